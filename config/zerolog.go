@@ -18,6 +18,7 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
@@ -28,11 +29,11 @@ import (
 	"github.com/tforceaio/tf-unifiler-go/filesystem"
 )
 
-// Entrypoint for creating a ZeroLog logger instance.
-func InitZerolog(configDir string, useFS bool) (zerolog.Logger, *os.File, error) {
+// Entrypoint for creating a ZeroLog logger instance, console logs are written to consoleOut.
+func InitZerolog(configDir string, useFS bool, consoleOut io.Writer) (zerolog.Logger, *os.File, error) {
 	consoleWriter := &zerolog.FilteredLevelWriter{
 		Writer: zerolog.LevelWriterAdapter{
-			Writer: zerolog.ConsoleWriter{Out: os.Stdout, NoColor: true, TimeFormat: time.DateTime},
+			Writer: zerolog.ConsoleWriter{Out: consoleOut, NoColor: true, TimeFormat: time.DateTime},
 		},
 		Level: zerolog.TraceLevel,
 	}
@@ -25,4 +25,6 @@ type FileMultiHash struct {
 	Sha512   stdx.Bytes
 	Size     uint32
 	FileName string
+	// Quick fingerprint computed from size and sampled content, see hasher.Fingerprint.
+	Fingerprint stdx.Bytes
 }
@@ -0,0 +1,43 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"hash"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
+)
+
+func init() {
+	// tags follow GNU coreutils b2sum, which omits the length of the default 512-bit digest.
+	Register(&Algorithm{Name: "blake2b-256", New: func() hash.Hash { return newBlake2b(blake2b.Size256) }, Size: blake2b.Size256, BsdTag: "BLAKE2b-256"})
+	Register(&Algorithm{Name: "blake2b-384", New: func() hash.Hash { return newBlake2b(blake2b.Size384) }, Size: blake2b.Size384, BsdTag: "BLAKE2b-384"})
+	Register(&Algorithm{Name: "blake2b-512", Aliases: []string{"b2", "blake2b"}, New: func() hash.Hash { return newBlake2b(blake2b.Size) }, Size: blake2b.Size, BsdTag: "BLAKE2b"})
+	Register(&Algorithm{Name: "blake2s-256", Aliases: []string{"blake2s"}, New: newBlake2s, Size: blake2s.Size})
+}
+
+// Unkeyed BLAKE2 constructors never return error.
+func newBlake2b(size int) hash.Hash {
+	h, _ := blake2b.New(size, nil)
+	return h
+}
+
+func newBlake2s() hash.Hash {
+	h, _ := blake2s.New256(nil)
+	return h
+}
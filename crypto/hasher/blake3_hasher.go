@@ -0,0 +1,49 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"hash"
+
+	"github.com/zeebo/blake3"
+)
+
+func init() {
+	Register(&Algorithm{
+		Name:     "blake3",
+		Aliases:  []string{"b3"},
+		New:      func() hash.Hash { return blake3.New() },
+		Size:     32,
+		NewSized: func(size int) hash.Hash { return &blake3Hash{blake3.New(), size} },
+	})
+}
+
+// blake3Hash adapts BLAKE3 extendable output to hash.Hash with custom output length.
+type blake3Hash struct {
+	*blake3.Hasher
+	size int
+}
+
+func (h *blake3Hash) Size() int {
+	return h.size
+}
+
+func (h *blake3Hash) Sum(b []byte) []byte {
+	digest := make([]byte, h.size)
+	h.Hasher.Digest().Read(digest)
+	return append(b, digest...)
+}
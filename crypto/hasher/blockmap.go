@@ -0,0 +1,196 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+	"os"
+)
+
+// Name of block map in HashResult. Its hash is the concatenation of hashes of all blocks.
+const BlockMapAlgorithm = "blockmap"
+
+// Algorithm used for hashing blocks of block maps.
+const BlockHashAlgorithm = "xxh3-128"
+
+// Struct BlockMap contains hashes of fixed-size blocks of a file, so corrupted regions
+// can be located by comparing it with block map of another copy.
+type BlockMap struct {
+	Algorithm string
+	BlockSize int64
+	Size      int64
+	Hashes    [][]byte
+}
+
+// Struct ByteRange is a region of a file, End is exclusive.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// Return string representation of the range, using inclusive end like HTTP ranges.
+func (r *ByteRange) String() string {
+	return fmt.Sprintf("%d-%d", r.Start, r.End-1)
+}
+
+// Return block map from the block map result of HashWithBlocks or HashFiles.
+func NewBlockMap(result *HashResult, blockSize int64) (*BlockMap, error) {
+	algo, ok := Lookup(BlockHashAlgorithm)
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm: '%s'", BlockHashAlgorithm)
+	}
+	if len(result.Hash)%algo.Size != 0 {
+		return nil, fmt.Errorf("invalid block map length %d", len(result.Hash))
+	}
+	m := &BlockMap{
+		Algorithm: algo.Name,
+		BlockSize: blockSize,
+		Size:      result.Size,
+		Hashes:    [][]byte{},
+	}
+	for i := 0; i < len(result.Hash); i += algo.Size {
+		m.Hashes = append(m.Hashes, result.Hash[i:i+algo.Size])
+	}
+	return m, nil
+}
+
+// Compute hashes of a file using multiple algorithms, together with its block map in a single pass.
+// The block map is the last result, its algorithm is BlockMapAlgorithm.
+func HashWithBlocks(fPath string, algorithms []string, blockSize int64, progress ProgressFunc) ([]*HashResult, error) {
+	if blockSize <= 0 {
+		return []*HashResult{}, fmt.Errorf("invalid block size %d", blockSize)
+	}
+	fHandle, err := os.Open(fPath)
+	if err != nil {
+		return []*HashResult{}, err
+	}
+	defer fHandle.Close()
+
+	size := int64(-1)
+	if fi, err := fHandle.Stat(); err == nil && fi.Mode().IsRegular() {
+		size = fi.Size()
+	}
+	results, hashers, err := newHashers(fPath, size, algorithms)
+	if err != nil {
+		return []*HashResult{}, err
+	}
+	algo, _ := Lookup(BlockHashAlgorithm)
+	results = append(results, &HashResult{
+		Path:      fPath,
+		Algorithm: BlockMapAlgorithm,
+	})
+	hashers = append(hashers, &blockHasher{algo: algo, h: algo.New(), blockSize: blockSize})
+
+	tracker := newProgressTracker(fPath, size, progress)
+	written, err := hashStream(fHandle, getBufferSize(size), hashers, tracker)
+	if err != nil {
+		return []*HashResult{}, err
+	}
+	tracker.finish(written)
+	for i, h := range hashers {
+		results[i].Size = written
+		results[i].Hash = h.Sum(nil)
+	}
+	return results, nil
+}
+
+// Compare content of a file with the block map, then return corrupted regions.
+// Adjacent corrupted blocks are merged, content beyond the end of the shorter one
+// is considered corrupted.
+func (m *BlockMap) Compare(fPath string) ([]*ByteRange, error) {
+	results, err := HashWithBlocks(fPath, []string{}, m.BlockSize, nil)
+	if err != nil {
+		return []*ByteRange{}, err
+	}
+	actual, err := NewBlockMap(results[0], m.BlockSize)
+	if err != nil {
+		return []*ByteRange{}, err
+	}
+
+	ranges := []*ByteRange{}
+	size := m.Size
+	if actual.Size > size {
+		size = actual.Size
+	}
+	for offset, i := int64(0), 0; offset < size; offset, i = offset+m.BlockSize, i+1 {
+		if i < len(m.Hashes) && i < len(actual.Hashes) && bytes.Equal(m.Hashes[i], actual.Hashes[i]) {
+			continue
+		}
+		end := offset + m.BlockSize
+		if end > size {
+			end = size
+		}
+		if n := len(ranges); n > 0 && ranges[n-1].End == offset {
+			ranges[n-1].End = end
+		} else {
+			ranges = append(ranges, &ByteRange{Start: offset, End: end})
+		}
+	}
+	return ranges, nil
+}
+
+// blockHasher hashes content in fixed-size blocks, its sum is the concatenation of hashes of all blocks.
+type blockHasher struct {
+	algo      *Algorithm
+	h         hash.Hash
+	blockSize int64
+	n         int64
+	sums      []byte
+}
+
+func (b *blockHasher) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		size := b.blockSize - b.n
+		if size > int64(len(p)) {
+			size = int64(len(p))
+		}
+		b.h.Write(p[:size])
+		b.n += size
+		p = p[size:]
+		if b.n == b.blockSize {
+			b.sums = b.h.Sum(b.sums)
+			b.h.Reset()
+			b.n = 0
+		}
+	}
+	return n, nil
+}
+
+func (b *blockHasher) Sum(in []byte) []byte {
+	in = append(in, b.sums...)
+	if b.n > 0 {
+		in = b.h.Sum(in)
+	}
+	return in
+}
+
+func (b *blockHasher) Reset() {
+	b.h.Reset()
+	b.n = 0
+	b.sums = nil
+}
+
+func (b *blockHasher) Size() int {
+	return b.algo.Size
+}
+
+func (b *blockHasher) BlockSize() int {
+	return b.h.BlockSize()
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBlockMapCompare(t *testing.T) {
+	const blockSize = 1000
+	content := make([]byte, blockSize*5+300)
+	rand.New(rand.NewSource(1)).Read(content)
+	fPath := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(fPath, content, 0664)
+
+	results, err := HashWithBlocks(fPath, []string{"sha256"}, blockSize, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[1].Algorithm != BlockMapAlgorithm {
+		t.Fatalf("block map is not the last result")
+	}
+	blockMap, err := NewBlockMap(results[1], blockSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blockMap.Hashes) != 6 || blockMap.Size != int64(len(content)) {
+		t.Fatalf("wrong block map. expected %d blocks actual %d", 6, len(blockMap.Hashes))
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(b []byte) []byte
+		expected []string
+	}{
+		{"intact", func(b []byte) []byte { return b }, []string{}},
+		{"single byte", func(b []byte) []byte { b[2500] ^= 0xff; return b }, []string{"2000-2999"}},
+		{"adjacent blocks", func(b []byte) []byte { b[999] ^= 0xff; b[1000] ^= 0xff; return b }, []string{"0-1999"}},
+		{"separate blocks", func(b []byte) []byte { b[0] ^= 0xff; b[5100] ^= 0xff; return b }, []string{"0-999", "5000-5299"}},
+		{"truncated", func(b []byte) []byte { return b[:3500] }, []string{"3000-5299"}},
+		{"appended", func(b []byte) []byte { return append(b, 1, 2, 3) }, []string{"5000-5302"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.WriteFile(fPath, tt.modify(bytes.Clone(content)), 0664)
+			ranges, err := blockMap.Compare(fPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := make([]string, len(ranges))
+			for i, r := range ranges {
+				actual[i] = r.String()
+			}
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("wrong ranges. expected %v actual %v", tt.expected, actual)
+			}
+			for i := range actual {
+				if actual[i] != tt.expected[i] {
+					t.Errorf("wrong ranges. expected %v actual %v", tt.expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"encoding"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// Struct Checkpoint is the state of hashing a file after some of its content has been consumed,
+// so an interrupted computation can be resumed instead of reading the file from the start.
+type Checkpoint struct {
+	Algorithms []string
+	Offset     int64
+	// Marshaled state of each algorithm, in the same order as Algorithms.
+	States [][]byte
+}
+
+// CheckpointStore persists checkpoints of files. Implementations are responsible for discarding
+// checkpoints of files which have been changed, and must be safe for concurrent use.
+type CheckpointStore interface {
+	// Return the checkpoint of a file, or false if there is none or the file has been changed.
+	LoadCheckpoint(fPath string) (*Checkpoint, bool)
+	// Store the latest checkpoint of a file.
+	SaveCheckpoint(fPath string, cp *Checkpoint) error
+	// Remove checkpoint of a file after it has been hashed completely.
+	DeleteCheckpoint(fPath string)
+}
+
+// Same as HashWithProgress, but state of all hashers is saved to store after every interval bytes,
+// and hashing resumes from the saved state if store has a checkpoint for the file.
+// Files smaller than interval and algorithms whose state cannot be marshaled are hashed normally.
+// Quick fingerprint is computed from sampled regions of large files, so the rest can be resumed.
+func HashResumable(fPath string, algorithms []string, progress ProgressFunc, store CheckpointStore, interval int64) ([]*HashResult, error) {
+	if store == nil || interval <= 0 {
+		return HashWithProgress(fPath, algorithms, progress)
+	}
+	fHandle, err := os.Open(fPath)
+	if err != nil {
+		return []*HashResult{}, err
+	}
+	defer fHandle.Close()
+	fi, err := fHandle.Stat()
+	if err != nil {
+		return []*HashResult{}, err
+	}
+	size := fi.Size()
+	if !fi.Mode().IsRegular() || size <= interval {
+		return hashReader(fHandle, fPath, size, algorithms, progress)
+	}
+	if len(algorithms) == 1 && algorithms[0] == FingerprintAlgorithm {
+		return fingerprintResults(fPath)
+	}
+	// fingerprint cannot be saved to checkpoints, it is computed from sampled regions instead.
+	for i, a := range algorithms {
+		if a != FingerprintAlgorithm {
+			continue
+		}
+		fResults, err := fingerprintResults(fPath)
+		if err != nil {
+			return []*HashResult{}, err
+		}
+		others := append(append([]string{}, algorithms[:i]...), algorithms[i+1:]...)
+		results, err := HashResumable(fPath, others, progress, store, interval)
+		if err != nil {
+			return []*HashResult{}, err
+		}
+		return append(results[:i], append(fResults, results[i:]...)...), nil
+	}
+
+	results, hashers, err := newHashers(fPath, size, algorithms)
+	if err != nil {
+		return []*HashResult{}, err
+	}
+	// some hashers implement the marshaler but refuse to marshal, e.g. keyed BLAKE2b.
+	if _, err := newCheckpoint(results, hashers, 0); err != nil {
+		return hashReader(fHandle, fPath, size, algorithms, progress)
+	}
+
+	tracker := newProgressTracker(fPath, size, progress)
+	offset := int64(0)
+	if cp, ok := store.LoadCheckpoint(fPath); ok && restoreCheckpoint(cp, results, hashers, size) {
+		if _, err := fHandle.Seek(cp.Offset, io.SeekStart); err != nil {
+			return []*HashResult{}, err
+		}
+		offset = cp.Offset
+		tracker.resume(offset)
+	} else {
+		// states may be partially restored.
+		for _, h := range hashers {
+			h.Reset()
+		}
+	}
+
+	bufSize := getBufferSize(size)
+	for {
+		n, err := hashStream(io.LimitReader(fHandle, interval), bufSize, hashers, tracker)
+		if err != nil {
+			return []*HashResult{}, err
+		}
+		offset += n
+		tracker.advance(n)
+		if n < interval {
+			break
+		}
+		cp, err := newCheckpoint(results, hashers, offset)
+		if err == nil {
+			err = store.SaveCheckpoint(fPath, cp)
+		}
+		if err != nil {
+			logger.Warn().Err(err).Str("path", fPath).Msg("Failed to save checkpoint.")
+		}
+	}
+	tracker.finish(0)
+	store.DeleteCheckpoint(fPath)
+
+	for i, h := range hashers {
+		results[i].Size = offset
+		results[i].Hash = h.Sum(nil)
+	}
+	return results, nil
+}
+
+// Return checkpoint containing state of all hashers, or error if any of them cannot be marshaled.
+func newCheckpoint(results []*HashResult, hashers []hash.Hash, offset int64) (*Checkpoint, error) {
+	cp := &Checkpoint{
+		Algorithms: make([]string, len(results)),
+		Offset:     offset,
+		States:     make([][]byte, len(hashers)),
+	}
+	for i, h := range hashers {
+		cp.Algorithms[i] = results[i].Algorithm
+		marshaler, ok := h.(encoding.BinaryMarshaler)
+		if !ok {
+			return nil, fmt.Errorf("state of hash algorithm '%s' cannot be marshaled", results[i].Algorithm)
+		}
+		state, err := marshaler.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		cp.States[i] = state
+	}
+	return cp, nil
+}
+
+// Restore state of hashers from a checkpoint, return false if the checkpoint does not
+// match the algorithms or cannot be unmarshaled.
+func restoreCheckpoint(cp *Checkpoint, results []*HashResult, hashers []hash.Hash, size int64) bool {
+	if cp.Offset <= 0 || cp.Offset > size || len(cp.Algorithms) != len(results) || len(cp.States) != len(hashers) {
+		return false
+	}
+	for i, h := range hashers {
+		if cp.Algorithms[i] != results[i].Algorithm {
+			return false
+		}
+		unmarshaler, ok := h.(encoding.BinaryUnmarshaler)
+		if !ok || unmarshaler.UnmarshalBinary(cp.States[i]) != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testingCheckpointStore struct {
+	saved   []*Checkpoint
+	loaded  *Checkpoint
+	deleted bool
+}
+
+func (s *testingCheckpointStore) LoadCheckpoint(fPath string) (*Checkpoint, bool) {
+	return s.loaded, s.loaded != nil
+}
+
+func (s *testingCheckpointStore) SaveCheckpoint(fPath string, cp *Checkpoint) error {
+	s.saved = append(s.saved, cp)
+	return nil
+}
+
+func (s *testingCheckpointStore) DeleteCheckpoint(fPath string) {
+	s.deleted = true
+}
+
+func TestHashResumable(t *testing.T) {
+	const interval = 100000
+	content := make([]byte, interval*3+500)
+	rand.New(rand.NewSource(1)).Read(content)
+	fPath := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(fPath, content, 0664)
+	sum := sha256.Sum256(content)
+	expected := hex.EncodeToString(sum[:])
+	algorithms := []string{"sha256", "md5"}
+
+	store := &testingCheckpointStore{}
+	results, err := HashResumable(fPath, algorithms, nil, store, interval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash := hex.EncodeToString(results[0].Hash); hash != expected {
+		t.Errorf("wrong hash. expected '%s' actual '%s'", expected, hash)
+	}
+	if len(store.saved) != 3 || store.saved[1].Offset != interval*2 || !store.deleted {
+		t.Fatalf("wrong checkpoints. expected %d saved and deleted actual %d and %t", 3, len(store.saved), store.deleted)
+	}
+
+	// content before checkpoint is changed, so the original hash proves it has not been read again.
+	changed := bytes.Clone(content)
+	changed[0] ^= 0xff
+	os.WriteFile(fPath, changed, 0664)
+	changedSum := sha256.Sum256(changed)
+
+	tests := []struct {
+		name       string
+		checkpoint *Checkpoint
+		expected   string
+	}{
+		{"resumed", store.saved[1], expected},
+		{"algorithms mismatch", &Checkpoint{Algorithms: []string{"md5", "sha256"}, Offset: interval, States: store.saved[0].States}, hex.EncodeToString(changedSum[:])},
+		{"offset beyond size", &Checkpoint{Algorithms: store.saved[0].Algorithms, Offset: int64(len(content) + 1), States: store.saved[0].States}, hex.EncodeToString(changedSum[:])},
+		{"invalid state", &Checkpoint{Algorithms: store.saved[0].Algorithms, Offset: interval, States: [][]byte{{1}, {2}}}, hex.EncodeToString(changedSum[:])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var last *Progress
+			results, err := HashResumable(fPath, algorithms, func(p *Progress) { last = p }, &testingCheckpointStore{loaded: tt.checkpoint}, interval)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hash := hex.EncodeToString(results[0].Hash); hash != tt.expected {
+				t.Errorf("wrong hash. expected '%s' actual '%s'", tt.expected, hash)
+			}
+			if results[0].Size != int64(len(content)) {
+				t.Errorf("wrong size. expected %d actual %d", len(content), results[0].Size)
+			}
+			if last == nil || last.Done != int64(len(content)) || last.Total != int64(len(content)) {
+				t.Errorf("wrong final progress. actual %+v", last)
+			}
+		})
+	}
+}
+
+func TestHashResumableWithFingerprint(t *testing.T) {
+	const interval = 100000
+	content := make([]byte, interval*3+500)
+	rand.New(rand.NewSource(1)).Read(content)
+	fPath := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(fPath, content, 0664)
+	sum := sha256.Sum256(content)
+	fingerprint, err := Fingerprint(fPath, DefaultSampleSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &testingCheckpointStore{}
+	results, err := HashResumable(fPath, []string{"sha256", FingerprintAlgorithm, "md5"}, nil, store, interval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[1].Algorithm != FingerprintAlgorithm || !bytes.Equal(results[1].Hash, fingerprint.Hash) {
+		t.Fatalf("wrong fingerprint result. actual %v", results)
+	}
+	if !bytes.Equal(results[0].Hash, sum[:]) || results[2].Algorithm != "md5" {
+		t.Errorf("wrong results order or hash. actual %v", results)
+	}
+	if len(store.saved) != 3 {
+		t.Errorf("wrong checkpoints. expected %d saved actual %d", 3, len(store.saved))
+	}
+}
+
+func TestHashResumableUnmarshalable(t *testing.T) {
+	const interval = 100000
+	content := make([]byte, interval*3+500)
+	rand.New(rand.NewSource(1)).Read(content)
+	fPath := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(fPath, content, 0664)
+	SetKey(Key("0123456789abcdef0123456789abcdef"))
+	defer SetKey(nil)
+	expected, err := Hash(fPath, []string{"keyed-blake2b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &testingCheckpointStore{}
+	results, err := HashResumable(fPath, []string{"sha256", "keyed-blake2b"}, nil, store, interval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(results[1].Hash, expected[0].Hash) {
+		t.Errorf("wrong hash. expected %x actual %x", expected[0].Hash, results[1].Hash)
+	}
+	if len(store.saved) != 0 {
+		t.Errorf("unexpected checkpoints. actual %d saved", len(store.saved))
+	}
+}
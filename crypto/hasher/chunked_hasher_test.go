@@ -0,0 +1,95 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+// Generate deterministic content which does not repeat at chunk boundaries.
+func patternContent(size int) []byte {
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i*7 + i/251)
+	}
+	return content
+}
+
+// Write content in pieces of odd sizes so buffering across blocks is exercised.
+func writePieces(h hash.Hash, content []byte) {
+	for i, piece := 0, 1; i < len(content); piece = piece*3 + 1 {
+		end := i + piece
+		if end > len(content) {
+			end = len(content)
+		}
+		h.Write(content[i:end])
+		i = end
+	}
+}
+
+func TestTigerTree(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		hash string
+	}{
+		{"empty", 0, "5d9ed00a030e638bdb753a6a24fb900e5a63b8e73e6c25b6"},
+		{"single byte", 1, "aabbcca084acecd0511d1f6232a17bfaefa441b2982e5548"},
+		{"single leaf", 1024, "71ce2f6100d50fd0fcccf3147e7fd645c5cdd880b27c9850"},
+		{"partial leaf", 1025, "9cc8a3deaf23f8625ef1f305cdaf5b62725eb293f9a79b14"},
+		{"odd leaves", 3072, "9bf892f80567126e2609069aec6e52823d5e7e14bb244852"},
+		{"promoted subtree", 5*1024 + 3, "5ffa7d0864c757293e76fecb668b91779655b929d9e1a1dd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTigerTree()
+			writePieces(h, patternContent(tt.size))
+			if hash := hex.EncodeToString(h.Sum(nil)); hash != tt.hash {
+				t.Errorf("wrong hash. expected '%s' actual '%s'", tt.hash, hash)
+			}
+			// Sum must not change state of the hasher.
+			if hash := hex.EncodeToString(h.Sum(nil)); hash != tt.hash {
+				t.Errorf("wrong hash after Sum. expected '%s' actual '%s'", tt.hash, hash)
+			}
+		})
+	}
+}
+
+func TestEd2k(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		hash string
+	}{
+		{"empty", 0, "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"single byte", 1, "47c61a0fa8738ba77308a8a600f88e4b"},
+		{"single chunk", ed2kChunkSize, "b0c0671d38d6790971cde40b6e942d28"},
+		{"partial chunk", ed2kChunkSize + 1, "5c57e563f151855eb0faa41be2366570"},
+		{"multiple chunks", ed2kChunkSize * 2, "fadfebb08aac1a6d53aff849bca4c2d9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newEd2k()
+			writePieces(h, patternContent(tt.size))
+			if hash := hex.EncodeToString(h.Sum(nil)); hash != tt.hash {
+				t.Errorf("wrong hash. expected '%s' actual '%s'", tt.hash, hash)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"hash"
+	"hash/crc32"
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+func init() {
+	Register(&Algorithm{Name: "crc32", Aliases: []string{"crc32b"}, New: func() hash.Hash { return crc32.NewIEEE() }, Size: crc32.Size})
+	Register(&Algorithm{Name: "crc32c", New: func() hash.Hash { return crc32.New(castagnoliTable) }, Size: crc32.Size})
+}
+
+func HashCrc32(fPath string) (*HashResult, error) {
+	return hashFile(fPath, crc32.NewIEEE(), "crc32")
+}
+
+func HashCrc32c(fPath string) (*HashResult, error) {
+	return hashFile(fPath, crc32.New(castagnoliTable), "crc32c")
+}
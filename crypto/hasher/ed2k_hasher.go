@@ -0,0 +1,89 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"hash"
+
+	"golang.org/x/crypto/md4"
+)
+
+// Size of chunks used by eDonkey2000 network, 9500 KiB.
+const ed2kChunkSize = 9728000
+
+func init() {
+	Register(&Algorithm{Name: "ed2k", Aliases: []string{"edonkey"}, New: newEd2k, Size: md4.Size})
+}
+
+// ed2kDigest implements eDonkey2000 hash. Content smaller than a chunk is hashed using plain MD4,
+// otherwise the result is MD4 of concatenated MD4 hashes of all chunks.
+// Like eMule and RHash, when content size is a multiple of chunk size, hash of an empty chunk
+// is appended before the final MD4.
+type ed2kDigest struct {
+	chunk  hash.Hash
+	nchunk int
+	hashes []byte
+}
+
+func newEd2k() hash.Hash {
+	d := &ed2kDigest{chunk: md4.New()}
+	d.Reset()
+	return d
+}
+
+func (d *ed2kDigest) Reset() {
+	d.chunk.Reset()
+	d.nchunk = 0
+	d.hashes = []byte{}
+}
+
+func (d *ed2kDigest) Size() int {
+	return md4.Size
+}
+
+func (d *ed2kDigest) BlockSize() int {
+	return md4.BlockSize
+}
+
+func (d *ed2kDigest) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		size := ed2kChunkSize - d.nchunk
+		if size > len(p) {
+			size = len(p)
+		}
+		d.chunk.Write(p[:size])
+		d.nchunk += size
+		p = p[size:]
+		if d.nchunk == ed2kChunkSize {
+			d.hashes = d.chunk.Sum(d.hashes)
+			d.chunk.Reset()
+			d.nchunk = 0
+		}
+	}
+	return n, nil
+}
+
+func (d *ed2kDigest) Sum(b []byte) []byte {
+	if len(d.hashes) == 0 {
+		return d.chunk.Sum(b)
+	}
+	root := md4.New()
+	root.Write(d.hashes)
+	root.Write(d.chunk.Sum(nil))
+	return root.Sum(b)
+}
@@ -0,0 +1,143 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"encoding/binary"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/zeebo/xxh3"
+)
+
+// Default number of bytes sampled from each of the start, middle and end of a file for fingerprint.
+const DefaultSampleSize = 1024 * 1024
+
+// Name of quick fingerprint in HashResult.
+const FingerprintAlgorithm = "fingerprint"
+
+// Compute quick fingerprint of a file using its size and XXH3-128 of content sampled at the start,
+// the middle and the end of the file, each region has sampleSize bytes. Files not larger than
+// 3 regions are hashed entirely. Files having different fingerprints are certainly different,
+// but files having the same fingerprint must be compared using full hashes.
+func Fingerprint(fPath string, sampleSize int64) (*HashResult, error) {
+	fHandle, err := os.Open(fPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fHandle.Close()
+	fi, err := fHandle.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := fi.Size()
+
+	h := xxh3.New()
+	sizeBuf := make([]byte, 8)
+	binary.BigEndian.PutUint64(sizeBuf, uint64(size))
+	h.Write(sizeBuf)
+	if size <= sampleSize*3 {
+		if _, err := io.Copy(h, fHandle); err != nil {
+			return nil, err
+		}
+	} else {
+		buf := make([]byte, sampleSize)
+		for _, offset := range []int64{0, (size - sampleSize) / 2, size - sampleSize} {
+			if _, err := fHandle.ReadAt(buf, offset); err != nil {
+				return nil, err
+			}
+			h.Write(buf)
+		}
+	}
+	digest := h.Sum128().Bytes()
+
+	result := &HashResult{
+		Path:      fPath,
+		Size:      size,
+		Algorithm: FingerprintAlgorithm,
+		Hash:      digest[:],
+	}
+	return result, nil
+}
+
+// Struct fingerprintHasher computes quick fingerprint of content streamed in order, so it can be
+// computed in the same pass as other algorithms. It produces the same result as Fingerprint
+// as long as content has the size it is created with.
+type fingerprintHasher struct {
+	h       *xxh3.Hasher
+	regions []ByteRange
+	offset  int64
+}
+
+// Return new hash.Hash computing quick fingerprint of content having size bytes.
+func newFingerprintHasher(size, sampleSize int64) hash.Hash {
+	regions := []ByteRange{{Start: 0, End: size}}
+	if size > sampleSize*3 {
+		regions = []ByteRange{}
+		for _, offset := range []int64{0, (size - sampleSize) / 2, size - sampleSize} {
+			regions = append(regions, ByteRange{Start: offset, End: offset + sampleSize})
+		}
+	}
+	f := &fingerprintHasher{
+		h:       xxh3.New(),
+		regions: regions,
+	}
+	f.Reset()
+	return f
+}
+
+func (f *fingerprintHasher) Write(p []byte) (int, error) {
+	start, end := f.offset, f.offset+int64(len(p))
+	for _, r := range f.regions {
+		if r.End <= start || r.Start >= end {
+			continue
+		}
+		from, to := r.Start, r.End
+		if from < start {
+			from = start
+		}
+		if to > end {
+			to = end
+		}
+		f.h.Write(p[from-start : to-start])
+	}
+	f.offset = end
+	return len(p), nil
+}
+
+func (f *fingerprintHasher) Sum(in []byte) []byte {
+	digest := f.h.Sum128().Bytes()
+	return append(in, digest[:]...)
+}
+
+func (f *fingerprintHasher) Reset() {
+	f.h.Reset()
+	f.offset = 0
+	size := f.regions[len(f.regions)-1].End
+	sizeBuf := make([]byte, 8)
+	binary.BigEndian.PutUint64(sizeBuf, uint64(size))
+	f.h.Write(sizeBuf)
+}
+
+func (f *fingerprintHasher) Size() int {
+	return 16
+}
+
+func (f *fingerprintHasher) BlockSize() int {
+	return f.h.BlockSize()
+}
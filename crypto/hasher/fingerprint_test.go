@@ -0,0 +1,111 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	base := bytes.Repeat([]byte("0123456789abcdef"), 64)
+	write := func(name string, content []byte) string {
+		fPath := filepath.Join(dir, name)
+		os.WriteFile(fPath, content, 0664)
+		return fPath
+	}
+	changed := func(offset int) []byte {
+		content := bytes.Clone(base)
+		content[offset] = 'x'
+		return content
+	}
+	original := write("original", base)
+
+	tests := []struct {
+		name  string
+		fPath string
+		same  bool
+	}{
+		{"identical", write("identical", base), true},
+		{"start changed", write("start", changed(0)), false},
+		{"middle changed", write("middle", changed(len(base)/2)), false},
+		{"end changed", write("end", changed(len(base)-1)), false},
+		{"unsampled region changed", write("unsampled", changed(200)), true},
+		{"size changed", write("size", base[:len(base)-1]), false},
+	}
+	expected, err := Fingerprint(original, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := Fingerprint(tt.fPath, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if same := bytes.Equal(expected.Hash, actual.Hash); same != tt.same {
+				t.Errorf("wrong fingerprint comparison. expected %t actual %t", tt.same, same)
+			}
+		})
+	}
+
+	t.Run("small file", func(t *testing.T) {
+		small, _ := Fingerprint(original, int64(len(base)))
+		smallChanged, _ := Fingerprint(tests[4].fPath, int64(len(base)))
+		if bytes.Equal(small.Hash, smallChanged.Hash) {
+			t.Errorf("small files should be hashed entirely")
+		}
+	})
+}
+
+func TestFingerprintInHashPass(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"empty", 0},
+		{"small", 1000},
+		{"sampled entirely", int(DefaultSampleSize * 3)},
+		{"sampled regions", int(DefaultSampleSize*3) + 12345},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := make([]byte, tt.size)
+			for i := range content {
+				content[i] = byte(i * 31 / 7)
+			}
+			fPath := filepath.Join(dir, tt.name)
+			os.WriteFile(fPath, content, 0664)
+			expected, err := Fingerprint(fPath, DefaultSampleSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := Hash(fPath, []string{"sha256", FingerprintAlgorithm})
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := FindResult(results, FingerprintAlgorithm)
+			if !bytes.Equal(expected.Hash, actual.Hash) {
+				t.Errorf("wrong fingerprint. expected %x actual %x", expected.Hash, actual.Hash)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// Smallest key accepted by LoadKey.
+	MinKeySize = 16
+	// Largest key accepted by LoadKey, which is the limit of keyed BLAKE2b.
+	MaxKeySize = 64
+)
+
+// Key is a secret used by keyed algorithms, so digests cannot be compared with
+// digests of the same content computed by anyone who doesn't have it.
+type Key []byte
+
+func init() {
+	Register(&Algorithm{Name: "hmac-sha256", NewKeyed: func(key []byte) hash.Hash { return hmac.New(sha256.New, key) }, Size: sha256.Size})
+	Register(&Algorithm{Name: "keyed-blake2b", Aliases: []string{"blake2b-mac"}, NewKeyed: newKeyedBlake2b, Size: blake2b.Size256})
+}
+
+// Keys longer than 64 bytes are rejected by LoadKey, which is the only error of blake2b.New256.
+func newKeyedBlake2b(key []byte) hash.Hash {
+	h, _ := blake2b.New256(key)
+	return h
+}
+
+// Read key from a file, or from an environment variable if keyFile is empty.
+// Surrounding whitespaces are ignored. Return nil if neither is set.
+func LoadKey(keyFile, envName string) (Key, error) {
+	var content []byte
+	switch {
+	case keyFile != "" && envName != "":
+		return nil, errors.New("key must be read from either a file or an environment variable")
+	case keyFile != "":
+		fContent, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		content = fContent
+	case envName != "":
+		value, ok := os.LookupEnv(envName)
+		if !ok {
+			return nil, fmt.Errorf("environment variable '%s' is not set", envName)
+		}
+		content = []byte(value)
+	default:
+		return nil, nil
+	}
+	key := Key(bytes.TrimSpace(content))
+	if len(key) < MinKeySize || len(key) > MaxKeySize {
+		return nil, fmt.Errorf("key must have from %d to %d bytes, got %d", MinKeySize, MaxKeySize, len(key))
+	}
+	return key, nil
+}
+
+// Use key for all keyed algorithms returned by Lookup from now on. Nil disables them.
+func SetKey(key Key) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.key = bytes.Clone(key)
+}
+
+// Return key used by keyed algorithms, or nil if there is none.
+func CurrentKey() Key {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.key
+}
+
+// Return a short identifier of the key which doesn't reveal it, so digests computed
+// using different keys can be told apart. Return empty string for nil key.
+func (k Key) Fingerprint() string {
+	if len(k) == 0 {
+		return ""
+	}
+	return hex.EncodeToString(k.Sum([]byte("tf-unifiler key fingerprint"))[:8])
+}
+
+// Return HMAC-SHA256 of data using the key.
+func (k Key) Sum(data []byte) []byte {
+	h := hmac.New(sha256.New, k)
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyedAlgorithms(t *testing.T) {
+	fPath := filepath.Join(t.TempDir(), "data.txt")
+	os.WriteFile(fPath, []byte("hello world"), 0664)
+	key := Key("0123456789abcdef0123456789abcdef")
+
+	SetKey(nil)
+	if _, err := Normalize([]string{"hmac-sha256"}); err == nil || err.Error() != "hash algorithm 'hmac-sha256' requires a key" {
+		t.Fatalf("wrong error without key: %v", err)
+	}
+	SetKey(key)
+	defer SetKey(nil)
+
+	tests := []struct {
+		algorithm string
+		expected  string
+	}{
+		{"hmac-sha256", "60a0b19b2bd8dd2feed55f44ce6fdc5da220d0b2a91c8683f46eeabce3909a66"},
+		{"keyed-blake2b", "b6f1f7988e7a987e463e3b891a79a6b47c23be32ad56728428f8cd270a8e3948"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			results, err := Hash(fPath, []string{tt.algorithm})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hash := hex.EncodeToString(results[0].Hash); hash != tt.expected {
+				t.Errorf("wrong hash. expected '%s' actual '%s'", tt.expected, hash)
+			}
+		})
+	}
+	if fingerprint := key.Fingerprint(); fingerprint != "7904a1cff6bd8833" {
+		t.Errorf("wrong fingerprint. expected '%s' actual '%s'", "7904a1cff6bd8833", fingerprint)
+	}
+	if fingerprint := Key(nil).Fingerprint(); fingerprint != "" {
+		t.Errorf("unexpected fingerprint of nil key '%s'", fingerprint)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	os.WriteFile(keyFile, []byte("0123456789abcdef\n"), 0600)
+	shortFile := filepath.Join(dir, "short")
+	os.WriteFile(shortFile, []byte("secret"), 0600)
+	t.Setenv("UNIFILER_TEST_KEY", "  fedcba9876543210  ")
+
+	tests := []struct {
+		name     string
+		keyFile  string
+		envName  string
+		expected string
+		ok       bool
+	}{
+		{"none", "", "", "", true},
+		{"file", keyFile, "", "0123456789abcdef", true},
+		{"env", "", "UNIFILER_TEST_KEY", "fedcba9876543210", true},
+		{"both", keyFile, "UNIFILER_TEST_KEY", "", false},
+		{"short", shortFile, "", "", false},
+		{"missing env", "", "UNIFILER_TEST_MISSING_KEY", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := LoadKey(tt.keyFile, tt.envName)
+			if (err == nil) != tt.ok {
+				t.Fatalf("wrong error. expected ok %t actual '%v'", tt.ok, err)
+			}
+			if string(key) != tt.expected {
+				t.Errorf("wrong key. expected '%s' actual '%s'", tt.expected, key)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Return ed2k link of a file, which is used by eDonkey2000 network clients such as eMule.
+// hash is the ed2k hash of the file.
+func Ed2kLink(name string, size int64, hash []byte) string {
+	// name is path-escaped so pipe, the field separator of ed2k links, cannot appear in it.
+	return fmt.Sprintf("ed2k://|file|%s|%d|%s|/", url.PathEscape(name), size, strings.ToUpper(hex.EncodeToString(hash)))
+}
+
+// Return magnet link of a file identified by its Tiger Tree Hash, which is used by
+// Direct Connect network clients such as DC++.
+func MagnetLink(name string, size int64, tth []byte) string {
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(tth)
+	return fmt.Sprintf("magnet:?xt=urn:tree:tiger:%s&xl=%d&dn=%s", encoded, size, url.QueryEscape(name))
+}
@@ -0,0 +1,46 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestEd2kLink(t *testing.T) {
+	hash, _ := hex.DecodeString("a448017aaf21d8525fc10ae87aa6729d")
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"abc.txt", "ed2k://|file|abc.txt|3|A448017AAF21D8525FC10AE87AA6729D|/"},
+		{"a b|c.txt", "ed2k://|file|a%20b%7Cc.txt|3|A448017AAF21D8525FC10AE87AA6729D|/"},
+	}
+	for _, tt := range tests {
+		if link := Ed2kLink(tt.name, 3, hash); link != tt.expected {
+			t.Errorf("wrong link. expected '%s' actual '%s'", tt.expected, link)
+		}
+	}
+}
+
+func TestMagnetLink(t *testing.T) {
+	tth, _ := hex.DecodeString("5d9ed00a030e638bdb753a6a24fb900e5a63b8e73e6c25b6")
+	expected := "magnet:?xt=urn:tree:tiger:LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ&xl=0&dn=empty+file.txt"
+	if link := MagnetLink("empty file.txt", 0, tth); link != expected {
+		t.Errorf("wrong link. expected '%s' actual '%s'", expected, link)
+	}
+}
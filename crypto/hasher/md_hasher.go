@@ -22,6 +22,11 @@ import (
 	"golang.org/x/crypto/md4"
 )
 
+func init() {
+	Register(&Algorithm{Name: "md4", New: md4.New, Size: md4.Size})
+	Register(&Algorithm{Name: "md5", New: md5.New, Size: md5.Size})
+}
+
 func HashMd4(fPath string) (*HashResult, error) {
 	return hashFile(fPath, md4.New(), "md4")
 }
@@ -0,0 +1,75 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// MerkleLeaf is a file of a directory tree, identified by its relative path and hash of its content.
+type MerkleLeaf struct {
+	Path string
+	Hash []byte
+}
+
+// Compute Merkle root of leaves using algorithm. Leaves are sorted by path, then each of them is
+// hashed along with its length-prefixed path, so renaming or moving a file changes the root.
+// Nodes are paired and hashed level by level until only 1 node remains, a node without pair is
+// promoted to the next level. Leaves and inner nodes are prefixed with 0x00 and 0x01 respectively.
+// Root of empty leaves is hash of empty input.
+// Paths should use forward slashes and be relative to the same directory to keep root deterministic.
+func MerkleRoot(algorithm string, leaves []MerkleLeaf) ([]byte, error) {
+	algo, ok := Lookup(algorithm)
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm: '%s'", algorithm)
+	}
+	h := algo.New()
+	if len(leaves) == 0 {
+		return h.Sum(nil), nil
+	}
+
+	sorted := make([]MerkleLeaf, len(leaves))
+	copy(sorted, leaves)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+	nodes := make([][]byte, len(sorted))
+	for i, leaf := range sorted {
+		h.Reset()
+		h.Write([]byte{0x00})
+		h.Write(binary.AppendUvarint(nil, uint64(len(leaf.Path))))
+		h.Write([]byte(leaf.Path))
+		h.Write(leaf.Hash)
+		nodes[i] = h.Sum(nil)
+	}
+	for len(nodes) > 1 {
+		next := make([][]byte, 0, (len(nodes)+1)/2)
+		for i := 0; i < len(nodes); i += 2 {
+			if i+1 == len(nodes) {
+				next = append(next, nodes[i])
+				continue
+			}
+			h.Reset()
+			h.Write([]byte{0x01})
+			h.Write(nodes[i])
+			h.Write(nodes[i+1])
+			next = append(next, h.Sum(nil))
+		}
+		nodes = next
+	}
+	return nodes[0], nil
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestMerkleRoot(t *testing.T) {
+	hashA := sha256.Sum256([]byte("a"))
+	hashB := sha256.Sum256([]byte("b"))
+	hashC := sha256.Sum256([]byte("c"))
+	leaves := []MerkleLeaf{
+		{"a.txt", hashA[:]},
+		{"sub/b.txt", hashB[:]},
+		{"sub/c.txt", hashC[:]},
+	}
+	expected, err := MerkleRoot("sha256", leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		leaves []MerkleLeaf
+		same   bool
+	}{
+		{"identical", []MerkleLeaf{{"a.txt", hashA[:]}, {"sub/b.txt", hashB[:]}, {"sub/c.txt", hashC[:]}}, true},
+		{"reordered", []MerkleLeaf{{"sub/c.txt", hashC[:]}, {"a.txt", hashA[:]}, {"sub/b.txt", hashB[:]}}, true},
+		{"renamed", []MerkleLeaf{{"a.txt", hashA[:]}, {"sub/b.txt", hashB[:]}, {"sub/d.txt", hashC[:]}}, false},
+		{"swapped contents", []MerkleLeaf{{"a.txt", hashA[:]}, {"sub/b.txt", hashC[:]}, {"sub/c.txt", hashB[:]}}, false},
+		{"missing", []MerkleLeaf{{"a.txt", hashA[:]}, {"sub/b.txt", hashB[:]}}, false},
+		{"extra", []MerkleLeaf{{"a.txt", hashA[:]}, {"sub/b.txt", hashB[:]}, {"sub/c.txt", hashC[:]}, {"z.txt", hashA[:]}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := MerkleRoot("sha256", tt.leaves)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(expected, actual) != tt.same {
+				t.Errorf("wrong merkle root comparison. expected same %t, root %x actual %x", tt.same, expected, actual)
+			}
+		})
+	}
+
+	empty, _ := MerkleRoot("sha256", nil)
+	emptyHash := sha256.Sum256(nil)
+	if !bytes.Equal(empty, emptyHash[:]) {
+		t.Errorf("wrong merkle root of empty leaves. expected %x actual %x", emptyHash, empty)
+	}
+	if _, err := MerkleRoot("unknown", leaves); err == nil {
+		t.Error("expected error for unsupported algorithm")
+	}
+}
@@ -17,118 +17,238 @@
 package hasher
 
 import (
-	"crypto/md5"
-	"crypto/sha1"
-	"crypto/sha256"
-	"crypto/sha512"
 	"errors"
 	"fmt"
 	"hash"
 	"io"
 	"os"
+	"sync"
+	"sync/atomic"
+)
 
-	"golang.org/x/crypto/md4"
-	"golang.org/x/crypto/ripemd160"
+const (
+	// Smallest buffer used for reading files, also used when file size is unknown.
+	minBufferSize = 64 * 1024
+	// Largest buffer used for reading files.
+	maxBufferSize = 4 * 1024 * 1024
+	// Number of buffers rotating between the reader and the hashers.
+	bufferCount = 4
 )
 
 type HashResult struct {
 	Path      string
-	Size      int
+	Size      int64
 	Algorithm string
 	Hash      []byte
 }
 
+// Compute hashes of a file using multiple algorithms in a single pass.
+// The file is read on its own goroutine into rotating buffers while each algorithm
+// consumes them on a separate goroutine, so reading and hashing overlap.
 func Hash(fPath string, algorithms []string) ([]*HashResult, error) {
+	return HashWithProgress(fPath, algorithms, nil)
+}
+
+// Same as Hash, progress will be called periodically while the file is being read if it is not nil.
+func HashWithProgress(fPath string, algorithms []string, progress ProgressFunc) ([]*HashResult, error) {
+	if len(algorithms) == 1 && algorithms[0] == FingerprintAlgorithm {
+		return fingerprintResults(fPath)
+	}
 	fHandle, err := os.Open(fPath)
 	if err != nil {
 		return []*HashResult{}, err
 	}
 	defer fHandle.Close()
 
+	size := int64(-1)
+	if fi, err := fHandle.Stat(); err == nil && fi.Mode().IsRegular() {
+		size = fi.Size()
+	}
+	return hashReader(fHandle, fPath, size, algorithms, progress)
+}
+
+// Return quick fingerprint of a file as results, it only reads sampled regions of the file.
+func fingerprintResults(fPath string) ([]*HashResult, error) {
+	result, err := Fingerprint(fPath, DefaultSampleSize)
+	if err != nil {
+		return []*HashResult{}, err
+	}
+	return []*HashResult{result}, nil
+}
+
+// Compute hashes of all content of a reader (stdin, pipes, archive members, etc.) using multiple
+// algorithms in a single pass. total is the expected size of content, or -1 if it is unknown,
+// and only used for buffer sizing and progress reporting. progress is optional.
+func HashReader(r io.Reader, algorithms []string, total int64, progress ProgressFunc) ([]*HashResult, error) {
+	return hashReader(r, "", total, algorithms, progress)
+}
+
+func hashReader(r io.Reader, fPath string, total int64, algorithms []string, progress ProgressFunc) ([]*HashResult, error) {
+	results, hashers, err := newHashers(fPath, total, algorithms)
+	if err != nil {
+		return []*HashResult{}, err
+	}
+
+	tracker := newProgressTracker(fPath, total, progress)
+	written, err := hashStream(r, getBufferSize(total), hashers, tracker)
+	if err != nil {
+		return []*HashResult{}, err
+	}
+	tracker.finish(written)
+	for i, h := range hashers {
+		results[i].Size = written
+		results[i].Hash = h.Sum(nil)
+	}
+	return results, nil
+}
+
+// Return empty results and hashers for algorithms. FingerprintAlgorithm is also accepted
+// if size of content is known, so quick fingerprint is computed in the same pass.
+func newHashers(fPath string, size int64, algorithms []string) ([]*HashResult, []hash.Hash, error) {
 	results := make([]*HashResult, len(algorithms))
 	hashers := make([]hash.Hash, len(algorithms))
 	for i, a := range algorithms {
+		if a == FingerprintAlgorithm {
+			if size < 0 {
+				return nil, nil, errors.New("fingerprint requires size of content")
+			}
+			results[i] = &HashResult{
+				Path:      fPath,
+				Algorithm: FingerprintAlgorithm,
+			}
+			hashers[i] = newFingerprintHasher(size, DefaultSampleSize)
+			continue
+		}
+		algo, ok := Lookup(a)
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported hash algorithm: '%s'", a)
+		}
 		results[i] = &HashResult{
 			Path:      fPath,
-			Algorithm: a,
-		}
-		switch a {
-		case "md4":
-			hashers[i] = md4.New()
-		case "md5":
-			hashers[i] = md5.New()
-		case "ripemd160":
-			hashers[i] = ripemd160.New()
-		case "sha1":
-			hashers[i] = sha1.New()
-		case "sha224":
-			hashers[i] = sha256.New224()
-		case "sha256":
-			hashers[i] = sha256.New()
-		case "sha384":
-			hashers[i] = sha512.New384()
-		case "sha512":
-			hashers[i] = sha512.New()
-		default:
-			return []*HashResult{}, fmt.Errorf("unsupported hash algorithm: '%s'", a)
+			Algorithm: algo.Name,
 		}
+		hashers[i] = algo.New()
 	}
+	return results, hashers, nil
+}
 
-	bufSize := getBufferSize(fHandle)
+// Return buffer size suitable for reading a file of specified size. Small files are read
+// using a single buffer, larger ones use bigger buffers to reduce number of syscalls.
+func getBufferSize(size int64) int {
+	switch {
+	case size <= 0:
+		return minBufferSize
+	case size < minBufferSize:
+		// one extra byte so EOF is detected without another full-sized read.
+		return int(size) + 1
+	case size/bufferCount > maxBufferSize:
+		return maxBufferSize
+	case size/bufferCount < minBufferSize:
+		return minBufferSize
+	default:
+		return int(size / bufferCount)
+	}
+}
+
+// Struct hashChunk is a filled buffer shared by all hashers. The last hasher
+// finished with it returns the buffer for reuse.
+type hashChunk struct {
+	buf  []byte
+	n    int
+	refs int32
+}
+
+// Feed content of a reader into all hashers, then return number of bytes read.
+// Content which fits in a single buffer is hashed on the calling goroutine since
+// there is nothing to overlap. All hashers have consumed the content when it returns.
+func hashStream(r io.Reader, bufSize int, hashers []hash.Hash, tracker *progressTracker) (int64, error) {
+	if len(hashers) == 0 {
+		written, err := io.Copy(io.Discard, r)
+		tracker.update(written)
+		return written, err
+	}
 	buf := make([]byte, bufSize)
-	written := int64(0)
-	for {
-		nread, eread := fHandle.Read(buf)
-		if nread > 0 {
-			nwrite := 0
-			var ewrite error = nil
-			for _, hasher := range hashers {
-				nwrite, ewrite = hasher.Write(buf[0:nread])
+	nread, eread := io.ReadFull(r, buf)
+	if eread == io.EOF || eread == io.ErrUnexpectedEOF {
+		for _, h := range hashers {
+			if err := writeHash(h, buf[:nread]); err != nil {
+				return 0, err
 			}
-			if nwrite < 0 || nread < nwrite {
-				nwrite = 0
-				if ewrite == nil {
-					ewrite = errors.New("cannot write to hasher")
+		}
+		tracker.update(int64(nread))
+		return int64(nread), nil
+	}
+	if eread != nil {
+		return 0, eread
+	}
+
+	free := make(chan []byte, bufferCount)
+	for i := 1; i < bufferCount; i++ {
+		free <- make([]byte, bufSize)
+	}
+	queues := make([]chan *hashChunk, len(hashers))
+	errs := make([]error, len(hashers))
+	wg := &sync.WaitGroup{}
+	for i, h := range hashers {
+		queues[i] = make(chan *hashChunk, bufferCount)
+		wg.Add(1)
+		go func(i int, h hash.Hash) {
+			defer wg.Done()
+			for chunk := range queues[i] {
+				if errs[i] == nil {
+					errs[i] = writeHash(h, chunk.buf[:chunk.n])
+				}
+				if atomic.AddInt32(&chunk.refs, -1) == 0 {
+					free <- chunk.buf
 				}
 			}
-			written += int64(nwrite)
-			if ewrite != nil {
-				err = ewrite
-				break
-			}
-			if nread != nwrite {
-				err = fmt.Errorf("read and write data mismatch %d %d", nread, nwrite)
-				break
+		}(i, h)
+	}
+
+	written := int64(0)
+	var err error
+	for {
+		if nread > 0 {
+			chunk := &hashChunk{buf: buf, n: nread, refs: int32(len(hashers))}
+			for _, q := range queues {
+				q <- chunk
 			}
+			written += int64(nread)
+			tracker.update(written)
 		}
 		if eread != nil {
-			if eread != io.EOF {
+			if eread != io.EOF && eread != io.ErrUnexpectedEOF {
 				err = eread
 			}
 			break
 		}
+		buf = <-free
+		nread, eread = io.ReadFull(r, buf)
+	}
+	for _, q := range queues {
+		close(q)
 	}
+	wg.Wait()
 	if err != nil {
-		return []*HashResult{}, err
+		return 0, err
 	}
-
-	for i, h := range hashers {
-		results[i].Size = int(written)
-		results[i].Hash = h.Sum(nil)
+	for _, e := range errs {
+		if e != nil {
+			return 0, e
+		}
 	}
-	return results, nil
+	return written, nil
 }
 
-func getBufferSize(src io.Reader) int {
-	size := 32 * 1024
-	if l, ok := src.(*io.LimitedReader); ok && int64(size) > l.N {
-		if l.N < 1 {
-			size = 1
-		} else {
-			size = int(l.N)
-		}
+func writeHash(h hash.Hash, buf []byte) error {
+	nwrite, ewrite := h.Write(buf)
+	if ewrite != nil {
+		return ewrite
+	}
+	if nwrite != len(buf) {
+		return errors.New("cannot write to hasher")
 	}
-	return size
+	return nil
 }
 
 func hashFile(fPath string, hasher hash.Hash, algo string) (*HashResult, error) {
@@ -138,43 +258,18 @@ func hashFile(fPath string, hasher hash.Hash, algo string) (*HashResult, error)
 	}
 	defer fHandle.Close()
 
-	bufSize := getBufferSize(fHandle)
-	buf := make([]byte, bufSize)
-	written := int64(0)
-	for {
-		nread, eread := fHandle.Read(buf)
-		if nread > 0 {
-			nwrite, ewrite := hasher.Write(buf[0:nread])
-			if nwrite < 0 || nread < nwrite {
-				nwrite = 0
-				if ewrite == nil {
-					ewrite = errors.New("cannot write to hasher")
-				}
-			}
-			written += int64(nwrite)
-			if ewrite != nil {
-				err = ewrite
-				break
-			}
-			if nread != nwrite {
-				err = fmt.Errorf("read and write data mismatch %d %d", nread, nwrite)
-				break
-			}
-		}
-		if eread != nil {
-			if eread != io.EOF {
-				err = eread
-			}
-			break
-		}
+	size := int64(-1)
+	if fi, err := fHandle.Stat(); err == nil && fi.Mode().IsRegular() {
+		size = fi.Size()
 	}
+	written, err := hashStream(fHandle, getBufferSize(size), []hash.Hash{hasher}, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	result := &HashResult{
 		Path:      fPath,
-		Size:      int(written),
+		Size:      written,
 		Algorithm: algo,
 		Hash:      hasher.Sum(nil),
 	}
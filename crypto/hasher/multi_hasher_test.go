@@ -0,0 +1,158 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/crc32"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	fPath := filepath.Join(t.TempDir(), "abc.txt")
+	os.WriteFile(fPath, []byte("abc"), 0664)
+
+	tests := []struct {
+		algorithm string
+		hash      string
+	}{
+		{"crc32", "352441c2"},
+		{"md5", "900150983cd24fb0d6963f7d28e17f72"},
+		{"sha1", "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{"sha256", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"sha3-256", "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+		{"shake128", "5881092dd818bf5cf8a3ddb793fbcba74097d5c526a6d35f97b83351940f2cc8"},
+		{"shake256-256", "483366601360a8771c6863080cc4114d8db44530f8f1e1ee4f94ea37e78b5739"},
+		{"blake2b-256", "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319"},
+		{"blake2s-256", "508c5e8c327c14e2e1a72ba34eeb452f37458b209ed63a294d999b4c86675982"},
+		{"blake3", "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"},
+		{"xxh3-64", "78af5f94892f3950"},
+		{"xxh3-128", "06b05ab6733a618578af5f94892f3950"},
+		{"xxh64", "44bc2cf5ad770999"},
+		{"tiger", "2aab1484e8c158f2bfb8c5ff41b57a525129131c957b5f93"},
+		{"tth", "0487ca26443f59cfbc780f3ca0ce509c8c352c27c5dcca20"},
+		{"ed2k", "a448017aaf21d8525fc10ae87aa6729d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			results, err := Hash(fPath, []string{tt.algorithm})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if results[0].Algorithm != tt.algorithm {
+				t.Errorf("wrong algorithm. expected '%s' actual '%s'", tt.algorithm, results[0].Algorithm)
+			}
+			if hash := hex.EncodeToString(results[0].Hash); hash != tt.hash {
+				t.Errorf("wrong hash. expected '%s' actual '%s'", tt.hash, hash)
+			}
+			if results[0].Size != 3 {
+				t.Errorf("wrong size. expected %d actual %d", 3, results[0].Size)
+			}
+		})
+	}
+}
+
+func TestHashBufferSizes(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"empty", 0},
+		{"single byte", 1},
+		{"below min buffer", minBufferSize - 1},
+		{"min buffer", minBufferSize},
+		{"all buffers", minBufferSize * bufferCount},
+		{"above max buffer", maxBufferSize*bufferCount + 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := make([]byte, tt.size)
+			rand.New(rand.NewSource(int64(tt.size))).Read(content)
+			fPath := filepath.Join(t.TempDir(), "data.bin")
+			os.WriteFile(fPath, content, 0664)
+
+			results, err := Hash(fPath, []string{"md5", "sha256", "crc32"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			md5Sum := md5.Sum(content)
+			sha256Sum := sha256.Sum256(content)
+			crc32Sum := crc32.ChecksumIEEE(content)
+			expected := []string{
+				hex.EncodeToString(md5Sum[:]),
+				hex.EncodeToString(sha256Sum[:]),
+				hex.EncodeToString([]byte{byte(crc32Sum >> 24), byte(crc32Sum >> 16), byte(crc32Sum >> 8), byte(crc32Sum)}),
+			}
+			for i, r := range results {
+				if hash := hex.EncodeToString(r.Hash); hash != expected[i] {
+					t.Errorf("wrong %s hash. expected '%s' actual '%s'", r.Algorithm, expected[i], hash)
+				}
+				if r.Size != int64(tt.size) {
+					t.Errorf("wrong size. expected %d actual %d", tt.size, r.Size)
+				}
+			}
+		})
+	}
+}
+
+func TestHashReader(t *testing.T) {
+	content := make([]byte, minBufferSize*bufferCount*2+13)
+	rand.New(rand.NewSource(1)).Read(content)
+	sha256Sum := sha256.Sum256(content)
+
+	tests := []struct {
+		name  string
+		total int64
+	}{
+		{"known size", int64(len(content))},
+		{"unknown size", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last := &Progress{}
+			calls := 0
+			results, err := HashReader(bytes.NewReader(content), []string{"sha256"}, tt.total, func(p *Progress) {
+				if p.Done < last.Done {
+					t.Errorf("progress went backward. previous %d actual %d", last.Done, p.Done)
+				}
+				last = p
+				calls++
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hash := hex.EncodeToString(results[0].Hash); hash != hex.EncodeToString(sha256Sum[:]) {
+				t.Errorf("wrong hash. expected '%x' actual '%s'", sha256Sum, hash)
+			}
+			if results[0].Size != int64(len(content)) {
+				t.Errorf("wrong size. expected %d actual %d", len(content), results[0].Size)
+			}
+			if calls < 2 {
+				t.Errorf("wrong progress calls. expected at least %d actual %d", 2, calls)
+			}
+			if last.Done != int64(len(content)) || last.Total != int64(len(content)) {
+				t.Errorf("wrong final progress. expected %d/%d actual %d/%d", len(content), len(content), last.Done, last.Total)
+			}
+		})
+	}
+}
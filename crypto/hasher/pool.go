@@ -0,0 +1,147 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"runtime"
+	"sync"
+)
+
+// HashCallback is called once for each file hashed by HashFiles, in the same order as the input paths.
+// Returning a non-nil error stops the remaining work and HashFiles returns that error.
+type HashCallback func(index int, results []*HashResult, err error) error
+
+// Cache provides hashes computed previously, so unchanged files are not read again.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Return cached results of a file for all algorithms, or false if any of them is missing or outdated.
+	Get(fPath string, algorithms []string) ([]*HashResult, bool)
+	// Store results of a file that has just been hashed.
+	Put(fPath string, results []*HashResult)
+}
+
+// Struct PoolOptions contains optional settings of HashFiles.
+type PoolOptions struct {
+	// Number of files hashed concurrently. If it is less than 1, number of CPUs is used.
+	Jobs int
+	// Receive progress of all files being hashed, it may be called concurrently.
+	Progress ProgressFunc
+	// Cache to look up before hashing and to store new results to.
+	Cache Cache
+	// Store of checkpoints for resuming interrupted hashing of large files, see HashResumable.
+	Checkpoints CheckpointStore
+	// Number of bytes hashed between checkpoints.
+	CheckpointInterval int64
+	// If it is positive, block map using blocks of this size is appended to results of each file,
+	// see HashWithBlocks. Cached results are not used and the block map is not cached,
+	// checkpoints are not used either.
+	BlockSize int64
+}
+
+// Struct hashJob holds the outcome of hashing a single file in the pool.
+type hashJob struct {
+	index   int
+	results []*HashResult
+	err     error
+	cached  bool
+}
+
+// Compute hashes for multiple files concurrently. Results are delivered to fn in the order
+// of fPaths regardless of which worker finishes first, so output produced from them is reproducible.
+func HashFiles(fPaths []string, algorithms []string, opts *PoolOptions, fn HashCallback) error {
+	if len(fPaths) == 0 {
+		return nil
+	}
+	if opts == nil {
+		opts = &PoolOptions{}
+	}
+	jobs := opts.Jobs
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+	if jobs > len(fPaths) {
+		jobs = len(fPaths)
+	}
+
+	indexes := make(chan int)
+	done := make(chan *hashJob, jobs)
+	stop := make(chan struct{})
+	go func() {
+		defer close(indexes)
+		for i := range fPaths {
+			select {
+			case indexes <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	wg := &sync.WaitGroup{}
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				job := &hashJob{index: i}
+				if opts.Cache != nil {
+					job.results, job.cached = opts.Cache.Get(fPaths[i], algorithms)
+					// block map is not cached, so the file must be read anyway.
+					job.cached = job.cached && opts.BlockSize <= 0
+				}
+				if opts.BlockSize > 0 {
+					job.results, job.err = HashWithBlocks(fPaths[i], algorithms, opts.BlockSize, opts.Progress)
+				} else if !job.cached {
+					job.results, job.err = HashResumable(fPaths[i], algorithms, opts.Progress, opts.Checkpoints, opts.CheckpointInterval)
+				}
+				select {
+				case done <- job:
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// buffer out-of-order results until all preceding ones are delivered.
+	pending := map[int]*hashJob{}
+	next := 0
+	for job := range done {
+		pending[job.index] = job
+		for {
+			job, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if opts.Cache != nil && !job.cached && job.err == nil {
+				opts.Cache.Put(fPaths[job.index], job.results[:len(algorithms)])
+			}
+			if err := fn(job.index, job.results, job.err); err != nil {
+				close(stop)
+				for range done {
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestHashFiles(t *testing.T) {
+	dir := t.TempDir()
+	fPaths := []string{}
+	for i := 0; i < 16; i++ {
+		fPath := filepath.Join(dir, strconv.Itoa(i)+".txt")
+		os.WriteFile(fPath, []byte("abc"), 0664)
+		fPaths = append(fPaths, fPath)
+	}
+
+	tests := []struct {
+		jobs int
+	}{
+		{0},
+		{1},
+		{4},
+		{32},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.jobs), func(t *testing.T) {
+			next := 0
+			err := HashFiles(fPaths, []string{"md5"}, &PoolOptions{Jobs: tt.jobs}, func(i int, results []*HashResult, err error) error {
+				if err != nil {
+					return err
+				}
+				if i != next {
+					t.Errorf("wrong order. expected %d actual %d", next, i)
+				}
+				if results[0].Path != fPaths[i] {
+					t.Errorf("wrong path. expected '%s' actual '%s'", fPaths[i], results[0].Path)
+				}
+				if hash := hex.EncodeToString(results[0].Hash); hash != "900150983cd24fb0d6963f7d28e17f72" {
+					t.Errorf("wrong hash. expected '%s' actual '%s'", "900150983cd24fb0d6963f7d28e17f72", hash)
+				}
+				next++
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next != len(fPaths) {
+				t.Errorf("wrong count. expected %d actual %d", len(fPaths), next)
+			}
+		})
+	}
+}
+
+func TestHashFilesError(t *testing.T) {
+	dir := t.TempDir()
+	fPaths := []string{}
+	for i := 0; i < 8; i++ {
+		fPath := filepath.Join(dir, strconv.Itoa(i)+".txt")
+		if i != 3 {
+			os.WriteFile(fPath, []byte("abc"), 0664)
+		}
+		fPaths = append(fPaths, fPath)
+	}
+
+	count := 0
+	err := HashFiles(fPaths, []string{"md5"}, &PoolOptions{Jobs: 4}, func(i int, results []*HashResult, err error) error {
+		count++
+		return err
+	})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("wrong error. expected '%v' actual '%v'", os.ErrNotExist, err)
+	}
+	if count != 4 {
+		t.Errorf("wrong count. expected %d actual %d", 4, count)
+	}
+}
+
+type testingCache struct {
+	mu      sync.Mutex
+	entries map[string][]*HashResult
+	hits    int
+}
+
+func (c *testingCache) Get(fPath string, algorithms []string) ([]*HashResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	results, ok := c.entries[fPath]
+	if ok {
+		c.hits++
+	}
+	return results, ok
+}
+
+func (c *testingCache) Put(fPath string, results []*HashResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[fPath] = results
+}
+
+func TestHashFilesCache(t *testing.T) {
+	dir := t.TempDir()
+	fPaths := []string{}
+	for i := 0; i < 8; i++ {
+		fPath := filepath.Join(dir, strconv.Itoa(i)+".txt")
+		os.WriteFile(fPath, []byte("abc"), 0664)
+		fPaths = append(fPaths, fPath)
+	}
+	cache := &testingCache{entries: map[string][]*HashResult{}}
+	cache.entries[fPaths[2]] = []*HashResult{{Path: fPaths[2], Size: 3, Algorithm: "md5", Hash: []byte{1}}}
+
+	hashes := []string{}
+	err := HashFiles(fPaths, []string{"md5"}, &PoolOptions{Jobs: 2, Cache: cache}, func(i int, results []*HashResult, err error) error {
+		hashes = append(hashes, hex.EncodeToString(results[0].Hash))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashes[2] != "01" || hashes[3] != "900150983cd24fb0d6963f7d28e17f72" {
+		t.Errorf("wrong hashes. cached entry should be used. actual %v", hashes)
+	}
+	if cache.hits != 1 || len(cache.entries) != len(fPaths) {
+		t.Errorf("wrong cache usage. expected %d hit(s) and %d entries actual %d and %d", 1, len(fPaths), cache.hits, len(cache.entries))
+	}
+}
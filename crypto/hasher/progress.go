@@ -0,0 +1,103 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"time"
+)
+
+// Struct Progress reports status of a running hash computation.
+type Progress struct {
+	// Path of the file being hashed, empty when hashing a reader.
+	Path string
+	// Number of bytes hashed so far.
+	Done int64
+	// Expected number of bytes, -1 if it is unknown.
+	Total int64
+	// Average throughput in bytes per second.
+	Rate float64
+}
+
+// ProgressFunc receives progress of hash computation. When used with HashFiles,
+// it may be called concurrently by multiple workers.
+type ProgressFunc func(p *Progress)
+
+// Struct progressTracker computes rate and invokes ProgressFunc. Nil tracker is a no-op.
+type progressTracker struct {
+	fn    ProgressFunc
+	path  string
+	total int64
+	start time.Time
+	// Bytes restored from a checkpoint, they are reported as done but excluded from rate.
+	resumed int64
+	// Bytes hashed by previous streams, added to values passed to update.
+	offset int64
+}
+
+func newProgressTracker(fPath string, total int64, fn ProgressFunc) *progressTracker {
+	if fn == nil {
+		return nil
+	}
+	return &progressTracker{
+		fn:    fn,
+		path:  fPath,
+		total: total,
+		start: time.Now(),
+	}
+}
+
+func (t *progressTracker) update(done int64) {
+	if t == nil {
+		return
+	}
+	hashed := t.offset + done
+	rate := float64(0)
+	if elapsed := time.Since(t.start).Seconds(); elapsed > 0 {
+		rate = float64(hashed) / elapsed
+	}
+	t.fn(&Progress{
+		Path:  t.path,
+		Done:  t.resumed + hashed,
+		Total: t.total,
+		Rate:  rate,
+	})
+}
+
+// Start reporting from offset restored from a checkpoint.
+func (t *progressTracker) resume(offset int64) {
+	if t == nil {
+		return
+	}
+	t.resumed = offset
+}
+
+// Account a stream of n bytes which has been hashed, so updates of the next stream continue from it.
+func (t *progressTracker) advance(n int64) {
+	if t == nil {
+		return
+	}
+	t.offset += n
+}
+
+// Report final progress. Total is set to actual size so consumers can rely on Done == Total.
+func (t *progressTracker) finish(done int64) {
+	if t == nil {
+		return
+	}
+	t.total = t.resumed + t.offset + done
+	t.update(done)
+}
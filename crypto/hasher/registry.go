@@ -0,0 +1,255 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"fmt"
+	"hash"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Algorithm describes a hash algorithm that can be used by Hash.
+type Algorithm struct {
+	Name    string           // canonical name, also used as checksum file extension
+	Aliases []string         // alternative names
+	New     func() hash.Hash // return new hash.Hash instance
+	Size    int              // digest size in bytes
+	BsdTag  string           // tag of BSD style checksum lines, uppercase name if empty
+
+	// Optional constructor for algorithms having variable output length (e.g. SHAKE).
+	// If set, the algorithm can also be looked up using name-bits (e.g. shake256-1024).
+	NewSized func(size int) hash.Hash
+
+	// Constructor for keyed algorithms (e.g. HMAC) instead of New. Lookup returns them with
+	// New using the key set by SetKey, they cannot be looked up until a key is set.
+	NewKeyed func(key []byte) hash.Hash
+}
+
+var registry = struct {
+	sync.RWMutex
+	algorithms map[string]*Algorithm
+	key        Key
+	names      map[string]string
+	tags       map[string]string
+}{
+	algorithms: map[string]*Algorithm{},
+	names:      map[string]string{},
+	tags:       map[string]string{},
+}
+
+// Register a hash algorithm so it can be used by Hash and every module reading
+// from the registry. Names and aliases are case-insensitive.
+// Register panics if the name or any alias has been registered.
+func Register(algo *Algorithm) {
+	if algo == nil || algo.Name == "" || (algo.New == nil && algo.NewKeyed == nil) {
+		panic("hasher: algorithm must have name and constructor")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	name := strings.ToLower(algo.Name)
+	keys := []string{name}
+	for _, alias := range algo.Aliases {
+		keys = append(keys, strings.ToLower(alias))
+	}
+	for _, key := range keys {
+		if _, ok := registry.names[key]; ok {
+			panic(fmt.Sprintf("hasher: algorithm '%s' is already registered", key))
+		}
+	}
+	if _, ok := registry.tags[algo.BsdTag]; ok && algo.BsdTag != "" {
+		panic(fmt.Sprintf("hasher: BSD tag '%s' is already registered", algo.BsdTag))
+	}
+	registered := *algo
+	registered.Name = name
+	registry.algorithms[name] = &registered
+	for _, key := range keys {
+		registry.names[key] = name
+	}
+	if algo.BsdTag != "" {
+		registry.tags[algo.BsdTag] = name
+	}
+}
+
+// Remove a registered algorithm along with its aliases. It is intended for tests only.
+func unregister(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+	name = strings.ToLower(name)
+	delete(registry.algorithms, name)
+	for key, canonical := range registry.names {
+		if canonical == name {
+			delete(registry.names, key)
+		}
+	}
+	for tag, canonical := range registry.tags {
+		if canonical == name {
+			delete(registry.tags, tag)
+		}
+	}
+}
+
+// Maximum output length in bits of algorithms looked up using name-bits.
+const maxSizedBits = 65536
+
+// Return algorithm registered using name or alias. Algorithms having variable
+// output length can be looked up using name-bits, up to maxSizedBits.
+func Lookup(name string) (*Algorithm, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	name = strings.ToLower(name)
+	if canonical, ok := registry.names[name]; ok {
+		algo := registry.algorithms[canonical]
+		if algo.NewKeyed != nil {
+			return keyedAlgorithm(algo, registry.key)
+		}
+		return algo, true
+	}
+
+	sepIndex := strings.LastIndex(name, "-")
+	if sepIndex < 0 {
+		return nil, false
+	}
+	canonical, ok := registry.names[name[:sepIndex]]
+	if !ok || registry.algorithms[canonical].NewSized == nil {
+		return nil, false
+	}
+	bits, err := strconv.Atoi(name[sepIndex+1:])
+	if err != nil || bits <= 0 || bits > maxSizedBits || bits%8 != 0 {
+		return nil, false
+	}
+	base := registry.algorithms[canonical]
+	return &Algorithm{
+		Name:     fmt.Sprintf("%s-%d", base.Name, bits),
+		New:      func() hash.Hash { return base.NewSized(bits / 8) },
+		Size:     bits / 8,
+		NewSized: base.NewSized,
+	}, true
+}
+
+// Return tag of BSD style checksum lines for the algorithm.
+func (a *Algorithm) Tag() string {
+	if a.BsdTag != "" {
+		return a.BsdTag
+	}
+	return strings.ToUpper(a.Name)
+}
+
+// Return tag of BSD style checksum lines for algorithm name, or uppercase name if it is not registered.
+func BsdTag(name string) string {
+	registry.RLock()
+	defer registry.RUnlock()
+	if canonical, ok := registry.names[strings.ToLower(name)]; ok {
+		return registry.algorithms[canonical].Tag()
+	}
+	return strings.ToUpper(name)
+}
+
+// Return canonical name of algorithm having the BSD tag, or lowercase tag if none is registered.
+// Tags are case-sensitive, other tags fall back to names and aliases.
+func NameByBsdTag(tag string) string {
+	registry.RLock()
+	defer registry.RUnlock()
+	if canonical, ok := registry.tags[tag]; ok {
+		return canonical
+	}
+	if canonical, ok := registry.names[strings.ToLower(tag)]; ok {
+		return canonical
+	}
+	return strings.ToLower(tag)
+}
+
+// Return keyed algorithm whose New uses key, or false if there is no key.
+func keyedAlgorithm(algo *Algorithm, key Key) (*Algorithm, bool) {
+	if len(key) == 0 {
+		return nil, false
+	}
+	keyed := *algo
+	keyed.New = func() hash.Hash { return algo.NewKeyed(key) }
+	return &keyed, true
+}
+
+// Check whether the algorithm is registered as a keyed algorithm, regardless of whether a key is set.
+func IsKeyed(name string) bool {
+	registry.RLock()
+	defer registry.RUnlock()
+	canonical, ok := registry.names[strings.ToLower(name)]
+	return ok && registry.algorithms[canonical].NewKeyed != nil
+}
+
+// Return all registered algorithms ordered by name. New of keyed algorithms is nil.
+func Algorithms() []*Algorithm {
+	registry.RLock()
+	defer registry.RUnlock()
+	algos := make([]*Algorithm, 0, len(registry.algorithms))
+	for _, algo := range registry.algorithms {
+		algos = append(algos, algo)
+	}
+	sort.Slice(algos, func(i, j int) bool {
+		return algos[i].Name < algos[j].Name
+	})
+	return algos
+}
+
+// Return canonical names of all registered algorithms ordered by name.
+func Names() []string {
+	algos := Algorithms()
+	names := make([]string, len(algos))
+	for i, algo := range algos {
+		names[i] = algo.Name
+	}
+	return names
+}
+
+// Check whether the algorithm is registered.
+func IsSupported(name string) bool {
+	_, ok := Lookup(name)
+	return ok
+}
+
+// Return canonical names for algorithms, or error if any of them is not registered.
+func Normalize(names []string) ([]string, error) {
+	canonicals := make([]string, len(names))
+	for i, name := range names {
+		algo, ok := Lookup(name)
+		if !ok && IsKeyed(name) {
+			return []string{}, fmt.Errorf("hash algorithm '%s' requires a key", name)
+		}
+		if !ok {
+			return []string{}, fmt.Errorf("unsupported hash algorithm: '%s'", name)
+		}
+		canonicals[i] = algo.Name
+	}
+	return canonicals, nil
+}
+
+// Return common algorithms used for identifying files: MD5, SHA-1, SHA-256, SHA-512.
+func CommonAlgorithms() []string {
+	return []string{"md5", "sha1", "sha256", "sha512"}
+}
+
+// Return the result computed using algorithm, or nil if it is not found.
+func FindResult(results []*HashResult, algorithm string) *HashResult {
+	for _, r := range results {
+		if r.Algorithm == algorithm {
+			return r
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"crypto/sha256"
+	"reflect"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		ok        bool
+	}{
+		{"sha256", "sha256", true},
+		{"SHA-256", "sha256", true},
+		{"RMD160", "ripemd160", true},
+		{"sha3", "", false},
+		{"shake256-1024", "shake256-1024", true},
+		{"shake256-65536", "shake256-65536", true},
+		{"shake256-65544", "", false},
+		{"shake256-99999999999999999999", "", false},
+		{"shake256-12", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algo, ok := Lookup(tt.name)
+			if ok != tt.ok {
+				t.Fatalf("wrong lookup result. expected %t actual %t", tt.ok, ok)
+			}
+			if ok && algo.Name != tt.algorithm {
+				t.Errorf("wrong algorithm. expected '%s' actual '%s'", tt.algorithm, algo.Name)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(&Algorithm{Name: "TEST-SHA256", Aliases: []string{"test-sha-256"}, New: sha256.New, Size: sha256.Size})
+	t.Cleanup(func() { unregister("test-sha256") })
+	algos, err := Normalize([]string{"Test-Sha-256", "sha1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(algos, []string{"test-sha256", "sha1"}) {
+		t.Errorf("wrong normalized names. actual %v", algos)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("duplicated registration should panic")
+		}
+	}()
+	Register(&Algorithm{Name: "sha-256", New: sha256.New, Size: sha256.Size})
+}
+
+func TestBsdTag(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+	}{
+		{"sha256", "SHA256"},
+		{"sha3-256", "SHA3-256"},
+		{"blake2b", "BLAKE2b"},
+		{"blake2b-512", "BLAKE2b"},
+		{"blake2b-256", "BLAKE2b-256"},
+		{"shake256-1024", "SHAKE256-1024"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag := BsdTag(tt.name)
+			if tag != tt.tag {
+				t.Errorf("wrong tag. expected '%s' actual '%s'", tt.tag, tag)
+			}
+			algo, ok := Lookup(tt.name)
+			if !ok {
+				t.Fatalf("algorithm '%s' is not registered", tt.name)
+			}
+			if name := NameByBsdTag(tag); name != algo.Name {
+				t.Errorf("wrong name. expected '%s' actual '%s'", algo.Name, name)
+			}
+		})
+	}
+}
@@ -18,6 +18,10 @@ package hasher
 
 import "golang.org/x/crypto/ripemd160"
 
+func init() {
+	Register(&Algorithm{Name: "ripemd160", Aliases: []string{"ripemd-160", "rmd160"}, New: ripemd160.New, Size: ripemd160.Size})
+}
+
 func HashRipemd160(fPath string) (*HashResult, error) {
 	return hashFile(fPath, ripemd160.New(), "ripemd160")
 }
@@ -18,6 +18,10 @@ package hasher
 
 import "crypto/sha1"
 
+func init() {
+	Register(&Algorithm{Name: "sha1", Aliases: []string{"sha-1"}, New: sha1.New, Size: sha1.Size})
+}
+
 func HashSha1(fPath string) (*HashResult, error) {
 	return hashFile(fPath, sha1.New(), "sha1")
 }
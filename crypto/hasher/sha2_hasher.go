@@ -21,6 +21,13 @@ import (
 	"crypto/sha512"
 )
 
+func init() {
+	Register(&Algorithm{Name: "sha224", Aliases: []string{"sha-224"}, New: sha256.New224, Size: sha256.Size224})
+	Register(&Algorithm{Name: "sha256", Aliases: []string{"sha-256"}, New: sha256.New, Size: sha256.Size})
+	Register(&Algorithm{Name: "sha384", Aliases: []string{"sha-384"}, New: sha512.New384, Size: sha512.Size384})
+	Register(&Algorithm{Name: "sha512", Aliases: []string{"sha-512"}, New: sha512.New, Size: sha512.Size})
+}
+
 func HashSha224(fPath string) (*HashResult, error) {
 	return hashFile(fPath, sha256.New224(), "sha224")
 }
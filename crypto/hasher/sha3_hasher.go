@@ -0,0 +1,62 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"hash"
+
+	"golang.org/x/crypto/sha3"
+)
+
+func init() {
+	Register(&Algorithm{Name: "sha3-224", Aliases: []string{"sha3_224"}, New: sha3.New224, Size: 28})
+	Register(&Algorithm{Name: "sha3-256", Aliases: []string{"sha3_256"}, New: sha3.New256, Size: 32})
+	Register(&Algorithm{Name: "sha3-384", Aliases: []string{"sha3_384"}, New: sha3.New384, Size: 48})
+	Register(&Algorithm{Name: "sha3-512", Aliases: []string{"sha3_512"}, New: sha3.New512, Size: 64})
+	Register(&Algorithm{
+		Name:     "shake128",
+		New:      func() hash.Hash { return newShake(sha3.NewShake128(), 32) },
+		Size:     32,
+		NewSized: func(size int) hash.Hash { return newShake(sha3.NewShake128(), size) },
+	})
+	Register(&Algorithm{
+		Name:     "shake256",
+		New:      func() hash.Hash { return newShake(sha3.NewShake256(), 64) },
+		Size:     64,
+		NewSized: func(size int) hash.Hash { return newShake(sha3.NewShake256(), size) },
+	})
+}
+
+// shakeHash adapts SHAKE extendable-output functions to hash.Hash with fixed output length.
+type shakeHash struct {
+	sha3.ShakeHash
+	size int
+}
+
+func newShake(h sha3.ShakeHash, size int) hash.Hash {
+	return &shakeHash{h, size}
+}
+
+func (h *shakeHash) Size() int {
+	return h.size
+}
+
+func (h *shakeHash) Sum(b []byte) []byte {
+	digest := make([]byte, h.size)
+	h.ShakeHash.Clone().Read(digest)
+	return append(b, digest...)
+}
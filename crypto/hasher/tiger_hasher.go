@@ -0,0 +1,159 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	tigerSize      = 24
+	tigerBlockSize = 64
+)
+
+func init() {
+	Register(&Algorithm{Name: "tiger", Aliases: []string{"tiger192"}, New: newTiger, Size: tigerSize})
+}
+
+// tigerDigest implements the original Tiger hash function (Tiger/192 with 0x01 padding),
+// which is used by Tiger Tree Hash.
+type tigerDigest struct {
+	a, b, c uint64
+	buf     [tigerBlockSize]byte
+	nbuf    int
+	length  uint64
+}
+
+func newTiger() hash.Hash {
+	d := &tigerDigest{}
+	d.Reset()
+	return d
+}
+
+func (d *tigerDigest) Reset() {
+	d.a = 0x0123456789ABCDEF
+	d.b = 0xFEDCBA9876543210
+	d.c = 0xF096A5B4C3B2E187
+	d.nbuf = 0
+	d.length = 0
+}
+
+func (d *tigerDigest) Size() int {
+	return tigerSize
+}
+
+func (d *tigerDigest) BlockSize() int {
+	return tigerBlockSize
+}
+
+func (d *tigerDigest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.length += uint64(n)
+	if d.nbuf > 0 {
+		copied := copy(d.buf[d.nbuf:], p)
+		d.nbuf += copied
+		p = p[copied:]
+		if d.nbuf < tigerBlockSize {
+			return n, nil
+		}
+		d.compress(d.buf[:])
+		d.nbuf = 0
+	}
+	for len(p) >= tigerBlockSize {
+		d.compress(p[:tigerBlockSize])
+		p = p[tigerBlockSize:]
+	}
+	d.nbuf = copy(d.buf[:], p)
+	return n, nil
+}
+
+func (d *tigerDigest) Sum(b []byte) []byte {
+	// work on a copy so caller can keep writing.
+	dd := *d
+	length := dd.length
+	padding := make([]byte, tigerBlockSize+8)
+	padding[0] = 0x01
+	padLen := tigerBlockSize - int(length%tigerBlockSize)
+	if padLen < 9 {
+		padLen += tigerBlockSize
+	}
+	binary.LittleEndian.PutUint64(padding[padLen-8:], length<<3)
+	dd.Write(padding[:padLen])
+
+	digest := make([]byte, tigerSize)
+	binary.LittleEndian.PutUint64(digest[0:], dd.a)
+	binary.LittleEndian.PutUint64(digest[8:], dd.b)
+	binary.LittleEndian.PutUint64(digest[16:], dd.c)
+	return append(b, digest...)
+}
+
+func (d *tigerDigest) compress(block []byte) {
+	var x [8]uint64
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint64(block[i*8:])
+	}
+	a, b, c := d.a, d.b, d.c
+
+	tigerPass(&a, &b, &c, &x, 5)
+	tigerKeySchedule(&x)
+	tigerPass(&c, &a, &b, &x, 7)
+	tigerKeySchedule(&x)
+	tigerPass(&b, &c, &a, &x, 9)
+
+	d.a ^= a
+	d.b = b - d.b
+	d.c += c
+}
+
+func tigerPass(a, b, c *uint64, x *[8]uint64, mul uint64) {
+	tigerRound(a, b, c, x[0], mul)
+	tigerRound(b, c, a, x[1], mul)
+	tigerRound(c, a, b, x[2], mul)
+	tigerRound(a, b, c, x[3], mul)
+	tigerRound(b, c, a, x[4], mul)
+	tigerRound(c, a, b, x[5], mul)
+	tigerRound(a, b, c, x[6], mul)
+	tigerRound(b, c, a, x[7], mul)
+}
+
+func tigerRound(a, b, c *uint64, x, mul uint64) {
+	*c ^= x
+	cc := *c
+	*a -= tigerSbox[0][byte(cc)] ^ tigerSbox[1][byte(cc>>16)] ^ tigerSbox[2][byte(cc>>32)] ^ tigerSbox[3][byte(cc>>48)]
+	*b += tigerSbox[3][byte(cc>>8)] ^ tigerSbox[2][byte(cc>>24)] ^ tigerSbox[1][byte(cc>>40)] ^ tigerSbox[0][byte(cc>>56)]
+	*b *= mul
+}
+
+func tigerKeySchedule(x *[8]uint64) {
+	x[0] -= x[7] ^ 0xA5A5A5A5A5A5A5A5
+	x[1] ^= x[0]
+	x[2] += x[1]
+	x[3] -= x[2] ^ (^x[1] << 19)
+	x[4] ^= x[3]
+	x[5] += x[4]
+	x[6] -= x[5] ^ (^x[4] >> 23)
+	x[7] ^= x[6]
+	x[0] += x[7]
+	x[1] -= x[0] ^ (^x[7] << 19)
+	x[2] ^= x[1]
+	x[3] += x[2]
+	x[4] -= x[3] ^ (^x[2] >> 23)
+	x[5] ^= x[4]
+	x[6] += x[5]
+	x[7] -= x[6] ^ 0x0123456789ABCDEF
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import "hash"
+
+const (
+	tthSize     = 24
+	tthLeafSize = 1024
+)
+
+func init() {
+	Register(&Algorithm{Name: "tth", Aliases: []string{"tigertree"}, New: newTigerTree, Size: tthSize})
+}
+
+// tigerTreeNode is a subtree hash waiting to be combined with its sibling.
+type tigerTreeNode struct {
+	level int
+	hash  []byte
+}
+
+// tigerTreeDigest implements Tiger Tree Hash (THEX) as used by DC++ and Gnutella.
+// Leaves are Tiger(0x00 || 1024-byte block), internal nodes are Tiger(0x01 || left || right),
+// a node without sibling is promoted to the next level unchanged.
+// Completed subtrees are kept on a stack so memory usage is logarithmic to content size.
+type tigerTreeDigest struct {
+	leaf   hash.Hash
+	nleaf  int
+	leaves int64
+	stack  []*tigerTreeNode
+}
+
+func newTigerTree() hash.Hash {
+	d := &tigerTreeDigest{leaf: newTiger()}
+	d.Reset()
+	return d
+}
+
+func (d *tigerTreeDigest) Reset() {
+	d.leaf.Reset()
+	d.leaf.Write([]byte{0x00})
+	d.nleaf = 0
+	d.leaves = 0
+	d.stack = []*tigerTreeNode{}
+}
+
+func (d *tigerTreeDigest) Size() int {
+	return tthSize
+}
+
+func (d *tigerTreeDigest) BlockSize() int {
+	return tthLeafSize
+}
+
+func (d *tigerTreeDigest) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		size := tthLeafSize - d.nleaf
+		if size > len(p) {
+			size = len(p)
+		}
+		d.leaf.Write(p[:size])
+		d.nleaf += size
+		p = p[size:]
+		if d.nleaf == tthLeafSize {
+			d.pushLeaf()
+		}
+	}
+	return n, nil
+}
+
+func (d *tigerTreeDigest) Sum(b []byte) []byte {
+	stack := d.stack
+	// empty content still has a single leaf.
+	if d.nleaf > 0 || d.leaves == 0 {
+		stack = append(stack[:len(stack):len(stack)], &tigerTreeNode{0, d.leaf.Sum(nil)})
+	}
+	root := stack[len(stack)-1].hash
+	for i := len(stack) - 2; i >= 0; i-- {
+		root = tigerTreeInternal(stack[i].hash, root)
+	}
+	return append(b, root...)
+}
+
+// Push hash of the current leaf to the stack, then merge subtrees of the same level.
+func (d *tigerTreeDigest) pushLeaf() {
+	d.stack = append(d.stack, &tigerTreeNode{0, d.leaf.Sum(nil)})
+	d.leaves++
+	for len(d.stack) > 1 {
+		last := len(d.stack) - 1
+		if d.stack[last].level != d.stack[last-1].level {
+			break
+		}
+		node := &tigerTreeNode{
+			level: d.stack[last].level + 1,
+			hash:  tigerTreeInternal(d.stack[last-1].hash, d.stack[last].hash),
+		}
+		d.stack = append(d.stack[:last-1], node)
+	}
+	d.leaf.Reset()
+	d.leaf.Write([]byte{0x00})
+	d.nleaf = 0
+}
+
+func tigerTreeInternal(left, right []byte) []byte {
+	h := newTiger()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
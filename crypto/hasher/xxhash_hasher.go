@@ -0,0 +1,44 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hasher
+
+import (
+	"hash"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/zeebo/xxh3"
+)
+
+func init() {
+	Register(&Algorithm{Name: "xxh3-64", Aliases: []string{"xxh3"}, New: func() hash.Hash { return xxh3.New() }, Size: 8})
+	Register(&Algorithm{Name: "xxh3-128", Aliases: []string{"xxh128"}, New: func() hash.Hash { return &xxh3Hash128{xxh3.New()} }, Size: 16})
+	Register(&Algorithm{Name: "xxh64", Aliases: []string{"xxhash64"}, New: func() hash.Hash { return xxhash.New() }, Size: 8})
+}
+
+// xxh3Hash128 exposes 128-bit variant of XXH3 as hash.Hash, digest is in canonical (big-endian) form.
+type xxh3Hash128 struct {
+	*xxh3.Hasher
+}
+
+func (h *xxh3Hash128) Size() int {
+	return 16
+}
+
+func (h *xxh3Hash128) Sum(b []byte) []byte {
+	digest := h.Hasher.Sum128().Bytes()
+	return append(b, digest[:]...)
+}
@@ -0,0 +1,295 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+/*
+Package minisign reads and writes Ed25519 keys and detached signatures in minisign
+format, so signatures can be checked using minisign and vice versa.
+*/
+package minisign
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// Default scrypt operation limit used by minisign to encrypt secret keys.
+	DefaultOpsLimit = 33554432
+	// Default scrypt memory limit used by minisign to encrypt secret keys.
+	DefaultMemLimit = 1073741824
+
+	untrustedPrefix = "untrusted comment: "
+	trustedPrefix   = "trusted comment: "
+	// Size of key ID, Ed25519 secret key and its checksum which are encrypted together.
+	keynumSize    = 8 + ed25519.PrivateKeySize + blake2b.Size256
+	publicKeySize = 2 + 8 + ed25519.PublicKeySize
+	secretKeySize = 2 + 2 + 2 + 32 + 8 + 8 + keynumSize
+)
+
+var (
+	algEd     = []byte("Ed")
+	algScrypt = []byte("Sc")
+	algBlake2 = []byte("B2")
+
+	ErrEncryptedKey = errors.New("secret key is encrypted, password is required")
+	ErrWrongKey     = errors.New("wrong password or corrupted secret key")
+)
+
+// Struct PublicKey is an Ed25519 public key along with its minisign key ID.
+type PublicKey struct {
+	ID  [8]byte
+	Key ed25519.PublicKey
+}
+
+// Struct SecretKey is an Ed25519 secret key along with its minisign key ID.
+type SecretKey struct {
+	ID  [8]byte
+	Key ed25519.PrivateKey
+}
+
+// Generate a new key pair with random key ID.
+func GenerateKey() (*PublicKey, *SecretKey, error) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	var id [8]byte
+	if _, err := io.ReadFull(rand.Reader, id[:]); err != nil {
+		return nil, nil, err
+	}
+	return &PublicKey{ID: id, Key: pk}, &SecretKey{ID: id, Key: sk}, nil
+}
+
+// Return key ID in the format printed by minisign.
+func (k *PublicKey) KeyID() string {
+	return formatKeyID(k.ID)
+}
+
+// Return public key as a single base64 line, which minisign accepts using -P.
+func (k *PublicKey) String() string {
+	buf := make([]byte, 0, publicKeySize)
+	buf = append(buf, algEd...)
+	buf = append(buf, k.ID[:]...)
+	buf = append(buf, k.Key...)
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// Return content of public key file.
+func (k *PublicKey) Encode() []byte {
+	return []byte(untrustedPrefix + "minisign public key " + k.KeyID() + "\n" + k.String() + "\n")
+}
+
+// Parse content of public key file, or a single base64 line.
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	lines := readLines(data)
+	if len(lines) == 2 && strings.HasPrefix(lines[0], untrustedPrefix) {
+		lines = lines[1:]
+	}
+	if len(lines) != 1 {
+		return nil, errors.New("invalid public key format")
+	}
+	buf, err := base64.StdEncoding.DecodeString(lines[0])
+	if err != nil || len(buf) != publicKeySize {
+		return nil, errors.New("invalid public key encoding")
+	}
+	if !bytes.Equal(buf[:2], algEd) {
+		return nil, fmt.Errorf("unsupported signature algorithm '%s'", buf[:2])
+	}
+	k := &PublicKey{Key: ed25519.PublicKey(bytes.Clone(buf[10:]))}
+	copy(k.ID[:], buf[2:10])
+	return k, nil
+}
+
+// Read public key from a file.
+func ReadPublicKey(fPath string) (*PublicKey, error) {
+	data, err := os.ReadFile(fPath)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublicKey(data)
+}
+
+// Return public key of the secret key.
+func (k *SecretKey) Public() *PublicKey {
+	return &PublicKey{
+		ID:  k.ID,
+		Key: k.Key.Public().(ed25519.PublicKey),
+	}
+}
+
+// Return content of secret key file. The key is encrypted using scrypt with default limits
+// of minisign if password is not empty, otherwise it is stored unencrypted.
+func (k *SecretKey) Encode(password []byte) ([]byte, error) {
+	return k.encode(password, DefaultOpsLimit, DefaultMemLimit)
+}
+
+func (k *SecretKey) encode(password []byte, opsLimit, memLimit uint64) ([]byte, error) {
+	buf := make([]byte, 0, secretKeySize)
+	buf = append(buf, algEd...)
+	buf = append(buf, 0, 0)
+	buf = append(buf, algBlake2...)
+	salt := make([]byte, 32)
+	keynum := make([]byte, 0, keynumSize)
+	keynum = append(keynum, k.ID[:]...)
+	keynum = append(keynum, k.Key...)
+	keynum = append(keynum, secretKeyChecksum(k.ID, k.Key)...)
+	comment := "minisign secret key"
+	if len(password) > 0 {
+		copy(buf[2:4], algScrypt)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, err
+		}
+		if err := xorStream(keynum, password, salt, opsLimit, memLimit); err != nil {
+			return nil, err
+		}
+		comment = "minisign encrypted secret key"
+	} else {
+		opsLimit, memLimit = 0, 0
+	}
+	buf = append(buf, salt...)
+	buf = binary.LittleEndian.AppendUint64(buf, opsLimit)
+	buf = binary.LittleEndian.AppendUint64(buf, memLimit)
+	buf = append(buf, keynum...)
+	return []byte(untrustedPrefix + comment + "\n" + base64.StdEncoding.EncodeToString(buf) + "\n"), nil
+}
+
+// Parse content of secret key file. Password is only used if the key is encrypted.
+func ParseSecretKey(data, password []byte) (*SecretKey, error) {
+	lines := readLines(data)
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], untrustedPrefix) {
+		return nil, errors.New("invalid secret key format")
+	}
+	buf, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(buf) != secretKeySize {
+		return nil, errors.New("invalid secret key encoding")
+	}
+	if !bytes.Equal(buf[:2], algEd) {
+		return nil, fmt.Errorf("unsupported signature algorithm '%s'", buf[:2])
+	}
+	if !bytes.Equal(buf[4:6], algBlake2) {
+		return nil, fmt.Errorf("unsupported checksum algorithm '%s'", buf[4:6])
+	}
+	keynum := buf[secretKeySize-keynumSize:]
+	switch {
+	case bytes.Equal(buf[2:4], algScrypt):
+		if len(password) == 0 {
+			return nil, ErrEncryptedKey
+		}
+		opsLimit := binary.LittleEndian.Uint64(buf[38:46])
+		memLimit := binary.LittleEndian.Uint64(buf[46:54])
+		if err := xorStream(keynum, password, buf[6:38], opsLimit, memLimit); err != nil {
+			return nil, err
+		}
+	case buf[2] != 0 || buf[3] != 0:
+		return nil, fmt.Errorf("unsupported key derivation algorithm '%s'", buf[2:4])
+	}
+	k := &SecretKey{Key: ed25519.PrivateKey(bytes.Clone(keynum[8:72]))}
+	copy(k.ID[:], keynum[:8])
+	if subtle.ConstantTimeCompare(keynum[72:], secretKeyChecksum(k.ID, k.Key)) != 1 {
+		return nil, ErrWrongKey
+	}
+	return k, nil
+}
+
+// Read secret key from a file.
+func ReadSecretKey(fPath string, password []byte) (*SecretKey, error) {
+	data, err := os.ReadFile(fPath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSecretKey(data, password)
+}
+
+// Return BLAKE2b-256 checksum of a secret key, used to detect wrong passwords.
+func secretKeyChecksum(id [8]byte, key ed25519.PrivateKey) []byte {
+	h, _ := blake2b.New256(nil)
+	h.Write(algEd)
+	h.Write(id[:])
+	h.Write(key)
+	return h.Sum(nil)
+}
+
+// Encrypt or decrypt data in place using a stream derived from password by scrypt.
+func xorStream(data, password, salt []byte, opsLimit, memLimit uint64) error {
+	nLog2, r, p := scryptParams(opsLimit, memLimit)
+	stream, err := scrypt.Key(password, salt, 1<<nLog2, r, p, len(data))
+	if err != nil {
+		return err
+	}
+	for i := range data {
+		data[i] ^= stream[i]
+	}
+	return nil
+}
+
+// Convert operation and memory limits to scrypt parameters the same way libsodium does.
+func scryptParams(opsLimit, memLimit uint64) (nLog2 uint, r, p int) {
+	if opsLimit < 32768 {
+		opsLimit = 32768
+	}
+	r = 8
+	if opsLimit < memLimit/32 {
+		return scryptLogN(opsLimit / uint64(r*4)), r, 1
+	}
+	nLog2 = scryptLogN(memLimit / uint64(r*128))
+	maxRP := (opsLimit / 4) / (uint64(1) << nLog2)
+	if maxRP > 0x3fffffff {
+		maxRP = 0x3fffffff
+	}
+	return nLog2, r, int(maxRP) / r
+}
+
+// Return the smallest power of 2 exponent which is greater than half of maxN.
+func scryptLogN(maxN uint64) uint {
+	nLog2 := uint(1)
+	for ; nLog2 < 63; nLog2++ {
+		if uint64(1)<<nLog2 > maxN/2 {
+			break
+		}
+	}
+	return nLog2
+}
+
+// Return key ID as upper case hexadecimal of a little endian integer.
+func formatKeyID(id [8]byte) string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
+
+// Return non-empty lines of data without trailing carriage returns.
+func readLines(data []byte) []string {
+	lines := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
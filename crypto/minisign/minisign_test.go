@@ -0,0 +1,166 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package minisign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParsePublicKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		keyID string
+		ok    bool
+	}{
+		{"line", "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3", "E7620F1842B4E81F", true},
+		{"file", "untrusted comment: minisign public key E7620F1842B4E81F\r\nRWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3\r\n", "E7620F1842B4E81F", true},
+		{"truncated", "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7", "", false},
+		{"algorithm", "RVFf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3", "", false},
+		{"empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := ParsePublicKey([]byte(tt.data))
+			if (err == nil) != tt.ok {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if k.KeyID() != tt.keyID {
+				t.Errorf("wrong key ID. expected '%s' actual '%s'", tt.keyID, k.KeyID())
+			}
+			if !strings.Contains(tt.data, k.String()) {
+				t.Errorf("public key is not encoded back to '%s'", k.String())
+			}
+		})
+	}
+}
+
+func TestSecretKey(t *testing.T) {
+	pk, sk, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := sk.encode(nil, 0, 0)
+	encrypted, err := sk.encode([]byte("password"), 32768, 16*1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		data     []byte
+		password string
+		err      error
+	}{
+		{"plain", plain, "", nil},
+		{"plain_with_password", plain, "password", nil},
+		{"encrypted", encrypted, "password", nil},
+		{"no_password", encrypted, "", ErrEncryptedKey},
+		{"wrong_password", encrypted, "drowssap", ErrWrongKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := ParseSecretKey(tt.data, []byte(tt.password))
+			if err != tt.err {
+				t.Fatalf("wrong error. expected '%v' actual '%v'", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if k.ID != sk.ID || !bytes.Equal(k.Key, sk.Key) {
+				t.Errorf("secret key mismatch")
+			}
+			if k.Public().String() != pk.String() {
+				t.Errorf("public key mismatch. expected '%s' actual '%s'", pk.String(), k.Public().String())
+			}
+		})
+	}
+}
+
+func TestScryptParams(t *testing.T) {
+	tests := []struct {
+		opsLimit uint64
+		memLimit uint64
+		nLog2    uint
+		r        int
+		p        int
+	}{
+		{DefaultOpsLimit, DefaultMemLimit, 20, 8, 1},
+		{32768, 16 * 1024 * 1024, 10, 8, 1},
+		{1 << 30, 1 << 24, 14, 8, 2048},
+	}
+	for _, tt := range tests {
+		nLog2, r, p := scryptParams(tt.opsLimit, tt.memLimit)
+		if nLog2 != tt.nLog2 || r != tt.r || p != tt.p {
+			t.Errorf("wrong params for %d/%d. expected %d/%d/%d actual %d/%d/%d", tt.opsLimit, tt.memLimit, tt.nLog2, tt.r, tt.p, nLog2, r, p)
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	pk, sk, _ := GenerateKey()
+	otherPk, _, _ := GenerateKey()
+	content := []byte("c1a44dd5f1e5be612408eac67aed6f60fa6abdee  curl\n")
+	sig, err := Sign(sk, bytes.NewReader(content), "timestamp:1700000000\tfile:SHA1SUMS\thashed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseSignature(sig.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := *parsed
+	tampered.TrustedComment = "timestamp:1800000000\tfile:SHA1SUMS\thashed"
+	legacy := *parsed
+	copy(legacy.Algorithm[:], algEd)
+	legacy.Signature = ed25519.Sign(sk.Key, content)
+	legacy.GlobalSignature = ed25519.Sign(sk.Key, legacy.globalMessage())
+
+	tests := []struct {
+		name    string
+		key     *PublicKey
+		content []byte
+		sig     *Signature
+		ok      bool
+	}{
+		{"valid", pk, content, parsed, true},
+		{"legacy", pk, content, &legacy, true},
+		{"content", pk, append(content, '\n'), parsed, false},
+		{"comment", pk, content, &tampered, false},
+		{"other_key", otherPk, content, parsed, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.key, bytes.NewReader(tt.content), tt.sig)
+			if (err == nil) != tt.ok {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+	if _, err := Sign(sk, bytes.NewReader(content), "line\nbreak"); err == nil {
+		t.Errorf("multi-line trusted comment is accepted")
+	}
+	if err := Verify(pk, bytes.NewReader(append(content, '\n')), parsed); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("wrong error of tampered content: %v", err)
+	}
+}
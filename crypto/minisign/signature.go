@@ -0,0 +1,180 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package minisign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const signatureSize = 2 + 8 + ed25519.SignatureSize
+
+var (
+	// Algorithm of signatures over BLAKE2b-512 digest of content, default of minisign 0.8 and later.
+	algHashedEd = []byte("ED")
+
+	ErrInvalidSignature = errors.New("signature verification failed")
+)
+
+// Struct Signature is a detached minisign signature. TrustedComment is signed
+// along with the signature, UntrustedComment is not.
+type Signature struct {
+	Algorithm        [2]byte
+	GlobalSignature  []byte
+	KeyID            [8]byte
+	Signature        []byte
+	TrustedComment   string
+	UntrustedComment string
+}
+
+// Sign content of a reader. The signature is computed over BLAKE2b-512 digest of content,
+// so content of any size is read once without being held in memory.
+func Sign(k *SecretKey, r io.Reader, trustedComment string) (*Signature, error) {
+	if strings.ContainsAny(trustedComment, "\r\n") {
+		return nil, errors.New("trusted comment must be a single line")
+	}
+	digest, err := prehash(r)
+	if err != nil {
+		return nil, err
+	}
+	s := &Signature{
+		KeyID:            k.ID,
+		Signature:        ed25519.Sign(k.Key, digest),
+		TrustedComment:   trustedComment,
+		UntrustedComment: "signature from minisign secret key",
+	}
+	copy(s.Algorithm[:], algHashedEd)
+	s.GlobalSignature = ed25519.Sign(k.Key, s.globalMessage())
+	return s, nil
+}
+
+// Sign content of a file.
+func SignFile(k *SecretKey, fPath, trustedComment string) (*Signature, error) {
+	fHandle, err := os.Open(fPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fHandle.Close()
+	return Sign(k, fHandle, trustedComment)
+}
+
+// Verify signature of content of a reader, including its trusted comment.
+func Verify(k *PublicKey, r io.Reader, s *Signature) error {
+	if k.ID != s.KeyID {
+		return fmt.Errorf("signature was created by key %s, but key %s is used", formatKeyID(s.KeyID), k.KeyID())
+	}
+	var message []byte
+	var err error
+	switch {
+	case bytes.Equal(s.Algorithm[:], algHashedEd):
+		message, err = prehash(r)
+	case bytes.Equal(s.Algorithm[:], algEd):
+		message, err = io.ReadAll(r)
+	default:
+		return fmt.Errorf("unsupported signature algorithm '%s'", s.Algorithm[:])
+	}
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(k.Key, message, s.Signature) {
+		return ErrInvalidSignature
+	}
+	if !ed25519.Verify(k.Key, s.globalMessage(), s.GlobalSignature) {
+		return errors.New("trusted comment verification failed")
+	}
+	return nil
+}
+
+// Verify signature of content of a file.
+func VerifyFile(k *PublicKey, fPath string, s *Signature) error {
+	fHandle, err := os.Open(fPath)
+	if err != nil {
+		return err
+	}
+	defer fHandle.Close()
+	return Verify(k, fHandle, s)
+}
+
+// Return content of signature file.
+func (s *Signature) Encode() []byte {
+	buf := make([]byte, 0, signatureSize)
+	buf = append(buf, s.Algorithm[:]...)
+	buf = append(buf, s.KeyID[:]...)
+	buf = append(buf, s.Signature...)
+	return []byte(untrustedPrefix + s.UntrustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(buf) + "\n" +
+		trustedPrefix + s.TrustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(s.GlobalSignature) + "\n")
+}
+
+// Parse content of signature file.
+func ParseSignature(data []byte) (*Signature, error) {
+	lines := readLines(data)
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], untrustedPrefix) || !strings.HasPrefix(lines[2], trustedPrefix) {
+		return nil, errors.New("invalid signature format")
+	}
+	buf, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(buf) != signatureSize {
+		return nil, errors.New("invalid signature encoding")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return nil, errors.New("invalid global signature encoding")
+	}
+	s := &Signature{
+		GlobalSignature:  globalSig,
+		Signature:        bytes.Clone(buf[10:]),
+		TrustedComment:   strings.TrimPrefix(lines[2], trustedPrefix),
+		UntrustedComment: strings.TrimPrefix(lines[0], untrustedPrefix),
+	}
+	copy(s.Algorithm[:], buf[:2])
+	copy(s.KeyID[:], buf[2:10])
+	return s, nil
+}
+
+// Read signature from a file.
+func ReadSignature(fPath string) (*Signature, error) {
+	data, err := os.ReadFile(fPath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSignature(data)
+}
+
+// Return message signed by global signature, which is the signature followed by trusted comment.
+func (s *Signature) globalMessage() []byte {
+	message := make([]byte, 0, len(s.Signature)+len(s.TrustedComment))
+	message = append(message, s.Signature...)
+	return append(message, s.TrustedComment...)
+}
+
+// Return BLAKE2b-512 digest of content of a reader.
+func prehash(r io.Reader) ([]byte, error) {
+	h, _ := blake2b.New512(nil)
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// CachedHash stores a hash of a file computed previously. It stays valid as long as
+// the file, identified by its device and inode, has the same size and modification time.
+type CachedHash struct {
+	Device    int64  `gorm:"column:device;primaryKey;autoIncrement:false"`
+	Inode     int64  `gorm:"column:inode;primaryKey;autoIncrement:false"`
+	Algorithm string `gorm:"column:algorithm;primaryKey"`
+	Size      int64  `gorm:"column:size"`
+	ModTime   int64  `gorm:"column:mod_time"`
+	Hash      string `gorm:"column:hash"`
+}
+
+// Return new CachedHash. Device and inode are stored as signed integers since SQLite
+// doesn't support unsigned 64-bit integers.
+func NewCachedHash(device, inode uint64, size int64, modTime time.Time, algorithm, hash string) *CachedHash {
+	return &CachedHash{
+		Device:    int64(device),
+		Inode:     int64(inode),
+		Algorithm: algorithm,
+		Size:      size,
+		ModTime:   modTime.UnixNano(),
+		Hash:      hash,
+	}
+}
+
+// Get CachedHashes of a file. Entries outdated by changes of size or modification time are ignored.
+func (c *DbContext) GetCachedHashes(device, inode uint64, size int64, modTime time.Time) ([]*CachedHash, error) {
+	return c.findCachedHashes(int64(device), int64(inode), size, modTime.UnixNano())
+}
+
+// Save CachedHashes to database. Existing entries of the same file and algorithm will be replaced.
+func (c *DbContext) SaveCachedHashes(hashes []*CachedHash) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	result := c.db.Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(hashes)
+	return result.Error
+}
+
+// Return CachedHashes of a file that have specified size and modification time.
+func (c *DbContext) findCachedHashes(device, inode, size, modTime int64) ([]*CachedHash, error) {
+	var docs []*CachedHash
+	result := c.db.Model(&CachedHash{}).
+		Where("device = ? AND inode = ? AND size = ? AND mod_time = ?", device, inode, size, modTime).
+		Find(&docs)
+	return docs, result.Error
+}
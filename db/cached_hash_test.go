@@ -0,0 +1,81 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveCachedHashes(t *testing.T) {
+	ctx, err := ConnectCache(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = ctx.SaveCachedHashes([]*CachedHash{
+		NewCachedHash(1, 2, 3, modTime, "md5", "900150983cd24fb0d6963f7d28e17f72"),
+		NewCachedHash(1, 2, 3, modTime, "sha1", "a9993e364706816aba3e25717850c26c9cd0d89d"),
+		NewCachedHash(1, 1<<63+5, 3, modTime, "md5", "900150983cd24fb0d6963f7d28e17f72"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		inode   uint64
+		size    int64
+		modTime time.Time
+		count   int
+	}{
+		{"unchanged", 2, 3, modTime, 2},
+		{"large inode", 1<<63 + 5, 3, modTime, 1},
+		{"size changed", 2, 4, modTime, 0},
+		{"mtime changed", 2, 3, modTime.Add(time.Second), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashes, err := ctx.GetCachedHashes(1, tt.inode, tt.size, tt.modTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hashes) != tt.count {
+				t.Errorf("Wrong cached hashes count. Expected %d. Actual %d.", tt.count, len(hashes))
+			}
+		})
+	}
+
+	t.Run("replace outdated", func(t *testing.T) {
+		newModTime := modTime.Add(time.Hour)
+		err := ctx.SaveCachedHashes([]*CachedHash{
+			NewCachedHash(1, 2, 4, newModTime, "md5", "5d41402abc4b2a76b9719d911017c592"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes, _ := ctx.GetCachedHashes(1, 2, 4, newModTime)
+		if len(hashes) != 1 || hashes[0].Hash != "5d41402abc4b2a76b9719d911017c592" {
+			t.Errorf("Wrong cached hash. Actual %v.", hashes)
+		}
+		count, _ := ctx.Count(&CachedHash{}, nil, nil)
+		if count != 3 {
+			t.Errorf("Wrong cached hashes count. Expected %d. Actual %d.", 3, count)
+		}
+	})
+}
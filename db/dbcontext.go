@@ -24,7 +24,7 @@ import (
 	"gorm.io/gorm"
 )
 
-var SchemaVersion = 2
+var SchemaVersion = 3
 
 // DbContext encapsulate all actions related to reading from and writing to database.
 type DbContext struct {
@@ -35,6 +35,30 @@ type DbContext struct {
 // Return new DbContext if the connection is successful.
 // Target database will be migrated to match database models.
 func Connect(uri string) (*DbContext, error) {
+	c, err := open(uri)
+	if err != nil {
+		return nil, err
+	}
+	c.Migrate()
+	return c, nil
+}
+
+// Return new DbContext of a hash cache database if the connection is successful.
+// Hash cache is kept in a separated database since it can be safely deleted at anytime.
+func ConnectCache(uri string) (*DbContext, error) {
+	c, err := open(uri)
+	if err != nil {
+		return nil, err
+	}
+	err = c.db.AutoMigrate(&CachedHash{}, &HashCheckpoint{})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Open connection to database, parent directory will be created if it's not existed.
+func open(uri string) (*DbContext, error) {
 	parentDir := filepath.Dir(uri)
 	if !filesystem.IsDirectoryExist(parentDir) {
 		err := filesystem.CreateDirectoryRecursive(parentDir)
@@ -46,9 +70,7 @@ func Connect(uri string) (*DbContext, error) {
 	if err != nil {
 		return nil, err
 	}
-	c := &DbContext{db, uri}
-	c.Migrate()
-	return c, nil
+	return &DbContext{db, uri}, nil
 }
 
 // Disconnect from database. Currently used for Gorm.
@@ -29,6 +29,9 @@ type Hash struct {
 	Sha256 string    `gorm:"column:sha256;uniqueIndex"`
 	Sha512 string    `gorm:"column:sha512"`
 
+	Fingerprint    string `gorm:"column:fingerprint;index"`
+	KeyFingerprint string `gorm:"column:key_fingerprint;index"`
+
 	Size        uint32 `gorm:"column:size"`
 	Description string `gorm:"column:description"`
 	IsIgnored   bool   `gorm:"column:is_ignored"`
@@ -43,6 +46,7 @@ func NewHash(fileHashes *core.FileMultiHash, isIgnored bool) *Hash {
 		Sha1:        fileHashes.Sha1.HexStr(),
 		Sha256:      fileHashes.Sha256.HexStr(),
 		Sha512:      fileHashes.Sha512.HexStr(),
+		Fingerprint: fileHashes.Fingerprint.HexStr(),
 		Size:        fileHashes.Size,
 		Description: fileHashes.FileName,
 		IsIgnored:   isIgnored,
@@ -69,6 +73,29 @@ func (c *DbContext) GetHashesInSets(sets, sha256s []string, onlyIgnored bool) ([
 	return c.findHashesInSets(sets, sha256s, onlyIgnored)
 }
 
+// Count Hashes saved without quick fingerprint.
+func (c *DbContext) CountHashesWithoutFingerprint() (int64, error) {
+	var count int64
+	result := c.db.Model(&Hash{}).
+		Where("fingerprint IS NULL OR fingerprint = ''").
+		Count(&count)
+	return count, result.Error
+}
+
+// Get distinct fingerprints of keys used to save Hashes. Empty fingerprint means no key was used.
+func (c *DbContext) GetHashKeyFingerprints() ([]string, error) {
+	var fingerprints []string
+	result := c.db.Model(&Hash{}).
+		Distinct().
+		Pluck("COALESCE(key_fingerprint, '')", &fingerprints)
+	return fingerprints, result.Error
+}
+
+// Get Hashes by their quick fingerprints.
+func (c *DbContext) GetHashesByFingerprints(fingerprints []string) ([]*Hash, error) {
+	return c.findHashesByFingerprints(fingerprints)
+}
+
 // Get Hashes by their SHA-256s.
 func (c *DbContext) GetHashesBySha256s(hashes []string) ([]*Hash, error) {
 	return c.findHashesBySha256s(hashes)
@@ -101,18 +128,29 @@ func (c *DbContext) SaveHashes(hashes []*Hash) error {
 		return err
 	}
 	changedHashesMap := map[string]uuid.UUID{}
+	missingFingerprints := map[string]bool{}
 	for _, hash := range changedHashes {
 		changedHashesMap[hash.Sha256] = hash.ID
+		missingFingerprints[hash.Sha256] = hash.Fingerprint == ""
 	}
 	newHashes := []*Hash{}
+	fingerprints := map[string]string{}
 	for _, hash := range hashes {
 		if _, ok := changedHashesMap[hash.Sha256]; ok {
+			// backfill fingerprint of Hashes saved before fingerprint was introduced.
+			if missingFingerprints[hash.Sha256] && hash.Fingerprint != "" {
+				fingerprints[hash.Sha256] = hash.Fingerprint
+			}
 			continue
 		}
 		newHashes = append(newHashes, hash)
 		changedHashesMap[hash.Sha256] = hash.ID
 	}
-	return c.writeHashes(newHashes, []*Hash{})
+	err = c.writeHashes(newHashes, []*Hash{})
+	if err != nil {
+		return err
+	}
+	return c.setFingerprintsBySha256s(fingerprints)
 }
 
 // Return Hash that has specified id.
@@ -163,6 +201,15 @@ func (c *DbContext) findHashesInSets(setNames, sha256s []string, onlyIgnored boo
 	return docs, result.Error
 }
 
+// Return Hashes that have specified fingerprints.
+func (c *DbContext) findHashesByFingerprints(fingerprints []string) ([]*Hash, error) {
+	var docs []*Hash
+	result := c.db.Model(&Hash{}).
+		Where("fingerprint IN ?", fingerprints).
+		Find(&docs)
+	return docs, result.Error
+}
+
 // Return Hashes that have specified SHA-256s.
 func (c *DbContext) findHashesBySha256s(hashes []string) ([]*Hash, error) {
 	var docs []*Hash
@@ -193,14 +240,16 @@ func (c *DbContext) writeHashes(newHashes []*Hash, changedHashes []*Hash) error
 		result := tx.Model(&Hash{}).
 			Where("id = ?", hash.ID).
 			Updates(map[string]interface{}{
-				"md5":         hash.Md5,
-				"sha1":        hash.Sha1,
-				"sha256":      hash.Sha256,
-				"sha512":      hash.Sha512,
-				"size":        hash.Size,
-				"description": hash.Description,
-				"is_ignored":  hash.IsIgnored,
-				"session_id":  hash.SessionID,
+				"md5":             hash.Md5,
+				"sha1":            hash.Sha1,
+				"sha256":          hash.Sha256,
+				"sha512":          hash.Sha512,
+				"fingerprint":     hash.Fingerprint,
+				"key_fingerprint": hash.KeyFingerprint,
+				"size":            hash.Size,
+				"description":     hash.Description,
+				"is_ignored":      hash.IsIgnored,
+				"session_id":      hash.SessionID,
 			})
 		if result.Error != nil {
 			tx.Rollback()
@@ -240,3 +289,22 @@ func (c *DbContext) setIgnoredBySha256s(hashesToIgnore, hashesToApprove []string
 	}
 	return nil
 }
+
+// Update fingerprint of Hashes identified by their SHA-256s, fingerprints is a map of SHA-256 to fingerprint.
+func (c *DbContext) setFingerprintsBySha256s(fingerprints map[string]string) error {
+	if len(fingerprints) == 0 {
+		return nil
+	}
+	tx := c.db.Begin()
+	for sha256, fingerprint := range fingerprints {
+		result := tx.Model(&Hash{}).
+			Where("sha256 = ?", sha256).
+			Update("fingerprint", fingerprint)
+		if result.Error != nil {
+			tx.Rollback()
+			return result.Error
+		}
+	}
+	tx.Commit()
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// HashCheckpoint stores the state of hashing a large file which has not been finished, so it can be
+// resumed later. Like CachedHash, it is only valid while the file has the same size and modification time.
+type HashCheckpoint struct {
+	Device     int64  `gorm:"column:device;primaryKey;autoIncrement:false"`
+	Inode      int64  `gorm:"column:inode;primaryKey;autoIncrement:false"`
+	Size       int64  `gorm:"column:size"`
+	ModTime    int64  `gorm:"column:mod_time"`
+	Algorithms string `gorm:"column:algorithms"`
+	Offset     int64  `gorm:"column:offset"`
+	States     string `gorm:"column:states"`
+}
+
+// Return new HashCheckpoint. States are marshaled states of algorithms, in the same order.
+func NewHashCheckpoint(device, inode uint64, size int64, modTime time.Time, algorithms []string, offset int64, states [][]byte) *HashCheckpoint {
+	encoded, _ := json.Marshal(states)
+	return &HashCheckpoint{
+		Device:     int64(device),
+		Inode:      int64(inode),
+		Size:       size,
+		ModTime:    modTime.UnixNano(),
+		Algorithms: strings.Join(algorithms, ","),
+		Offset:     offset,
+		States:     string(encoded),
+	}
+}
+
+// Return algorithms of the checkpoint.
+func (c *HashCheckpoint) GetAlgorithms() []string {
+	return strings.Split(c.Algorithms, ",")
+}
+
+// Return marshaled states of algorithms.
+func (c *HashCheckpoint) GetStates() ([][]byte, error) {
+	var states [][]byte
+	err := json.Unmarshal([]byte(c.States), &states)
+	return states, err
+}
+
+// Get HashCheckpoint of a file, or nil if there is none or the file has been changed.
+func (c *DbContext) GetHashCheckpoint(device, inode uint64, size int64, modTime time.Time) (*HashCheckpoint, error) {
+	return c.findHashCheckpoint(int64(device), int64(inode), size, modTime.UnixNano())
+}
+
+// Save HashCheckpoint to database. Existing checkpoint of the same file will be replaced.
+func (c *DbContext) SaveHashCheckpoint(checkpoint *HashCheckpoint) error {
+	result := c.db.Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(checkpoint)
+	return result.Error
+}
+
+// Delete HashCheckpoint of a file.
+func (c *DbContext) DeleteHashCheckpoint(device, inode uint64) error {
+	result := c.db.Where("device = ? AND inode = ?", int64(device), int64(inode)).
+		Delete(&HashCheckpoint{})
+	return result.Error
+}
+
+// Return HashCheckpoint of a file that has specified size and modification time.
+func (c *DbContext) findHashCheckpoint(device, inode, size, modTime int64) (*HashCheckpoint, error) {
+	var docs []*HashCheckpoint
+	result := c.db.Model(&HashCheckpoint{}).
+		Where("device = ? AND inode = ? AND size = ? AND mod_time = ?", device, inode, size, modTime).
+		Limit(1).
+		Find(&docs)
+	if result.Error != nil || len(docs) == 0 {
+		return nil, result.Error
+	}
+	return docs[0], nil
+}
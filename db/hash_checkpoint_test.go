@@ -0,0 +1,86 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHashCheckpoint(t *testing.T) {
+	ctx, err := ConnectCache(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	states := [][]byte{{0x01, 0x02}, {0xff}}
+	err = ctx.SaveHashCheckpoint(NewHashCheckpoint(1, 2, 100, modTime, []string{"md5", "sha1"}, 10, states))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// newer checkpoint replaces the old one.
+	err = ctx.SaveHashCheckpoint(NewHashCheckpoint(1, 2, 100, modTime, []string{"md5", "sha1"}, 20, states))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		size    int64
+		modTime time.Time
+		found   bool
+	}{
+		{"unchanged", 100, modTime, true},
+		{"size changed", 101, modTime, false},
+		{"mtime changed", 100, modTime.Add(time.Second), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp, err := ctx.GetHashCheckpoint(1, 2, tt.size, tt.modTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (cp != nil) != tt.found {
+				t.Fatalf("Wrong checkpoint lookup. Expected %t. Actual %t.", tt.found, cp != nil)
+			}
+			if cp == nil {
+				return
+			}
+			if cp.Offset != 20 {
+				t.Errorf("Wrong offset. Expected %d. Actual %d.", 20, cp.Offset)
+			}
+			if algos := cp.GetAlgorithms(); !reflect.DeepEqual(algos, []string{"md5", "sha1"}) {
+				t.Errorf("Wrong algorithms. Actual %v.", algos)
+			}
+			if actual, err := cp.GetStates(); err != nil || !reflect.DeepEqual(actual, states) {
+				t.Errorf("Wrong states. Expected %v. Actual %v.", states, actual)
+			}
+		})
+	}
+
+	t.Run("delete", func(t *testing.T) {
+		if err := ctx.DeleteHashCheckpoint(1, 2); err != nil {
+			t.Fatal(err)
+		}
+		cp, _ := ctx.GetHashCheckpoint(1, 2, 100, modTime)
+		if cp != nil {
+			t.Errorf("Checkpoint should be deleted.")
+		}
+	})
+}
@@ -17,6 +17,8 @@
 package db
 
 import (
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -79,6 +81,19 @@ func TestSaveHashes(t *testing.T) {
 			t.Errorf("hash2 mismatch. expected %v actual %v", alteredHash.Hash(), hash2)
 		}
 	})
+	t.Run("backfill_fingerprint", func(t *testing.T) {
+		ctx := getAndReseedHashDB("SaveHashes")
+		hash := execs[1].Hash()
+		hash.Fingerprint = "5c73e7e3dcbb0395e841f27363849a18"
+		ctx.SaveHashes([]*Hash{hash})
+		hashes, err := ctx.GetHashesByFingerprints([]string{hash.Fingerprint})
+		if err != nil {
+			t.Error(err)
+		}
+		if len(hashes) != 1 || hashes[0].ID != execs[1].ID {
+			t.Errorf("fingerprint mismatch. expected %v actual %v", execs[1].ID, hashes)
+		}
+	})
 }
 
 type testingHash struct {
@@ -159,3 +174,35 @@ func getAndReseedHashDB(function string) *DbContext {
 	tx.Commit()
 	return ctx
 }
+
+func TestGetHashKeyFingerprints(t *testing.T) {
+	execs, _ := testingHashData()
+	tests := []struct {
+		group    string
+		keys     []string
+		expected []string
+	}{
+		{"no_key", []string{"", ""}, []string{""}},
+		{"same_key", []string{"5c73e7e3dcbb0395", "5c73e7e3dcbb0395"}, []string{"", "5c73e7e3dcbb0395"}},
+		{"mixed_keys", []string{"5c73e7e3dcbb0395", "a1302556af3137c8"}, []string{"", "5c73e7e3dcbb0395", "a1302556af3137c8"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.group, func(t *testing.T) {
+			ctx := getAndReseedHashDB("GetHashKeyFingerprints")
+			hashes := make([]*Hash, len(tt.keys))
+			for i, key := range tt.keys {
+				hashes[i] = execs[5+i].Hash()
+				hashes[i].KeyFingerprint = key
+			}
+			ctx.SaveHashes(hashes)
+			fingerprints, err := ctx.GetHashKeyFingerprints()
+			if err != nil {
+				t.Error(err)
+			}
+			sort.Strings(fingerprints)
+			if strings.Join(fingerprints, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("wrong key fingerprints. expected %v actual %v", tt.expected, fingerprints)
+			}
+		})
+	}
+}
@@ -24,6 +24,9 @@ type Set struct {
 	ID   uuid.UUID `gorm:"column:id;primaryKey"`
 	Name string    `gorm:"column:name;uniqueIndex"`
 
+	// Fingerprint of the key used for keyed digests of the set, empty if digests are not keyed.
+	KeyFingerprint string `gorm:"column:key_fingerprint"`
+
 	SessionID uuid.UUID `gorm:"session_id"`
 }
 
@@ -0,0 +1,202 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+	"github.com/tforceaio/tf-unifiler-go/parser/hashdeep"
+)
+
+// Struct checksumAuditResult counts files by their audit statuses.
+type checksumAuditResult struct {
+	Matched int
+	Moved   int
+	New     int
+	Missing int
+}
+
+// Struct auditEntry is a file listed in a hashdeep manifest, which is seen once a file
+// having the same size and hashes is found.
+type auditEntry struct {
+	item *hashdeep.Item
+	path string
+	seen bool
+}
+
+// Audit files of inputs against a hashdeep manifest the same way as hashdeep audit mode.
+// A file is matched if the manifest lists it with the same size and hashes, moved if they are
+// listed under another path, otherwise it is new. Listed files which are not found are missing.
+// The known file, other checksum files and Unifiler workspace data are not audited.
+// Hash cache of workspaceDir is used if it is set.
+func (m *ChecksumModule) Audit(inputs []string, knownFile, workspaceDir string, opts *HashOptions) error {
+	if len(inputs) == 0 {
+		return errors.New("inputs is empty")
+	}
+	if knownFile == "" {
+		return errors.New("known file is not specified")
+	}
+	m.logger.Info().
+		Strs("files", inputs).
+		Str("known", knownFile).
+		Str("workspace", workspaceDir).
+		Msg("Start auditing files.")
+
+	fHandle, err := os.Open(knownFile)
+	if err != nil {
+		return err
+	}
+	columns, items, err := hashdeep.NewParser(fHandle).Parse()
+	fHandle.Close()
+	if err != nil {
+		return err
+	}
+	algorithms, err := hasher.Normalize(columns)
+	if err != nil {
+		return err
+	}
+	m.logger.Info().
+		Strs("algos", algorithms).
+		Int("count", len(items)).
+		Str("path", knownFile).
+		Msg("Parsed known file.")
+
+	contents, err := filesystem.List(inputs, true)
+	if err != nil {
+		return err
+	}
+	knownKey := comparablePath(knownFile)
+	fPaths := []string{}
+	for _, c := range contents {
+		if c.IsDir || isChecksumArtifact(c.Name) || isWorkspacePath(c.RelativePath) || comparablePath(c.RelativePath) == knownKey {
+			continue
+		}
+		fPaths = append(fPaths, c.RelativePath)
+	}
+	result, err := m.auditFiles(fPaths, algorithms, items, filepath.Dir(knownFile), workspaceDir, opts)
+	if err != nil {
+		return err
+	}
+
+	total := result.Matched + result.Moved + result.New
+	m.logger.Info().
+		Int("matched", result.Matched).
+		Int("missing", result.Missing).
+		Int("moved", result.Moved).
+		Int("new", result.New).
+		Int("total", total).
+		Msgf("Audited %d file(s). %d matched, %d moved, %d new, %d known file(s) not found.", total, result.Matched, result.Moved, result.New, result.Missing)
+	if result.Moved > 0 || result.New > 0 || result.Missing > 0 {
+		return errors.New("audit failed")
+	}
+	m.logger.Info().Msg("Audit passed.")
+	return nil
+}
+
+// Hash files using algorithms, then compare them with known items whose hashes are ordered as algorithms.
+// Relative paths of known items are resolved against baseDir.
+func (m *ChecksumModule) auditFiles(fPaths, algorithms []string, items []*hashdeep.Item, baseDir, workspaceDir string, opts *HashOptions) (*checksumAuditResult, error) {
+	entries := []*auditEntry{}
+	known := map[string][]*auditEntry{}
+	for _, item := range items {
+		entry := &auditEntry{
+			item: item,
+			path: comparablePath(m.resolvePath(item.Path, baseDir)),
+		}
+		entries = append(entries, entry)
+		key := auditKey(item.Size, item.Hashes)
+		known[key] = append(known[key], entry)
+	}
+
+	result := &checksumAuditResult{}
+	err := hashFiles(m.logger, fPaths, algorithms, workspaceDir, opts, func(i int, fhResults []*hasher.HashResult, err error) error {
+		if err != nil {
+			m.logger.Info().
+				Str("path", fPaths[i]).
+				Msg("Failed to compute hash.")
+			return err
+		}
+		hashes := make([]string, len(fhResults))
+		for j, r := range fhResults {
+			hashes[j] = hex.EncodeToString(r.Hash)
+		}
+		candidates := known[auditKey(fhResults[0].Size, hashes)]
+		if len(candidates) == 0 {
+			result.New++
+			m.logger.Warn().
+				Str("path", fPaths[i]).
+				Int64("size", fhResults[0].Size).
+				Str("status", "NEW").
+				Msg("File is not in known file.")
+			return nil
+		}
+		fPath := comparablePath(fPaths[i])
+		for _, entry := range candidates {
+			if entry.path == fPath {
+				entry.seen = true
+				result.Matched++
+				m.logger.Info().
+					Str("path", fPaths[i]).
+					Str("status", "MATCHED").
+					Msg("Matched file.")
+				return nil
+			}
+		}
+		// prefer entries which are not seen yet, so each of them accounts for a single moved file.
+		entry := candidates[0]
+		for _, e := range candidates {
+			if !e.seen {
+				entry = e
+				break
+			}
+		}
+		entry.seen = true
+		result.Moved++
+		m.logger.Warn().
+			Str("known", entry.item.Path).
+			Str("path", fPaths[i]).
+			Str("status", "MOVED").
+			Msg("File is moved.")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.seen {
+			result.Missing++
+			m.logger.Warn().
+				Str("path", entry.item.Path).
+				Int64("size", entry.item.Size).
+				Str("status", "MISSING").
+				Msg("Known file not found.")
+		}
+	}
+	return result, nil
+}
+
+// Return key identifying content of a file by its size and hashes.
+func auditKey(size int64, hashes []string) string {
+	return strconv.FormatInt(size, 10) + "," + strings.ToLower(strings.Join(hashes, ","))
+}
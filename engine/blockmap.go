@@ -0,0 +1,200 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+)
+
+// Extension of block map manifest files.
+const BlockMapExt = ".blockmap"
+
+// Struct BlockMapManifest is the content of a block map manifest, which stores hashes of
+// fixed-size blocks of files so corrupted byte ranges can be located during verification.
+type BlockMapManifest struct {
+	Algorithm string          `json:"algorithm"`
+	BlockSize int64           `json:"blockSize"`
+	Files     []*BlockMapFile `json:"files"`
+}
+
+// Struct BlockMapFile contains hex encoded hashes of blocks of a file.
+type BlockMapFile struct {
+	Path   string   `json:"path"`
+	Size   int64    `json:"size"`
+	Blocks []string `json:"blocks"`
+}
+
+// Return new empty BlockMapManifest.
+func NewBlockMapManifest(blockSize int64) *BlockMapManifest {
+	return &BlockMapManifest{
+		Algorithm: hasher.BlockHashAlgorithm,
+		BlockSize: blockSize,
+		Files:     []*BlockMapFile{},
+	}
+}
+
+// Add block map of a file to the manifest.
+func (m *BlockMapManifest) Add(fPath string, blockMap *hasher.BlockMap) {
+	file := &BlockMapFile{
+		Path:   fPath,
+		Size:   blockMap.Size,
+		Blocks: make([]string, len(blockMap.Hashes)),
+	}
+	for i, h := range blockMap.Hashes {
+		file.Blocks[i] = hex.EncodeToString(h)
+	}
+	m.Files = append(m.Files, file)
+}
+
+// Return block map of a file in the manifest, or nil if it is not found.
+func (m *BlockMapManifest) Find(fPath string) *hasher.BlockMap {
+	for _, f := range m.Files {
+		if f.Path == fPath {
+			return m.blockMap(f)
+		}
+	}
+	return nil
+}
+
+func (m *BlockMapManifest) blockMap(f *BlockMapFile) *hasher.BlockMap {
+	blockMap := &hasher.BlockMap{
+		Algorithm: m.Algorithm,
+		BlockSize: m.BlockSize,
+		Size:      f.Size,
+		Hashes:    make([][]byte, len(f.Blocks)),
+	}
+	for i, b := range f.Blocks {
+		hash, err := hex.DecodeString(b)
+		if err != nil {
+			return nil
+		}
+		blockMap.Hashes[i] = hash
+	}
+	return blockMap
+}
+
+// Write the manifest to a file.
+func (m *BlockMapManifest) Write(fPath string) error {
+	fContent, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fPath, fContent, 0664)
+}
+
+// Read block map manifest from a file. Manifests created using other block algorithms are rejected.
+func ReadBlockMapManifest(fPath string) (*BlockMapManifest, error) {
+	fContent, err := os.ReadFile(fPath)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &BlockMapManifest{}
+	if err := json.Unmarshal(fContent, manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Algorithm != hasher.BlockHashAlgorithm || manifest.BlockSize <= 0 {
+		return nil, fmt.Errorf("unsupported block map using '%s' with block size %d", manifest.Algorithm, manifest.BlockSize)
+	}
+	return manifest, nil
+}
+
+// Return path of block map manifest created along with a checksum file. All checksum
+// files having the same name share a manifest, e.g. checksum.sha1 uses checksum.blockmap.
+func BlockMapManifestPath(checksumFile string) string {
+	return strings.TrimSuffix(checksumFile, filepath.Ext(checksumFile)) + BlockMapExt
+}
+
+// Return path of block map manifest of a file identified by its SHA-256 in a workspace.
+func WorkspaceBlockMapPath(workspaceDir, sha256 string) string {
+	return filepath.Join(workspaceDir, ".unifiler", "blockmaps", strings.ToLower(sha256)+BlockMapExt)
+}
+
+// Return block map of a file identified by its SHA-256 in a workspace, or nil if there is none.
+func ReadWorkspaceBlockMap(workspaceDir, sha256 string) *hasher.BlockMap {
+	fPath := WorkspaceBlockMapPath(workspaceDir, sha256)
+	if !filesystem.IsFileExist(fPath) {
+		return nil
+	}
+	manifest, err := ReadBlockMapManifest(fPath)
+	if err != nil || len(manifest.Files) == 0 {
+		return nil
+	}
+	return manifest.blockMap(manifest.Files[0])
+}
+
+// Write block map of a file identified by its SHA-256 to a workspace.
+func WriteWorkspaceBlockMap(workspaceDir, sha256, fPath string, blockMap *hasher.BlockMap) error {
+	oPath := WorkspaceBlockMapPath(workspaceDir, sha256)
+	if err := filesystem.CreateDirectoryRecursive(filepath.Dir(oPath)); err != nil {
+		return err
+	}
+	manifest := NewBlockMapManifest(blockMap.BlockSize)
+	manifest.Add(fPath, blockMap)
+	return manifest.Write(oPath)
+}
+
+// Struct blockMapLookup finds block maps of files being verified. The manifest is only read
+// when it is needed for the first time.
+type blockMapLookup struct {
+	manifestPath string
+	workspaceDir string
+	manifest     *BlockMapManifest
+	loaded       bool
+}
+
+// Return block map of a file from the manifest, or from the workspace if the file is identified
+// by its SHA-256. Return nil if there is none.
+func (l *blockMapLookup) find(fPath, algorithm, hash string) *hasher.BlockMap {
+	if !l.loaded {
+		l.loaded = true
+		if filesystem.IsFileExist(l.manifestPath) {
+			l.manifest, _ = ReadBlockMapManifest(l.manifestPath)
+		}
+	}
+	if l.manifest != nil {
+		if blockMap := l.manifest.Find(fPath); blockMap != nil {
+			return blockMap
+		}
+	}
+	if l.workspaceDir != "" && algorithm == "sha256" {
+		return ReadWorkspaceBlockMap(l.workspaceDir, hash)
+	}
+	return nil
+}
+
+// Return ranges in a human readable list, e.g. 0-4194303.
+func formatByteRanges(ranges []*hasher.ByteRange) []string {
+	result := make([]string, len(ranges))
+	for i, r := range ranges {
+		result[i] = r.String()
+	}
+	return result
+}
+
+// Define block size flag for commands that can create block maps.
+func addBlockMapFlag(cmd *cobra.Command) {
+	cmd.Flags().Int64("block-size", 0, "Record hashes of blocks of this size in KiB (e.g. 4096) to a block map, so verification can report corrupted byte ranges. Use 0 to disable.")
+}
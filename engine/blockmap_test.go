@@ -0,0 +1,79 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+)
+
+func TestBlockMapManifestPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"sha1", "checksum.sha1", "checksum.blockmap"},
+		{"nested", "dir/files.sha256", "dir/files.blockmap"},
+		{"no extension", "checksum", "checksum.blockmap"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := BlockMapManifestPath(tt.path)
+			if actual != tt.expected {
+				t.Errorf("wrong path. expected '%s' actual '%s'", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWorkspaceBlockMap(t *testing.T) {
+	dir := t.TempDir()
+	fPath := filepath.Join(dir, "data.bin")
+	os.WriteFile(fPath, []byte("0123456789abcdef"), 0664)
+	results, err := hasher.HashWithBlocks(fPath, []string{"sha256"}, 4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockMap, err := hasher.NewBlockMap(results[1], 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sha256 := "9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08"
+	if err := WriteWorkspaceBlockMap(dir, sha256, fPath, blockMap); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(fPath, []byte("0123456X89abcdef"), 0664)
+	actual := ReadWorkspaceBlockMap(dir, sha256)
+	if actual == nil {
+		t.Fatal("block map is not found")
+	}
+	ranges, err := actual.Compare(fPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 1 || ranges[0].String() != "4-7" {
+		t.Errorf("wrong ranges. expected %v actual %v", []string{"4-7"}, formatByteRanges(ranges))
+	}
+	if ReadWorkspaceBlockMap(dir, "00") != nil {
+		t.Error("unexpected block map")
+	}
+}
@@ -20,16 +20,27 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 	"github.com/tforce-io/tf-golib/opx"
+	"github.com/tforce-io/tf-golib/opx/slicext"
 	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
 	"github.com/tforceaio/tf-unifiler-go/filesystem"
+	"github.com/tforceaio/tf-unifiler-go/parser/checksum"
+	"github.com/tforceaio/tf-unifiler-go/parser/dfxml"
+	"github.com/tforceaio/tf-unifiler-go/parser/hashdeep"
+	"github.com/tforceaio/tf-unifiler-go/parser/sfv"
 )
 
+// Formats supported by checksum create.
+var checksumFormats = []string{"gnu", "bsd", "sfv", "hashdeep", "dfxml"}
+
 // ChecksumModule handles user requests related checksum file creation and verification.
 type ChecksumModule struct {
 	logger zerolog.Logger
@@ -43,63 +54,566 @@ func NewChecksumModule(c *Controller, cmdName string) *ChecksumModule {
 }
 
 // Create checksum file(s) for inputs using 1 or many algorithms.
-func (m *ChecksumModule) Create(inputs []string, output string, algorithms []string) error {
-	if len(algorithms) == 0 {
-		return errors.New("hash algorithm is not specified")
+// GNU format writes one file per algorithm, BSD format writes all algorithms into
+// a single file using tagged lines. SFV format always uses CRC32. Hashdeep format writes
+// size and hashes of each file in a single line, DFXML format writes them as file objects.
+// Checksum file(s) are stored in output directory, working directory is used if it is empty.
+// They are named after title, or after inputs if title is empty. Paths inside them are relative
+// to output directory, so they stay valid when it is moved along with the files.
+// Checksum files, their artifacts and Unifiler workspace data are not listed.
+// If block size of opts is set, block maps are written to a manifest along with the checksum file(s).
+// Hash cache of workspaceDir is used if it is set.
+func (m *ChecksumModule) Create(inputs []string, output, title string, algorithms []string, format, workspaceDir string, opts *HashOptions) error {
+	format, algorithms, err := normalizeChecksumFormat(format, algorithms)
+	if err != nil {
+		return err
 	}
-
+	outputDir := opx.Ternary(output == "", ".", output)
+	outputStem, err := checksumFileStem(inputs, outputDir, title)
+	if err != nil {
+		return err
+	}
+	startTime := time.Now().UTC()
 	m.logger.Info().
 		Strs("algos", algorithms).
 		Strs("files", inputs).
-		Str("output", output).
+		Str("format", format).
+		Str("output", outputDir).
+		Str("title", filepath.Base(outputStem)).
+		Str("workspace", workspaceDir).
 		Msg("Start computing hashes.")
 
 	contents, err := filesystem.List(inputs, true)
 	if err != nil {
 		return err
 	}
+	fPaths := []string{}
+	for _, c := range contents {
+		if !c.IsDir && !isChecksumArtifact(c.Name) && !isWorkspacePath(c.RelativePath) {
+			fPaths = append(fPaths, c.RelativePath)
+		}
+	}
+	fResults, err := m.hashChecksumFiles(fPaths, algorithms, workspaceDir, opts)
+	if err != nil {
+		return err
+	}
+	return m.writeChecksumFiles(outputStem, fPaths, fResults, algorithms, format, opts, startTime)
+}
 
-	hResults := []*hasher.HashResult{}
+// Create checksum file(s) in every directory of inputs, listing only files of that directory,
+// or also files of its subdirectories if includeSubdirs is true. Formats are the same as Create.
+// Checksum file(s) are named after title, or after the directory if title is empty.
+// Paths inside them are relative to the directory, so each of them can be verified on its own.
+// Hash cache of workspaceDir is used if it is set.
+func (m *ChecksumModule) CreatePerDirectory(inputs []string, title string, algorithms []string, format string, includeSubdirs bool, workspaceDir string, opts *HashOptions) error {
+	format, algorithms, err := normalizeChecksumFormat(format, algorithms)
+	if err != nil {
+		return err
+	}
+	startTime := time.Now().UTC()
+	m.logger.Info().
+		Strs("algos", algorithms).
+		Strs("files", inputs).
+		Str("format", format).
+		Bool("includeSubdirs", includeSubdirs).
+		Str("title", title).
+		Str("workspace", workspaceDir).
+		Msg("Start computing hashes.")
+
+	contents, err := filesystem.List(inputs, true)
+	if err != nil {
+		return err
+	}
+	dirs := []string{}
+	fPaths := []string{}
 	for _, c := range contents {
+		if isWorkspacePath(c.RelativePath) {
+			continue
+		}
 		if c.IsDir {
+			dirs = append(dirs, c.RelativePath)
+		} else if !isChecksumArtifact(c.Name) {
+			fPaths = append(fPaths, c.RelativePath)
+		}
+	}
+	fResults, err := m.hashChecksumFiles(fPaths, algorithms, workspaceDir, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		dirPath := comparablePath(dir)
+		dirFiles := []string{}
+		for _, fPath := range fPaths {
+			parent := comparablePath(filepath.Dir(fPath))
+			if parent == dirPath || (includeSubdirs && strings.HasPrefix(parent, strings.TrimSuffix(dirPath, "/")+"/")) {
+				dirFiles = append(dirFiles, fPath)
+			}
+		}
+		if len(dirFiles) == 0 {
+			m.logger.Info().
+				Str("path", dir).
+				Msg("Skipped. Directory has no files.")
 			continue
 		}
-		fhResults, err := hasher.Hash(c.RelativePath, algorithms)
+		outputStem, err := checksumFileStem([]string{dir}, dir, title)
+		if err != nil {
+			return err
+		}
+		err = m.writeChecksumFiles(outputStem, dirFiles, fResults, algorithms, format, opts, startTime)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate format and algorithms of checksum files, then return them in their normalized forms.
+func normalizeChecksumFormat(format string, algorithms []string) (string, []string, error) {
+	if len(algorithms) == 0 {
+		return "", nil, errors.New("hash algorithm is not specified")
+	}
+	format = opx.Ternary(format == "", "gnu", format)
+	if !slicext.Contains(checksumFormats, format) {
+		return "", nil, fmt.Errorf("unsupported checksum format: '%s'", format)
+	}
+	if format == "sfv" {
+		algorithms = []string{"crc32"}
+	}
+	algorithms, err := hasher.Normalize(algorithms)
+	if err != nil {
+		return "", nil, err
+	}
+	if format == "hashdeep" {
+		for _, a := range algorithms {
+			if !hashdeep.IsSupported(a) {
+				return "", nil, fmt.Errorf("hash algorithm '%s' is not supported by hashdeep format. Supported algorithms: %s", a, strings.Join(hashdeep.Algorithms, ", "))
+			}
+		}
+	}
+	return format, algorithms, nil
+}
+
+// Compute hashes of files, then return results of each file keyed by its path.
+// Block map is the last result of each file if block size of opts is set.
+func (m *ChecksumModule) hashChecksumFiles(fPaths, algorithms []string, workspaceDir string, opts *HashOptions) (map[string][]*hasher.HashResult, error) {
+	fResults := map[string][]*hasher.HashResult{}
+	err := hashFiles(m.logger, fPaths, algorithms, workspaceDir, opts, func(i int, fhResults []*hasher.HashResult, err error) error {
+		if err != nil {
+			m.logger.Info().
+				Str("file", fPaths[i]).
+				Msg("Failed to compute hash.")
+			return err
+		}
 		m.logger.Info().
 			Strs("algos", algorithms).
-			Str("file", c.RelativePath).
-			Int("size", fhResults[0].Size).
+			Str("file", fPaths[i]).
+			Int64("size", fhResults[0].Size).
 			Msg("Hashed file.")
+		fResults[fPaths[i]] = fhResults
+		return nil
+	})
+	return fResults, err
+}
+
+// Write checksum file(s) of format listing fPaths to outputStem, paths are written relative to
+// the directory containing it. Block maps are written to a manifest if block size of opts is set.
+func (m *ChecksumModule) writeChecksumFiles(outputStem string, fPaths []string, fResults map[string][]*hasher.HashResult, algorithms []string, format string, opts *HashOptions, startTime time.Time) error {
+	outputDir := filepath.Dir(outputStem)
+	hResults := []*hasher.HashResult{}
+	var manifest *BlockMapManifest
+	if opts != nil && opts.BlockSize > 0 {
+		manifest = NewBlockMapManifest(opts.BlockSize)
+	}
+	for _, fPath := range fPaths {
+		relPath, err := relativePath(fPath, outputDir)
 		if err != nil {
 			return err
 		}
-		hResults = append(hResults, fhResults...)
+		fhResults := fResults[fPath]
+		if manifest != nil {
+			blockMap, err := hasher.NewBlockMap(fhResults[len(fhResults)-1], opts.BlockSize)
+			if err != nil {
+				return err
+			}
+			manifest.Add(relPath, blockMap)
+			fhResults = fhResults[:len(fhResults)-1]
+		}
+		for _, r := range fhResults {
+			hResults = append(hResults, &hasher.HashResult{
+				Path:      relPath,
+				Size:      r.Size,
+				Algorithm: r.Algorithm,
+				Hash:      r.Hash,
+			})
+		}
 	}
 
+	err := filesystem.CreateDirectoryRecursive(outputDir)
+	if err != nil {
+		return err
+	}
+	if manifest != nil {
+		if err := m.writeBlockMapManifest(outputStem+BlockMapExt, manifest); err != nil {
+			return err
+		}
+	}
+	if format == "bsd" {
+		fContents := []string{}
+		for _, r := range hResults {
+			item := &checksum.ChecksumItem{
+				Algorithm: r.Algorithm,
+				Hash:      hex.EncodeToString(r.Hash),
+				Path:      r.Path,
+			}
+			fContents = append(fContents, checksum.FormatBsd(item))
+		}
+		return m.writeChecksumFile(outputStem+".sum", fContents)
+	}
+	if format == "sfv" {
+		fContents := []string{sfv.FormatComment("Generated by TF Unifiler v" + version())}
+		for _, r := range hResults {
+			if !sfv.IsSupportedPath(r.Path) {
+				m.logger.Warn().
+					Str("path", r.Path).
+					Msg("Skipped file. Its path cannot be written to SFV file.")
+				continue
+			}
+			item := &checksum.ChecksumItem{
+				Hash: hex.EncodeToString(r.Hash),
+				Path: r.Path,
+			}
+			fContents = append(fContents, sfv.Format(item))
+		}
+		return m.writeChecksumFile(outputStem+".sfv", fContents)
+	}
+	if format == "hashdeep" {
+		// paths are relative to output directory, so it is recorded as the invoking directory.
+		baseDir, _ := filesystem.GetAbsPath(outputDir)
+		fContents := hashdeep.FormatHeader(algorithms)
+		fContents = append(fContents,
+			hashdeep.FormatComment("Invoked from: "+baseDir),
+			hashdeep.FormatComment("$ "+strings.Join(os.Args, " ")),
+			"##",
+		)
+		for i := 0; i < len(hResults); i += len(algorithms) {
+			item := &hashdeep.Item{
+				Size: hResults[i].Size,
+				Path: hResults[i].Path,
+			}
+			for _, r := range hResults[i : i+len(algorithms)] {
+				item.Hashes = append(item.Hashes, hex.EncodeToString(r.Hash))
+			}
+			fContents = append(fContents, hashdeep.Format(item))
+		}
+		return m.writeChecksumFile(outputStem+".hashdeep", fContents)
+	}
+	if format == "dfxml" {
+		doc := dfxml.NewHashList("TF Unifiler", version(), strings.Join(os.Args, " "), startTime.Format(time.RFC3339))
+		for i := 0; i < len(hResults); i += len(algorithms) {
+			hashes := map[string]string{}
+			for _, r := range hResults[i : i+len(algorithms)] {
+				hashes[r.Algorithm] = hex.EncodeToString(r.Hash)
+			}
+			doc.Add(hResults[i].Path, hResults[i].Size, algorithms, hashes)
+		}
+		return m.writeDfxmlFile(outputStem+".dfxml", doc)
+	}
 	for _, a := range algorithms {
 		fContents := []string{}
 		for _, r := range hResults {
 			if a == r.Algorithm {
-				line := fmt.Sprintf("%s *%s", hex.EncodeToString(r.Hash), r.Path)
-				fContents = append(fContents, line)
+				item := &checksum.ChecksumItem{
+					Hash:       hex.EncodeToString(r.Hash),
+					BinaryMode: true,
+					Path:       r.Path,
+				}
+				fContents = append(fContents, checksum.FormatGnu(item))
 			}
 		}
+		err := m.writeChecksumFile(fmt.Sprintf("%s.%s", outputStem, a), fContents)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		outputInternal := opx.Ternary(output == "", "checksum", output)
-		// substitute file extension. for more information: https://go.dev/play/p/0wZcne8ZC8G
-		oPath := fmt.Sprintf("%s.%s", strings.TrimSuffix(outputInternal, filepath.Ext(outputInternal)), a)
-		err := filesystem.WriteLines(oPath, fContents)
+// Verify files listed in checksum file(s) of inputs. Directories will be searched
+// recursively for checksum files, and paths inside each of them are resolved
+// relative to the directory containing that checksum file.
+// Corrupted byte ranges of mismatched files are reported if their block maps are found,
+// either in the manifest along with the checksum file or in workspaceDir by SHA-256.
+// If pubKeyFile is set, each checksum file must have a valid detached signature of that key.
+func (m *ChecksumModule) Verify(inputs []string, workspaceDir, pubKeyFile string) error {
+	if len(inputs) == 0 {
+		return errors.New("inputs is empty")
+	}
+	m.logger.Info().
+		Strs("files", inputs).
+		Str("pubkey", pubKeyFile).
+		Str("workspace", workspaceDir).
+		Msg("Start verifying checksum files.")
+	pubKey, err := readPublicKey(pubKeyFile)
+	if err != nil {
+		return err
+	}
+
+	result := &checksumVerifyResult{}
+	for _, input := range inputs {
+		if !filesystem.IsDirectoryExist(input) {
+			err := m.verifySignature(input, pubKey)
+			if err != nil {
+				return err
+			}
+			err = m.verifyFile(input, filepath.Dir(input), workspaceDir, result)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		contents, err := filesystem.List([]string{input}, true)
 		if err != nil {
 			return err
 		}
+		for _, c := range contents {
+			if c.IsDir {
+				continue
+			}
+			if algo := keyedChecksumFileAlgorithm(c.Name); algo != "" {
+				return fmt.Errorf("checksum file '%s' uses keyed hash algorithm '%s' which requires a key", c.RelativePath, algo)
+			}
+			if !IsChecksumFile(c.Name) {
+				continue
+			}
+			err := m.verifySignature(c.RelativePath, pubKey)
+			if err != nil {
+				return err
+			}
+			err = m.verifyFile(c.RelativePath, path.Dir(c.RelativePath), workspaceDir, result)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	m.logger.Info().
+		Int("failed", result.Failed).
+		Int("missing", result.Missing).
+		Int("ok", result.OK).
+		Int("total", result.OK+result.Failed+result.Missing).
+		Msgf("Verified %d file(s). %d OK, %d FAILED, %d MISSING.", result.OK+result.Failed+result.Missing, result.OK, result.Failed, result.Missing)
+	if result.Failed > 0 || result.Missing > 0 {
+		return errors.New("checksum verification failed")
+	}
+	return nil
+}
+
+// Verify files listed in a single checksum file and accumulate their statuses to result.
+// A checksum file which cannot be parsed is counted as a failure.
+// Relative paths are resolved against baseDir, which is usually the directory containing
+// the checksum file, or working directory if it is empty.
+func (m *ChecksumModule) verifyFile(checksumFile, baseDir, workspaceDir string, result *checksumVerifyResult) error {
+	checksumReader, err := os.Open(checksumFile)
+	if err != nil {
+		return err
+	}
+	defer checksumReader.Close()
+	var items []*checksum.ChecksumItem
+	if strings.EqualFold(filepath.Ext(checksumFile), ".sfv") {
+		items, err = sfv.NewParser(checksumReader).Parse()
+	} else {
+		items, err = checksum.NewParser(checksumReader).Parse()
+	}
+	if err != nil {
+		// other checksum files are still verified, the malformed one counts as a failure.
+		result.Failed++
+		m.logger.Error().
+			Err(err).
+			Str("path", checksumFile).
+			Str("status", "MALFORMED").
+			Msg("Failed to parse checksum file.")
+		return nil
+	}
+	m.logger.Info().
+		Int("count", len(items)).
+		Str("path", checksumFile).
+		Msg("Parsed checksum file.")
+
+	// group algorithms by file so each file is only read once.
+	fileAlgo := ChecksumFileAlgorithm(checksumFile)
+	fPaths := []string{}
+	fAlgos := map[string][]string{}
+	itemAlgos := make([]string, len(items))
+	for i, item := range items {
+		name := opx.Ternary(item.Algorithm == "", fileAlgo, item.Algorithm)
+		if name == "" && keyedChecksumFileAlgorithm(checksumFile) != "" {
+			return fmt.Errorf("checksum file '%s' uses keyed hash algorithm '%s' which requires a key", checksumFile, keyedChecksumFileAlgorithm(checksumFile))
+		}
+		if name == "" {
+			return fmt.Errorf("cannot detect hash algorithm of checksum file '%s'", checksumFile)
+		}
+		algo, ok := hasher.Lookup(name)
+		if !ok && hasher.IsKeyed(name) {
+			return fmt.Errorf("hash algorithm '%s' requires a key", name)
+		}
+		if !ok {
+			return fmt.Errorf("unsupported hash algorithm: '%s'", name)
+		}
+		itemAlgos[i] = algo.Name
+		if _, ok := fAlgos[item.Path]; !ok {
+			fPaths = append(fPaths, item.Path)
+		}
+		if !slicext.Contains(fAlgos[item.Path], algo.Name) {
+			fAlgos[item.Path] = append(fAlgos[item.Path], algo.Name)
+		}
+	}
+
+	digests := map[string]map[string]string{}
+	errs := map[string]error{}
+	for _, p := range fPaths {
+		fPath := m.resolvePath(p, baseDir)
+		if !filesystem.IsFileExist(fPath) {
+			continue
+		}
+		fhResults, err := hasher.Hash(fPath, fAlgos[p])
+		if err != nil {
+			errs[p] = err
+			continue
+		}
+		digests[p] = map[string]string{}
+		for _, r := range fhResults {
+			digests[p][r.Algorithm] = hex.EncodeToString(r.Hash)
+		}
+	}
+
+	blockMaps := &blockMapLookup{
+		manifestPath: BlockMapManifestPath(checksumFile),
+		workspaceDir: workspaceDir,
+	}
+	reported := map[string]bool{}
+	for i, item := range items {
+		fPath := m.resolvePath(item.Path, baseDir)
+		if err, ok := errs[item.Path]; ok {
+			result.Failed++
+			m.logger.Warn().
+				Err(err).
+				Str("algo", itemAlgos[i]).
+				Str("path", fPath).
+				Str("status", "FAILED").
+				Msg("Failed to compute hash.")
+			continue
+		}
+		if _, ok := digests[item.Path]; !ok {
+			result.Missing++
+			m.logger.Warn().
+				Str("algo", itemAlgos[i]).
+				Str("path", fPath).
+				Str("status", "MISSING").
+				Msg("File not found.")
+			continue
+		}
+		actual := digests[item.Path][itemAlgos[i]]
+		if !strings.EqualFold(actual, item.Hash) {
+			result.Failed++
+			m.logger.Warn().
+				Str("actual", actual).
+				Str("algo", itemAlgos[i]).
+				Str("expected", item.Hash).
+				Str("path", fPath).
+				Str("status", "FAILED").
+				Msg("Checksum mismatch.")
+			if !reported[item.Path] {
+				reported[item.Path] = true
+				m.reportCorruption(fPath, blockMaps.find(item.Path, itemAlgos[i], item.Hash))
+			}
+			continue
+		}
+		result.OK++
 		m.logger.Info().
-			Int("lineCount", len(fContents)).
-			Str("path", oPath).
-			Msg("Written checksum file.")
+			Str("algo", itemAlgos[i]).
+			Str("path", fPath).
+			Str("status", "OK").
+			Msg("Verified file.")
 	}
 	return nil
 }
 
+// Log corrupted byte ranges of a file by comparing it with its block map. Nil block map is ignored.
+func (m *ChecksumModule) reportCorruption(fPath string, blockMap *hasher.BlockMap) {
+	if blockMap == nil {
+		return
+	}
+	ranges, err := blockMap.Compare(fPath)
+	if err != nil {
+		m.logger.Warn().Err(err).Str("path", fPath).Msg("Failed to compare block map.")
+		return
+	}
+	corrupted := int64(0)
+	for _, r := range ranges {
+		corrupted += r.End - r.Start
+	}
+	m.logger.Warn().
+		Int64("blockSize", blockMap.BlockSize).
+		Int64("corruptedBytes", corrupted).
+		Str("path", fPath).
+		Strs("ranges", formatByteRanges(ranges)).
+		Msgf("Found %d corrupted byte range(s).", len(ranges))
+}
+
+// Return path of a checksum item relative to working directory.
+func (m *ChecksumModule) resolvePath(fPath, baseDir string) string {
+	if baseDir == "" || filesystem.IsAbsPath(fPath) {
+		return fPath
+	}
+	return filesystem.Join(baseDir, fPath)
+}
+
+// Write block map manifest to file.
+func (m *ChecksumModule) writeBlockMapManifest(oPath string, manifest *BlockMapManifest) error {
+	err := manifest.Write(oPath)
+	if err != nil {
+		return err
+	}
+	m.logger.Info().
+		Int64("blockSize", manifest.BlockSize).
+		Int("fileCount", len(manifest.Files)).
+		Str("path", oPath).
+		Msg("Written block map.")
+	return nil
+}
+
+// Write checksum lines to file.
+func (m *ChecksumModule) writeChecksumFile(oPath string, fContents []string) error {
+	err := filesystem.WriteLines(oPath, fContents)
+	if err != nil {
+		return err
+	}
+	m.logger.Info().
+		Int("lineCount", len(fContents)).
+		Str("path", oPath).
+		Msg("Written checksum file.")
+	return nil
+}
+
+// Write DFXML document to file.
+func (m *ChecksumModule) writeDfxmlFile(oPath string, doc *dfxml.DFXML) error {
+	f, err := os.Create(oPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = doc.Encode(f)
+	if err != nil {
+		return err
+	}
+	m.logger.Info().
+		Int("fileCount", len(doc.FileObjects)).
+		Str("path", oPath).
+		Msg("Written checksum file.")
+	return nil
+}
+
 // Decorator to log error occurred when calling handlers.
 func (m *ChecksumModule) logError(err error) {
 	if err != nil {
@@ -111,8 +625,30 @@ func (m *ChecksumModule) logError(err error) {
 func ChecksumCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:   "checksum",
-		Short: "Create and verify checksum files.",
+		Short: "Create, sign and verify checksum files.",
+	}
+
+	auditCmd := &cobra.Command{
+		Use:   "audit <input>...",
+		Short: "Audit files against a hashdeep file, reporting matched, moved, new and missing files.",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := InitApp()
+			defer c.Close()
+			flags := ParseChecksumFlags(cmd, args)
+			m := NewChecksumModule(c, "audit")
+			err := m.Audit(flags.Inputs, flags.KnownFile, flags.WorkspaceDir, flags.HashOptions)
+			m.logError(err)
+			if err != nil {
+				c.Close()
+				os.Exit(1)
+			}
+		},
 	}
+	auditCmd.Flags().StringArrayP("inputs", "i", []string{}, "Files/Directories to audit.")
+	auditCmd.Flags().StringP("known", "k", "", "Hashdeep file listing known files.")
+	auditCmd.Flags().StringP("workspace", "w", "", "Directory contains Unifiler workspace. If it is set, hashes will be cached in the workspace.")
+	addHashFlags(auditCmd)
+	rootCmd.AddCommand(auditCmd)
 
 	createCmd := &cobra.Command{
 		Use:   "create <input>...",
@@ -122,38 +658,298 @@ func ChecksumCmd() *cobra.Command {
 			defer c.Close()
 			flags := ParseChecksumFlags(cmd, args)
 			m := NewChecksumModule(c, "create")
-			m.logError(m.Create(flags.Inputs, flags.Output, flags.Algorithms))
+			if err := flags.KeyOptions.Apply(); err != nil {
+				m.logError(err)
+				return
+			}
+			if flags.PerDirectory {
+				if flags.Output != "" {
+					m.logError(errors.New("output directory cannot be used with per-directory mode"))
+					return
+				}
+				m.logError(m.CreatePerDirectory(flags.Inputs, flags.OutputName, flags.Algorithms, flags.Format, flags.IncludeSubdirs, flags.WorkspaceDir, flags.HashOptions))
+				return
+			}
+			m.logError(m.Create(flags.Inputs, flags.Output, flags.OutputName, flags.Algorithms, flags.Format, flags.WorkspaceDir, flags.HashOptions))
 		},
 	}
-	createCmd.Flags().StringSliceP("algo", "a", []string{"sha1"}, "Hash algorithms to use, comma-separated list supported. Supported algorithms: md4, md5, ripemd160, sha1, sha224, sha256, sha384, sha512.")
+	createCmd.Flags().StringSliceP("algo", "a", []string{"sha1"}, "Hash algorithms to use, comma-separated list supported. "+hashAlgorithmsUsage())
+	addBlockMapFlag(createCmd)
+	createCmd.Flags().StringP("format", "f", "gnu", "Checksum file format. Supported formats: gnu (one file per algorithm), bsd (tagged lines in a single .sum file), sfv (CRC32 only), hashdeep (size and hashes of each file in a single line, supports "+strings.Join(hashdeep.Algorithms, ", ")+"), dfxml (Digital Forensics XML).")
+	createCmd.Flags().Bool("include-subdirs", false, "In per-directory mode, also list files of subdirectories in checksum file(s) of each directory.")
 	createCmd.Flags().StringArrayP("inputs", "i", []string{}, "Files/Directories to create checksum.")
-	createCmd.Flags().StringP("output", "o", "", "Directory to store the calculated checksum file(s).")
-	createCmd.Flags().StringP("title", "t", "", "Output file name. This will override program smart naming scheme.")
+	createCmd.Flags().StringP("output", "o", "", "Directory to store the calculated checksum file(s). Default to working directory. Paths inside checksum file(s) are relative to it.")
+	createCmd.Flags().Bool("per-directory", false, "Write checksum file(s) into every directory of inputs, listing only files of that directory. Verify them with recursive verify of the root directory.")
+	createCmd.Flags().StringP("title", "t", "", "Output file name without extension. This will override program smart naming scheme, which uses name of the input, or name of the common parent directory of all inputs.")
+	createCmd.Flags().StringP("workspace", "w", "", "Directory contains Unifiler workspace. If it is set, hashes will be cached in the workspace.")
+	addHashFlags(createCmd)
+	addKeyFlags(createCmd)
 	rootCmd.AddCommand(createCmd)
 
+	keygenCmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate Ed25519 key pair in minisign format for signing checksum files.",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := InitApp()
+			defer c.Close()
+			flags := ParseChecksumFlags(cmd, args)
+			m := NewChecksumModule(c, "keygen")
+			password, err := readPassword(flags.PasswordEnv)
+			if err != nil {
+				m.logError(err)
+				return
+			}
+			output := opx.Ternary(flags.Output == "", SigningKeyDir(c.Root.ConfigDir), flags.Output)
+			m.logError(m.Keygen(output, password, flags.Force))
+		},
+	}
+	keygenCmd.Flags().Bool("force", false, "Replace existing key pair.")
+	keygenCmd.Flags().StringP("output", "o", "", "Directory to store the key pair. Default to keys directory inside config directory.")
+	keygenCmd.Flags().String("password-env", "", "Name of environment variable containing password to encrypt the secret key. Secret key is not encrypted if it is not set.")
+	rootCmd.AddCommand(keygenCmd)
+
+	signCmd := &cobra.Command{
+		Use:   "sign <input>...",
+		Short: "Create detached signatures (.sig) of checksum files. Directories will be searched for checksum files.",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := InitApp()
+			defer c.Close()
+			flags := ParseChecksumFlags(cmd, args)
+			m := NewChecksumModule(c, "sign")
+			password, err := readPassword(flags.PasswordEnv)
+			if err != nil {
+				m.logError(err)
+				return
+			}
+			if flags.SecKeyFile == "" {
+				_, flags.SecKeyFile = SigningKeyPaths(SigningKeyDir(c.Root.ConfigDir))
+			}
+			m.logError(m.Sign(flags.Inputs, flags.SecKeyFile, password, flags.Comment))
+		},
+	}
+	signCmd.Flags().StringP("comment", "c", "", "Trusted comment signed along with checksum files. Default to timestamp and file name.")
+	signCmd.Flags().StringArrayP("inputs", "i", []string{}, "Checksum files or directories containing them.")
+	signCmd.Flags().String("password-env", "", "Name of environment variable containing password of the secret key if it is encrypted.")
+	signCmd.Flags().StringP("seckey", "s", "", "Secret key file. Default to the key generated by keygen inside config directory.")
+	rootCmd.AddCommand(signCmd)
+
+	updateCmd := &cobra.Command{
+		Use:   "update <input>...",
+		Short: "Update checksum file(s) with files added to or removed from their directories.",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := InitApp()
+			defer c.Close()
+			flags := ParseChecksumFlags(cmd, args)
+			m := NewChecksumModule(c, "update")
+			if err := flags.KeyOptions.Apply(); err != nil {
+				m.logError(err)
+				return
+			}
+			m.logError(m.Update(flags.Inputs, flags.KeepMissing, flags.Refresh, flags.WorkspaceDir, flags.HashOptions))
+		},
+	}
+	updateCmd.Flags().StringArrayP("inputs", "i", []string{}, "Checksum files to update. Files inside the directory containing each of them will be listed.")
+	updateCmd.Flags().Bool("keep-missing", false, "Keep entries of files which are not found instead of removing them.")
+	updateCmd.Flags().Bool("refresh", false, "Re-hash files whose size or modification time changed since they were hashed instead of only reporting them. Their states are stored in a .state file along with the checksum file.")
+	updateCmd.Flags().StringP("workspace", "w", "", "Directory contains Unifiler workspace. If it is set, hashes will be cached in the workspace.")
+	addHashFlags(updateCmd)
+	addKeyFlags(updateCmd)
+	rootCmd.AddCommand(updateCmd)
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify <input>...",
+		Short: "Verify files against checksum file(s). Directories will be searched for checksum files.",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := InitApp()
+			defer c.Close()
+			flags := ParseChecksumFlags(cmd, args)
+			m := NewChecksumModule(c, "verify")
+			err := flags.KeyOptions.Apply()
+			if err == nil {
+				err = m.Verify(flags.Inputs, flags.WorkspaceDir, flags.PubKeyFile)
+			}
+			m.logError(err)
+			if err != nil {
+				c.Close()
+				os.Exit(1)
+			}
+		},
+	}
+	verifyCmd.Flags().StringArrayP("inputs", "i", []string{}, "Checksum files or directories containing them.")
+	verifyCmd.Flags().StringP("pubkey", "p", "", "Public key file. If it is set, checksum files must have valid detached signatures (.sig) created by its secret key.")
+	verifyCmd.Flags().StringP("workspace", "w", "", "Directory contains Unifiler workspace. If it is set, block maps recorded by metadata scan are used to locate corrupted byte ranges.")
+	addKeyFlags(verifyCmd)
+	rootCmd.AddCommand(verifyCmd)
+
 	return rootCmd
 }
 
+// Return path to checksum file(s) inside outputDir without extension. If title is empty,
+// name of the input is used if there is only one, otherwise name of the common parent directory
+// of all inputs, or "checksum" if they don't share one.
+func checksumFileStem(inputs []string, outputDir, title string) (string, error) {
+	if title != "" {
+		ext := filepath.Ext(title)
+		if IsChecksumFile(title) || strings.EqualFold(ext, ".hashdeep") || strings.EqualFold(ext, ".dfxml") {
+			title = strings.TrimSuffix(title, ext)
+		}
+		return filepath.Join(outputDir, title), nil
+	}
+	absPaths := make([]string, len(inputs))
+	for i, input := range inputs {
+		absPath, err := filesystem.GetAbsPath(input)
+		if err != nil {
+			return "", err
+		}
+		absPaths[i] = filepath.Clean(absPath)
+	}
+	var name string
+	if len(absPaths) == 1 {
+		name = filepath.Base(absPaths[0])
+	} else if len(absPaths) > 1 {
+		common := filepath.Dir(absPaths[0])
+		for _, p := range absPaths[1:] {
+			for !isSubPath(p, common) {
+				common = filepath.Dir(common)
+			}
+		}
+		name = filepath.Base(common)
+	}
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		name = "checksum"
+	}
+	return filepath.Join(outputDir, name), nil
+}
+
+// Return path of fPath relative to baseDir using forward slashes.
+func relativePath(fPath, baseDir string) (string, error) {
+	absPath, err := filesystem.GetAbsPath(fPath)
+	if err != nil {
+		return "", err
+	}
+	absBase, err := filesystem.GetAbsPath(baseDir)
+	if err != nil {
+		return "", err
+	}
+	relPath, err := filepath.Rel(absBase, absPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(relPath), nil
+}
+
+// Return absolute path used to compare paths of files listed in different places.
+func comparablePath(fPath string) string {
+	absPath, err := filesystem.GetAbsPath(fPath)
+	if err != nil {
+		return filesystem.NormalizePath(fPath)
+	}
+	return filesystem.NormalizePath(absPath)
+}
+
+// Check whether fPath is dir or inside it. Both paths must be absolute and clean.
+func isSubPath(fPath, dir string) bool {
+	if fPath == dir || dir == filepath.Dir(dir) {
+		return true
+	}
+	return strings.HasPrefix(fPath, dir+string(filepath.Separator))
+}
+
+// Return hash algorithm of a checksum file derived from its extension,
+// or empty string if the extension is not a supported algorithm.
+func ChecksumFileAlgorithm(fPath string) string {
+	ext := strings.TrimPrefix(filepath.Ext(fPath), ".")
+	if algo, ok := hasher.Lookup(ext); ok && ext != "" {
+		return algo.Name
+	}
+	return ""
+}
+
+// Return keyed hash algorithm of a checksum file derived from its extension if no key is set,
+// so it cannot be verified, or empty string otherwise.
+func keyedChecksumFileAlgorithm(fPath string) string {
+	ext := strings.TrimPrefix(filepath.Ext(fPath), ".")
+	if ext == "" || ChecksumFileAlgorithm(fPath) != "" || !hasher.IsKeyed(ext) {
+		return ""
+	}
+	return ext
+}
+
+// Check whether a file is a checksum file that can be verified by its extension.
+// Files with .sum extension contain BSD style lines which have algorithm inline,
+// files with .sfv extension always use CRC32.
+func IsChecksumFile(fPath string) bool {
+	ext := filepath.Ext(fPath)
+	return ChecksumFileAlgorithm(fPath) != "" || strings.EqualFold(ext, ".sum") || strings.EqualFold(ext, ".sfv")
+}
+
+// Struct checksumVerifyResult counts files by their verification statuses.
+type checksumVerifyResult struct {
+	OK      int
+	Failed  int
+	Missing int
+}
+
 // Struct ChecksumFlags contains all flags used by Checksum module.
 type ChecksumFlags struct {
-	Algorithms []string
-	Inputs     []string
-	Output     string
-	OutputName string
+	Algorithms     []string
+	Comment        string
+	Force          bool
+	Format         string
+	HashOptions    *HashOptions
+	IncludeSubdirs bool
+	Inputs         []string
+	KeepMissing    bool
+	KeyOptions     *KeyOptions
+	KnownFile      string
+	Output         string
+	OutputName     string
+	PasswordEnv    string
+	PerDirectory   bool
+	PubKeyFile     string
+	Refresh        bool
+	SecKeyFile     string
+	WorkspaceDir   string
 }
 
 // Extract all flags from a Cobra Command.
 func ParseChecksumFlags(cmd *cobra.Command, args []string) *ChecksumFlags {
 	algorithms, _ := cmd.Flags().GetStringSlice("algo")
+	comment, _ := cmd.Flags().GetString("comment")
+	force, _ := cmd.Flags().GetBool("force")
+	format, _ := cmd.Flags().GetString("format")
+	includeSubdirs, _ := cmd.Flags().GetBool("include-subdirs")
 	inputs, _ := cmd.Flags().GetStringArray("inputs")
+	keepMissing, _ := cmd.Flags().GetBool("keep-missing")
+	knownFile, _ := cmd.Flags().GetString("known")
 	output, _ := cmd.Flags().GetString("output")
 	outputName, _ := cmd.Flags().GetString("title")
+	passwordEnv, _ := cmd.Flags().GetString("password-env")
+	perDirectory, _ := cmd.Flags().GetBool("per-directory")
+	pubKeyFile, _ := cmd.Flags().GetString("pubkey")
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	secKeyFile, _ := cmd.Flags().GetString("seckey")
+	workspaceDir, _ := cmd.Flags().GetString("workspace")
 	inputs = append(args, inputs...)
 
 	return &ChecksumFlags{
-		Algorithms: algorithms,
-		Inputs:     inputs,
-		Output:     output,
-		OutputName: outputName,
+		Algorithms:     algorithms,
+		Comment:        comment,
+		Force:          force,
+		Format:         format,
+		HashOptions:    ParseHashOptions(cmd),
+		IncludeSubdirs: includeSubdirs,
+		Inputs:         inputs,
+		KeepMissing:    keepMissing,
+		KeyOptions:     ParseKeyOptions(cmd),
+		KnownFile:      knownFile,
+		Output:         output,
+		OutputName:     outputName,
+		PasswordEnv:    passwordEnv,
+		PerDirectory:   perDirectory,
+		PubKeyFile:     pubKeyFile,
+		Refresh:        refresh,
+		SecKeyFile:     secKeyFile,
+		WorkspaceDir:   workspaceDir,
 	}
 }
@@ -0,0 +1,443 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+	"github.com/tforceaio/tf-unifiler-go/parser/hashdeep"
+)
+
+func TestChecksumFileAlgorithm(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		algo string
+	}{
+		{"sha1", "checksum.sha1", "sha1"},
+		{"sha256 uppercase", "dir/CHECKSUM.SHA256", "sha256"},
+		{"crc32", "checksum.crc32", "crc32"},
+		{"no extension", "checksum", ""},
+		{"unsupported", "checksum.txt", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algo := ChecksumFileAlgorithm(tt.path)
+			if algo != tt.algo {
+				t.Errorf("wrong algorithm. expected '%s' actual '%s'", tt.algo, algo)
+			}
+		})
+	}
+}
+
+func TestChecksumVerifyFile(t *testing.T) {
+	dir := t.TempDir()
+	filesystem.WriteLines(filepath.Join(dir, "a.txt"), []string{"hello"})
+	filesystem.WriteLines(filepath.Join(dir, "b.txt"), []string{"world"})
+	filesystem.WriteLines(filepath.Join(dir, "checksum.sha1"), []string{
+		"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt",
+		"0000000000000000000000000000000000000000 *b.txt",
+		"f572d396fae9206628714fb2ce00f72e94f2258f *c.txt",
+	})
+
+	module := &ChecksumModule{
+		logger: log.Logger,
+	}
+	result := &checksumVerifyResult{}
+	err := module.verifyFile(filepath.Join(dir, "checksum.sha1"), dir, "", result)
+	if err != nil {
+		t.Error(err)
+	}
+	if result.OK != 1 || result.Failed != 1 || result.Missing != 1 {
+		t.Errorf("wrong verification result. expected %v actual %v", checksumVerifyResult{1, 1, 1}, *result)
+	}
+}
+
+func TestChecksumVerifyMalformedFile(t *testing.T) {
+	dir := t.TempDir()
+	filesystem.WriteLines(filepath.Join(dir, "a.txt"), []string{"hello"})
+	filesystem.WriteLines(filepath.Join(dir, "bad.sfv"), []string{"a.txt", "b.txt 00000000"})
+	filesystem.WriteLines(filepath.Join(dir, "good.sha1"), []string{"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt"})
+
+	module := &ChecksumModule{
+		logger: log.Logger,
+	}
+	result := &checksumVerifyResult{}
+	for _, name := range []string{"bad.sfv", "good.sha1"} {
+		if err := module.verifyFile(filepath.Join(dir, name), dir, "", result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := checksumVerifyResult{OK: 1, Failed: 1}
+	if *result != expected {
+		t.Errorf("wrong verify result. expected %v actual %v", expected, *result)
+	}
+}
+
+func TestChecksumAuditFiles(t *testing.T) {
+	dir := t.TempDir()
+	filesystem.WriteLines(filepath.Join(dir, "a.txt"), []string{"hello"})
+	filesystem.WriteLines(filepath.Join(dir, "moved.txt"), []string{"world"})
+	filesystem.WriteLines(filepath.Join(dir, "new.txt"), []string{"new"})
+	items := []*hashdeep.Item{
+		{Size: 6, Hashes: []string{"f572d396fae9206628714fb2ce00f72e94f2258f"}, Path: filepath.Join(dir, "a.txt")},
+		{Size: 6, Hashes: []string{"9591818c07e900db7e1e0bc4b884c945e6a61b24"}, Path: filepath.Join(dir, "b.txt")},
+		{Size: 6, Hashes: []string{"0000000000000000000000000000000000000000"}, Path: filepath.Join(dir, "c.txt")},
+	}
+	fPaths := []string{
+		filepath.Join(dir, "a.txt"),
+		filepath.Join(dir, "moved.txt"),
+		filepath.Join(dir, "new.txt"),
+	}
+
+	module := &ChecksumModule{
+		logger: log.Logger,
+	}
+	result, err := module.auditFiles(fPaths, []string{"sha1"}, items, dir, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := checksumAuditResult{Matched: 1, Moved: 1, New: 1, Missing: 1}
+	if *result != expected {
+		t.Errorf("wrong audit result. expected %v actual %v", expected, *result)
+	}
+}
+
+func TestChecksumAuditKnownFileInInput(t *testing.T) {
+	dir := t.TempDir()
+	filesystem.WriteLines(filepath.Join(dir, "a.txt"), []string{"hello"})
+	filesystem.WriteLines(filepath.Join(dir, "other.sha1"), []string{"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt"})
+
+	module := &ChecksumModule{
+		logger: log.Logger,
+	}
+	err := module.Create([]string{dir}, dir, "known", []string{"sha1"}, "hashdeep", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = module.Audit([]string{dir}, filepath.Join(dir, "known.hashdeep"), "", nil)
+	if err != nil {
+		t.Errorf("unexpected audit error: %v", err)
+	}
+}
+
+func TestChecksumFileStem(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		inputs   []string
+		title    string
+		expected string
+	}{
+		{"title", []string{filepath.Join(dir, "photos")}, "backup", "backup"},
+		{"title with extension", []string{filepath.Join(dir, "photos")}, "backup.sha1", "backup"},
+		{"title with dot", []string{filepath.Join(dir, "photos")}, "backup-1.2", "backup-1.2"},
+		{"single directory", []string{filepath.Join(dir, "photos") + "/"}, "", "photos"},
+		{"single file", []string{filepath.Join(dir, "photos", "a.jpg")}, "", "a.jpg"},
+		{"common parent", []string{filepath.Join(dir, "photos", "2024"), filepath.Join(dir, "photos", "2025", "a.jpg")}, "", "photos"},
+		{"root", []string{"/a", "/b"}, "", "checksum"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stem, err := checksumFileStem(tt.inputs, "out", tt.title)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := filepath.Join("out", tt.expected)
+			if stem != expected {
+				t.Errorf("wrong stem. expected '%s' actual '%s'", expected, stem)
+			}
+		})
+	}
+}
+
+func TestChecksumCreateIntoInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		output string
+	}{
+		{"gnu", "gnu", "d.sha256"},
+		{"bsd", "bsd", "d.sum"},
+		{"hashdeep", "hashdeep", "d.hashdeep"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "d")
+			os.MkdirAll(filepath.Join(dir, ".unifiler"), 0775)
+			filesystem.WriteLines(filepath.Join(dir, "a.txt"), []string{"hello"})
+			filesystem.WriteLines(filepath.Join(dir, ".unifiler", "cache.db"), []string{"cache"})
+			filesystem.WriteLines(filepath.Join(dir, "old.sha1"), []string{"0000 *a.txt"})
+
+			module := &ChecksumModule{
+				logger: log.Logger,
+			}
+			for i := 0; i < 2; i++ {
+				err := module.Create([]string{dir}, dir, "", []string{"sha256"}, tt.format, "", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			content, err := os.ReadFile(filepath.Join(dir, tt.output))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{tt.output, "old.sha1", "cache.db"} {
+				if strings.Contains(string(content), name) {
+					t.Errorf("unexpected entry of '%s' in %q", name, string(content))
+				}
+			}
+			if !strings.Contains(string(content), "a.txt") {
+				t.Errorf("missing entry of 'a.txt' in %q", string(content))
+			}
+		})
+	}
+}
+
+func TestChecksumCreatePerDirectory(t *testing.T) {
+	tests := []struct {
+		name           string
+		includeSubdirs bool
+		expected       map[string][]string
+	}{
+		{
+			"default", false,
+			map[string][]string{
+				"checksum.sha1":       {"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt"},
+				"sub/checksum.sha1":   {"9591818c07e900db7e1e0bc4b884c945e6a61b24 *b.txt"},
+				"empty/checksum.sha1": nil,
+			},
+		},
+		{
+			"include_subdirs", true,
+			map[string][]string{
+				"checksum.sha1": {
+					"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt",
+					"9591818c07e900db7e1e0bc4b884c945e6a61b24 *sub/b.txt",
+				},
+				"sub/checksum.sha1":   {"9591818c07e900db7e1e0bc4b884c945e6a61b24 *b.txt"},
+				"empty/checksum.sha1": nil,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			os.Mkdir(filepath.Join(dir, "empty"), 0775)
+			os.Mkdir(filepath.Join(dir, "sub"), 0775)
+			filesystem.WriteLines(filepath.Join(dir, "a.txt"), []string{"hello"})
+			filesystem.WriteLines(filepath.Join(dir, "sub", "b.txt"), []string{"world"})
+
+			module := &ChecksumModule{
+				logger: log.Logger,
+			}
+			err := module.CreatePerDirectory([]string{dir}, "checksum", []string{"sha1"}, "gnu", tt.includeSubdirs, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, lines := range tt.expected {
+				content, err := os.ReadFile(filepath.Join(dir, name))
+				if lines == nil {
+					if err == nil {
+						t.Errorf("unexpected checksum file '%s'", name)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected := strings.Join(lines, "\n") + "\n"
+				if string(content) != expected {
+					t.Errorf("wrong content of '%s'. expected %q actual %q", name, expected, string(content))
+				}
+			}
+		})
+	}
+}
+
+func TestChecksumUpdateFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		keepMissing bool
+		refresh     bool
+		expected    []string
+		result      checksumUpdateResult
+	}{
+		{
+			"default", false, false,
+			[]string{
+				"0000000000000000000000000000000000000000 *b.txt",
+				"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt",
+				"9591818c07e900db7e1e0bc4b884c945e6a61b24 *z.txt",
+			},
+			checksumUpdateResult{Added: 2, Changed: 1, Removed: 1, Unchanged: 0},
+		},
+		{
+			"keep_missing_and_refresh", true, true,
+			[]string{
+				"9591818c07e900db7e1e0bc4b884c945e6a61b24 *b.txt",
+				"0000000000000000000000000000000000000000 *c.txt",
+				"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt",
+				"9591818c07e900db7e1e0bc4b884c945e6a61b24 *z.txt",
+			},
+			checksumUpdateResult{Added: 2, Changed: 1, Removed: 0, Unchanged: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			os.Mkdir(filepath.Join(dir, "sub"), 0775)
+			filesystem.WriteLines(filepath.Join(dir, "z.txt"), []string{"world"})
+			filesystem.WriteLines(filepath.Join(dir, "a.txt"), []string{"hello"})
+			filesystem.WriteLines(filepath.Join(dir, "sub", "a.txt"), []string{"hello"})
+			filesystem.WriteLines(filepath.Join(dir, "b.txt"), []string{"world"})
+			checksumFile := filepath.Join(dir, "checksum.sha1")
+			filesystem.WriteLines(checksumFile, []string{
+				"0000000000000000000000000000000000000000 *b.txt",
+				"0000000000000000000000000000000000000000 *c.txt",
+			})
+			past := time.Now().Add(-time.Hour)
+			for _, name := range []string{"z.txt", "a.txt", "sub/a.txt"} {
+				os.Chtimes(filepath.Join(dir, name), past, past)
+			}
+			os.Chtimes(checksumFile, past.Add(time.Minute), past.Add(time.Minute))
+
+			module := &ChecksumModule{
+				logger: log.Logger,
+			}
+			result, err := module.updateFile(checksumFile, tt.keepMissing, tt.refresh, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *result != tt.result {
+				t.Errorf("wrong update result. expected %v actual %v", tt.result, *result)
+			}
+			content, _ := os.ReadFile(checksumFile)
+			expected := strings.Join(tt.expected, "\n") + "\n"
+			if string(content) != expected {
+				t.Errorf("wrong checksum file. expected '%s' actual '%s'", expected, string(content))
+			}
+		})
+	}
+}
+
+func TestChecksumUpdateFileScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []string
+		expected []string
+	}{
+		{
+			"direct files only",
+			[]string{"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt"},
+			[]string{
+				"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt",
+				"9591818c07e900db7e1e0bc4b884c945e6a61b24 *b.txt",
+			},
+		},
+		{
+			"listed subdirectory",
+			[]string{"f572d396fae9206628714fb2ce00f72e94f2258f *own/a.txt"},
+			[]string{
+				"f572d396fae9206628714fb2ce00f72e94f2258f *own/a.txt",
+				"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt",
+				"9591818c07e900db7e1e0bc4b884c945e6a61b24 *b.txt",
+				"9591818c07e900db7e1e0bc4b884c945e6a61b24 *own/b.txt",
+				"f572d396fae9206628714fb2ce00f72e94f2258f *plain/a.txt",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, sub := range []string{"own", "plain", "sep"} {
+				os.Mkdir(filepath.Join(dir, sub), 0775)
+				filesystem.WriteLines(filepath.Join(dir, sub, "a.txt"), []string{"hello"})
+			}
+			filesystem.WriteLines(filepath.Join(dir, "a.txt"), []string{"hello"})
+			filesystem.WriteLines(filepath.Join(dir, "b.txt"), []string{"world"})
+			filesystem.WriteLines(filepath.Join(dir, "own", "b.txt"), []string{"world"})
+			filesystem.WriteLines(filepath.Join(dir, "own", "own.sha1"), []string{"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt"})
+			filesystem.WriteLines(filepath.Join(dir, "sep", "sep.sha1"), []string{"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt"})
+			checksumFile := filepath.Join(dir, "checksum.sha1")
+			filesystem.WriteLines(checksumFile, tt.entries)
+
+			module := &ChecksumModule{
+				logger: log.Logger,
+			}
+			_, err := module.updateFile(checksumFile, false, false, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, _ := os.ReadFile(checksumFile)
+			expected := strings.Join(tt.expected, "\n") + "\n"
+			if string(content) != expected {
+				t.Errorf("wrong checksum file. expected '%s' actual '%s'", expected, string(content))
+			}
+		})
+	}
+}
+
+func TestChecksumUpdateFileRepeated(t *testing.T) {
+	dir := t.TempDir()
+	aPath := filepath.Join(dir, "a.txt")
+	filesystem.WriteLines(aPath, []string{"hello"})
+	checksumFile := filepath.Join(dir, "checksum.sha1")
+	filesystem.WriteLines(checksumFile, []string{"f572d396fae9206628714fb2ce00f72e94f2258f *a.txt"})
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(aPath, past, past)
+	os.Chtimes(checksumFile, past.Add(time.Minute), past.Add(time.Minute))
+	filesystem.WriteLines(filepath.Join(dir, "b.txt"), []string{"world"})
+
+	tests := []struct {
+		name    string
+		prepare func()
+		refresh bool
+		result  checksumUpdateResult
+	}{
+		{"added", func() {}, false, checksumUpdateResult{Added: 1, Unchanged: 1}},
+		{"no changes", func() {}, false, checksumUpdateResult{Unchanged: 2}},
+		{"size changed with same mtime", func() {
+			filesystem.WriteLines(aPath, []string{"hello world"})
+			os.Chtimes(aPath, past, past)
+		}, false, checksumUpdateResult{Changed: 1, Unchanged: 1}},
+		{"changed again until refreshed", func() {}, false, checksumUpdateResult{Changed: 1, Unchanged: 1}},
+		{"refreshed", func() {}, true, checksumUpdateResult{Changed: 1, Unchanged: 1}},
+		{"no changes after refresh", func() {}, false, checksumUpdateResult{Unchanged: 2}},
+	}
+	module := &ChecksumModule{
+		logger: log.Logger,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			result, err := module.updateFile(checksumFile, false, tt.refresh, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *result != tt.result {
+				t.Errorf("wrong update result. expected %v actual %v", tt.result, *result)
+			}
+		})
+	}
+}
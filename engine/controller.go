@@ -17,6 +17,7 @@
 package engine
 
 import (
+	"io"
 	"os"
 
 	"github.com/rs/zerolog"
@@ -34,9 +35,10 @@ type Controller struct {
 
 // Entrypoint for creating new instance of Controller.
 // useFS will instruct this function to read configurations and create log file.
-func NewController(useFS bool) *Controller {
+// Console logs are written to consoleOut.
+func NewController(useFS bool, consoleOut io.Writer) *Controller {
 	cfg, err := config.InitKoanf(useFS)
-	logger, logFile, err2 := config.InitZerolog(cfg.ConfigDir, useFS)
+	logger, logFile, err2 := config.InitZerolog(cfg.ConfigDir, useFS, consoleOut)
 	if err != nil {
 		logger.Err(err).Msg("error initializing config")
 	}
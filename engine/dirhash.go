@@ -0,0 +1,311 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/tforce-io/tf-golib/opx"
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+)
+
+// Struct directoryFingerprint contains Merkle root of a directory and hashes of all files inside it.
+type directoryFingerprint struct {
+	Path  string
+	Hash  string
+	Files map[string]string // path relative to the directory => hash of the file
+	Size  int64
+}
+
+// Compute deterministic hash of each directory in inputs, which is Merkle root over sorted relative paths
+// and hashes of all files inside it, so directories having identical contents share the same hash
+// regardless of their names and locations. SHA-256 is used by default, in fast mode XXH3-128 is used instead.
+// Hash cache of workspaceDir is used if it is set.
+func (m *FileModule) DirectoryHash(inputs []string, fast bool, workspaceDir string, opts *HashOptions) error {
+	if len(inputs) == 0 {
+		return errors.New("inputs is empty")
+	}
+	for _, input := range inputs {
+		if !filesystem.IsDirectoryExist(input) {
+			return fmt.Errorf("'%s' is not a directory", input)
+		}
+	}
+	algo := opx.Ternary(fast, "xxh3-128", "sha256")
+	m.logger.Info().
+		Str("algo", algo).
+		Strs("files", inputs).
+		Str("workspace", workspaceDir).
+		Msg("Start computing directory hashes.")
+
+	fingerprints, err := m.fingerprintDirectories(inputs, algo, workspaceDir, opts)
+	if err != nil {
+		return err
+	}
+	roots := map[string]bool{}
+	for _, input := range inputs {
+		roots[comparablePath(input)] = true
+	}
+	for _, fp := range fingerprints {
+		if !roots[comparablePath(fp.Path)] {
+			continue
+		}
+		m.logger.Info().
+			Str(algo, fp.Hash).
+			Int("files", len(fp.Files)).
+			Str("path", fp.Path).
+			Int64("size", fp.Size).
+			Msg("Hashed directory.")
+	}
+	return nil
+}
+
+// Find directories having identical contents in inputs, and directories whose files are a strict subset
+// of files of another directory, both compared by relative paths and hashes. Directories without files
+// are ignored, and identical directories are not reported if their parents are identical as well.
+// SHA-256 is used by default, in fast mode XXH3-128 is used instead, which is much faster but matches
+// should be treated as candidates only. Hash cache of workspaceDir is used if it is set.
+func (m *FileModule) DuplicateDirectory(inputs []string, fast bool, workspaceDir string, opts *HashOptions) error {
+	if len(inputs) == 0 {
+		return errors.New("inputs is empty")
+	}
+	algo := opx.Ternary(fast, "xxh3-128", "sha256")
+	m.logger.Info().
+		Str("algo", algo).
+		Strs("files", inputs).
+		Str("workspace", workspaceDir).
+		Msg("Start finding duplicated directories.")
+
+	fingerprints, err := m.fingerprintDirectories(inputs, algo, workspaceDir, opts)
+	if err != nil {
+		return err
+	}
+	duplicates, subsets := compareDirectories(fingerprints)
+
+	dirCount := 0
+	for _, group := range duplicates {
+		dirCount += len(group)
+		dirs := make([]string, len(group))
+		for i, fp := range group {
+			dirs[i] = fp.Path
+		}
+		m.logger.Info().
+			Str(algo, group[0].Hash).
+			Int("files", len(group[0].Files)).
+			Int64("size", group[0].Size).
+			Strs("dirs", dirs).
+			Msg("Found duplicated directories.")
+	}
+	for _, pair := range subsets {
+		m.logger.Info().
+			Str("subset", pair[0].Path).
+			Int("subsetFiles", len(pair[0].Files)).
+			Str("superset", pair[1].Path).
+			Int("supersetFiles", len(pair[1].Files)).
+			Msg("Found subset directory.")
+	}
+	m.logger.Info().Msgf("Found %d group(s) of %d duplicated directories, %d subset pair(s).", len(duplicates), dirCount, len(subsets))
+
+	return nil
+}
+
+// Hash all files in inputs, then return fingerprints of all directories in the order of listing.
+func (m *FileModule) fingerprintDirectories(inputs []string, algo, workspaceDir string, opts *HashOptions) ([]*directoryFingerprint, error) {
+	contents, err := filesystem.List(inputs, true)
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := []*directoryFingerprint{}
+	dirs := map[string]*directoryFingerprint{}
+	fPaths := []string{}
+	for _, c := range contents {
+		absPath := comparablePath(c.RelativePath)
+		if isWorkspacePath(absPath) {
+			continue
+		}
+		if c.IsDir {
+			if _, ok := dirs[absPath]; !ok {
+				fp := &directoryFingerprint{
+					Path:  c.RelativePath,
+					Files: map[string]string{},
+				}
+				fingerprints = append(fingerprints, fp)
+				dirs[absPath] = fp
+			}
+			continue
+		}
+		fPaths = append(fPaths, c.RelativePath)
+	}
+
+	err = hashFiles(m.logger, fPaths, []string{algo}, workspaceDir, opts, func(i int, fhResults []*hasher.HashResult, err error) error {
+		if err != nil {
+			m.logger.Info().
+				Str("path", fPaths[i]).
+				Msg("Failed to compute hash.")
+			return err
+		}
+		absPath := comparablePath(fPaths[i])
+		fHash := hex.EncodeToString(fhResults[0].Hash)
+		for dir := path.Dir(absPath); ; dir = path.Dir(dir) {
+			fp, ok := dirs[dir]
+			if !ok {
+				break
+			}
+			relPath := strings.TrimPrefix(strings.TrimPrefix(absPath, dir), "/")
+			if _, ok := fp.Files[relPath]; !ok {
+				fp.Files[relPath] = fHash
+				fp.Size += fhResults[0].Size
+			}
+			if dir == path.Dir(dir) {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fp := range fingerprints {
+		leaves := []hasher.MerkleLeaf{}
+		for relPath, fHash := range fp.Files {
+			hash, _ := hex.DecodeString(fHash)
+			leaves = append(leaves, hasher.MerkleLeaf{Path: relPath, Hash: hash})
+		}
+		root, err := hasher.MerkleRoot(algo, leaves)
+		if err != nil {
+			return nil, err
+		}
+		fp.Hash = hex.EncodeToString(root)
+		m.logger.Debug().
+			Str(algo, fp.Hash).
+			Int("files", len(fp.Files)).
+			Str("path", fp.Path).
+			Msg("Computed directory fingerprint.")
+	}
+	return fingerprints, nil
+}
+
+// Group directories having identical hashes, then find pairs of directories where files of the first one
+// are a strict subset of files of the second one. Directories without files are ignored. A group is omitted
+// if parents of its directories are identical, since it is implied by the group of their parents.
+// Nested directories are never paired as subset of each other, and a pair is omitted if their parents
+// are also a pair and they have the same names. Only directories sharing at least one file are compared.
+func compareDirectories(fingerprints []*directoryFingerprint) ([][]*directoryFingerprint, [][2]*directoryFingerprint) {
+	parentHashes := map[string]string{}
+	for _, fp := range fingerprints {
+		parentHashes[comparablePath(fp.Path)] = fp.Hash
+	}
+	groups := map[string][]*directoryFingerprint{}
+	hashes := []string{}
+	for _, fp := range fingerprints {
+		if len(fp.Files) == 0 {
+			continue
+		}
+		if _, ok := groups[fp.Hash]; !ok {
+			hashes = append(hashes, fp.Hash)
+		}
+		groups[fp.Hash] = append(groups[fp.Hash], fp)
+	}
+
+	duplicates := [][]*directoryFingerprint{}
+	for _, hash := range hashes {
+		group := groups[hash]
+		if len(group) < 2 {
+			continue
+		}
+		implied := true
+		parents := map[string]bool{}
+		for _, fp := range group {
+			parent := path.Dir(comparablePath(fp.Path))
+			parentHash, ok := parentHashes[parent]
+			if !ok || parents[parent] || parentHash != parentHashes[path.Dir(comparablePath(group[0].Path))] {
+				implied = false
+				break
+			}
+			parents[parent] = true
+		}
+		if !implied {
+			duplicates = append(duplicates, group)
+		}
+	}
+
+	// index groups by their files, so only groups sharing a file are compared.
+	fileGroups := map[string][]string{}
+	for _, hash := range hashes {
+		for relPath, fHash := range groups[hash][0].Files {
+			key := relPath + "\x00" + fHash
+			fileGroups[key] = append(fileGroups[key], hash)
+		}
+	}
+	subsets := [][2]*directoryFingerprint{}
+	for _, subHash := range hashes {
+		sub := groups[subHash][0]
+		// every superset contains all files of sub, so candidates sharing its rarest file are enough.
+		var candidates []string
+		for relPath, fHash := range sub.Files {
+			shared := fileGroups[relPath+"\x00"+fHash]
+			if candidates == nil || len(shared) < len(candidates) {
+				candidates = shared
+			}
+		}
+		for _, superHash := range candidates {
+			super := groups[superHash][0]
+			if len(sub.Files) >= len(super.Files) || !isFileSubset(sub.Files, super.Files) {
+				continue
+			}
+			for _, a := range groups[subHash] {
+				for _, b := range groups[superHash] {
+					aPath, bPath := comparablePath(a.Path), comparablePath(b.Path)
+					if strings.HasPrefix(aPath+"/", bPath+"/") || strings.HasPrefix(bPath+"/", aPath+"/") {
+						continue
+					}
+					subsets = append(subsets, [2]*directoryFingerprint{a, b})
+				}
+			}
+		}
+	}
+
+	// omit pairs implied by a pair of their parents having the same names.
+	pairs := map[[2]string]bool{}
+	for _, pair := range subsets {
+		pairs[[2]string{comparablePath(pair[0].Path), comparablePath(pair[1].Path)}] = true
+	}
+	result := [][2]*directoryFingerprint{}
+	for _, pair := range subsets {
+		aPath, bPath := comparablePath(pair[0].Path), comparablePath(pair[1].Path)
+		if path.Base(aPath) == path.Base(bPath) && pairs[[2]string{path.Dir(aPath), path.Dir(bPath)}] {
+			continue
+		}
+		result = append(result, pair)
+	}
+	return duplicates, result
+}
+
+// Check whether every file of sub exists in super at the same relative path with the same hash.
+func isFileSubset(sub, super map[string]string) bool {
+	for relPath, fHash := range sub {
+		if super[relPath] != fHash {
+			return false
+		}
+	}
+	return true
+}
@@ -20,14 +20,18 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 	"github.com/tforce-io/tf-golib/opx"
+	"github.com/tforce-io/tf-golib/opx/slicext"
 	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
 	"github.com/tforceaio/tf-unifiler-go/filesystem"
 )
@@ -50,46 +54,228 @@ func NewFileModule(c *Controller, cmdName string) *FileModule {
 	}
 }
 
-// Compute hashes using common algorithms (MD5, SHA-1, SHA-256, SHA-512) for inputs (files/folders),
-// then print the result to console.
-func (m *FileModule) Hash(inputs []string) error {
+// Find files having identical contents in inputs (files/folders). Files are grouped by size first,
+// then by quick fingerprint, only files sharing both of them will be fully hashed. SHA-256 is used
+// by default, in fast mode XXH3-128 is used instead, which is much faster, then only matches are
+// confirmed using SHA-256. Hash cache of workspaceDir is used if it is set.
+func (m *FileModule) Duplicate(inputs []string, fast bool, workspaceDir string, opts *HashOptions) error {
 	if len(inputs) == 0 {
 		return errors.New("inputs is empty")
 	}
+	algo := opx.Ternary(fast, "xxh3-128", "sha256")
 	m.logger.Info().
+		Str("algo", algo).
 		Strs("files", inputs).
-		Msg("Start hashing files.")
+		Str("workspace", workspaceDir).
+		Msg("Start finding duplicated files.")
 
 	contents, err := filesystem.List(inputs, true)
 	if err != nil {
 		return err
 	}
 
-	algos := []string{"md5", "sha1", "sha256", "sha512"}
+	fPaths := []string{}
+	fSizes := map[string]int64{}
+	fSizeKeys := map[string]string{}
 	for _, c := range contents {
 		if c.IsDir {
 			continue
 		}
-		fhResults, err := hasher.Hash(c.RelativePath, algos)
+		fi, err := os.Stat(c.RelativePath)
+		if err != nil {
+			return err
+		}
+		fPaths = append(fPaths, c.RelativePath)
+		fSizes[c.RelativePath] = fi.Size()
+		fSizeKeys[c.RelativePath] = strconv.FormatInt(fi.Size(), 10)
+	}
+	groups := splitFileGroups([][]string{fPaths}, fSizeKeys)
+
+	fPaths = []string{}
+	for _, group := range groups {
+		fPaths = append(fPaths, group...)
+	}
+	fFingerprints := map[string]string{}
+	err = hashFiles(m.logger, fPaths, []string{hasher.FingerprintAlgorithm}, workspaceDir, opts, func(i int, fhResults []*hasher.HashResult, err error) error {
 		if err != nil {
 			m.logger.Info().
-				Str("path", c.RelativePath).
-				Msg("Failed to compute hash.")
+				Str("path", fPaths[i]).
+				Msg("Failed to compute fingerprint.")
+			return err
+		}
+		fFingerprints[fPaths[i]] = hex.EncodeToString(fhResults[0].Hash)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	groups = splitFileGroups(groups, fFingerprints)
+
+	groups, fHashes, err := m.hashFileGroups(groups, algo, workspaceDir, opts)
+	if err != nil {
+		return err
+	}
+	// matches of fast mode are confirmed using SHA-256, only files sharing XXH3-128 are read again.
+	if fast {
+		algo = "sha256"
+		groups, fHashes, err = m.hashFileGroups(groups, algo, workspaceDir, opts)
+		if err != nil {
 			return err
 		}
+	}
+
+	fileCount := 0
+	for _, group := range groups {
+		fileCount += len(group)
 		m.logger.Info().
-			Str("md5", hex.EncodeToString(fhResults[0].Hash)).
-			Str("path", c.RelativePath).
-			Str("sha1", hex.EncodeToString(fhResults[1].Hash)).
-			Str("sha256", hex.EncodeToString(fhResults[2].Hash)).
-			Str("sha512", hex.EncodeToString(fhResults[3].Hash)).
-			Int("size", fhResults[0].Size).
-			Msg("Hashed file.")
+			Str(algo, fHashes[group[0]]).
+			Int64("size", fSizes[group[0]]).
+			Strs("files", group).
+			Msg("Found duplicated files.")
 	}
+	m.logger.Info().Msgf("Found %d group(s) of %d duplicated file(s).", len(groups), fileCount)
 
 	return nil
 }
 
+// Compute hash of files in groups using algo, then split groups by their hashes.
+// Return new groups and hashes of files in them.
+func (m *FileModule) hashFileGroups(groups [][]string, algo, workspaceDir string, opts *HashOptions) ([][]string, map[string]string, error) {
+	fPaths := []string{}
+	for _, group := range groups {
+		fPaths = append(fPaths, group...)
+	}
+	fHashes := map[string]string{}
+	err := hashFiles(m.logger, fPaths, []string{algo}, workspaceDir, opts, func(i int, fhResults []*hasher.HashResult, err error) error {
+		if err != nil {
+			m.logger.Info().
+				Str("path", fPaths[i]).
+				Msg("Failed to compute hash.")
+			return err
+		}
+		fHashes[fPaths[i]] = hex.EncodeToString(fhResults[0].Hash)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return splitFileGroups(groups, fHashes), fHashes, nil
+}
+
+// Compute hashes for inputs (files/folders), then print the result to console.
+// Common algorithms (MD5, SHA-1, SHA-256, SHA-512) will be used if algorithms is empty.
+// Input "-" reads content from stdin, which is hashed before other inputs.
+// If links is true, ed2k and magnet links of each file are also printed.
+// Results are written as log lines in log format, otherwise as records of format to stdout.
+// Hash cache of workspaceDir is used if it is set.
+func (m *FileModule) Hash(inputs, algorithms []string, links bool, format, workspaceDir string, opts *HashOptions) error {
+	if len(inputs) == 0 {
+		return errors.New("inputs is empty")
+	}
+	if len(algorithms) == 0 {
+		algorithms = hasher.CommonAlgorithms()
+	}
+	algos, err := hasher.Normalize(algorithms)
+	if err != nil {
+		return err
+	}
+	if links {
+		algos = appendMissingAlgorithms(algos, "ed2k", "tth")
+	}
+	writer, err := newHashRecordWriter(os.Stdout, format, algos, links)
+	if err != nil {
+		return err
+	}
+	m.logger.Info().
+		Strs("algos", algos).
+		Strs("files", inputs).
+		Str("format", format).
+		Bool("links", links).
+		Str("workspace", workspaceDir).
+		Msg("Start hashing files.")
+
+	fInputs := []string{}
+	for _, input := range inputs {
+		if input != "-" {
+			fInputs = append(fInputs, input)
+			continue
+		}
+		display := newHashProgress(os.Stderr, 1, opts != nil && opts.Progress)
+		fhResults, err := hasher.HashReader(os.Stdin, algos, -1, display.Func())
+		display.Done("")
+		if err != nil {
+			m.logger.Info().
+				Str("path", input).
+				Msg("Failed to compute hash.")
+			return err
+		}
+		// stdin has no name, so links cannot be created.
+		err = m.writeHashResults(writer, input, fhResults, false)
+		if err != nil {
+			return err
+		}
+	}
+	if len(fInputs) == 0 {
+		return closeHashRecordWriter(writer)
+	}
+
+	contents, err := filesystem.List(fInputs, true)
+	if err != nil {
+		return err
+	}
+
+	fPaths := []string{}
+	for _, c := range contents {
+		if !c.IsDir {
+			fPaths = append(fPaths, c.RelativePath)
+		}
+	}
+	err = hashFiles(m.logger, fPaths, algos, workspaceDir, opts, func(i int, fhResults []*hasher.HashResult, err error) error {
+		if err != nil {
+			m.logger.Info().
+				Str("path", fPaths[i]).
+				Msg("Failed to compute hash.")
+			return err
+		}
+		return m.writeHashResults(writer, fPaths[i], fhResults, links)
+	})
+	if err != nil {
+		return err
+	}
+	return closeHashRecordWriter(writer)
+}
+
+// Write hashes of a file as a record using writer, or as a log line if writer is nil.
+func (m *FileModule) writeHashResults(writer hashRecordWriter, fPath string, fhResults []*hasher.HashResult, links bool) error {
+	if writer == nil {
+		m.logHashResults(fPath, fhResults, links)
+		return nil
+	}
+	return writer.Write(NewHashRecord(fPath, fhResults, links))
+}
+
+// Print hashes of a file computed using multiple algorithms in a single log line.
+// ed2k and magnet links are included if links is true.
+func (m *FileModule) logHashResults(fPath string, fhResults []*hasher.HashResult, links bool) {
+	event := m.logger.Info()
+	for _, r := range fhResults {
+		event = event.Str(r.Algorithm, hex.EncodeToString(r.Hash))
+	}
+	if links {
+		name := filepath.Base(fPath)
+		if r := hasher.FindResult(fhResults, "ed2k"); r != nil {
+			event = event.Str("ed2kLink", hasher.Ed2kLink(name, r.Size, r.Hash))
+		}
+		if r := hasher.FindResult(fhResults, "tth"); r != nil {
+			event = event.Str("magnetLink", hasher.MagnetLink(name, r.Size, r.Hash))
+		}
+	}
+	event.
+		Str("path", fPath).
+		Int64("size", fhResults[0].Size).
+		Msg("Hashed file.")
+}
+
 // Multi-rename files. Input which is directories will be ignored.
 func (m *FileModule) Rename(inputs []string, preset string) error {
 	if len(inputs) == 0 {
@@ -100,20 +286,9 @@ func (m *FileModule) Rename(inputs []string, preset string) error {
 		Str("preset", preset).
 		Msg("Start renaming file.")
 
-	if preset == "md4" {
-		return m.renameByHash(inputs, preset, "6d6434_")
-	}
-	if preset == "md5" {
-		return m.renameByHash(inputs, preset, "6d6435_")
-	}
-	if preset == "sha1" {
-		return m.renameByHash(inputs, preset, "73686131_")
-	}
-	if preset == "sha256" {
-		return m.renameByHash(inputs, preset, "736861323536_")
-	}
-	if preset == "sha512" {
-		return m.renameByHash(inputs, preset, "736861353132_")
+	// preset is name of hash algorithm, the new name is prefixed with hex encoded algorithm name.
+	if algo, ok := hasher.Lookup(preset); ok {
+		return m.renameByHash(inputs, algo.Name, hex.EncodeToString([]byte(algo.Name))+"_")
 	}
 
 	return errors.New("preset is invalid")
@@ -151,7 +326,7 @@ func (m *FileModule) renameByHash(inputs []string, algo string, prefix string) e
 		m.logger.Info().
 			Str("algo", algo).
 			Str("path", c.RelativePath).
-			Int("size", fhResults[0].Size).
+			Int64("size", fhResults[0].Size).
 			Msg("Hashed file.")
 		hResults = append(hResults, fhResults...)
 	}
@@ -233,18 +408,85 @@ func FileCmd() *cobra.Command {
 		Short: "Batch file processing in general.",
 	}
 
-	hashCmd := &cobra.Command{
-		Use:   "hash <input>...",
-		Short: "Compute hashes for files using common algorithms (MD5, SHA-1, SHA-256, SHA-512).",
+	dirhashCmd := &cobra.Command{
+		Use:   "dirhash <input>...",
+		Short: "Compute deterministic hashes of directories from relative paths and hashes of their files.",
 		Run: func(cmd *cobra.Command, args []string) {
 			c := InitApp()
 			defer c.Close()
 			flags := ParseFileFlags(cmd, args)
+			m := NewFileModule(c, "dirhash")
+			m.logError(m.DirectoryHash(flags.Inputs, flags.Fast, flags.WorkspaceDir, flags.HashOptions))
+		},
+	}
+	dirhashCmd.Flags().Bool("fast", false, "Use XXH3-128 instead of SHA-256 for quick hashing. Hashes computed in fast mode are not comparable to those computed in normal mode.")
+	dirhashCmd.Flags().StringArrayP("inputs", "i", []string{}, "Directories to compute hash.")
+	dirhashCmd.Flags().StringP("workspace", "w", "", "Directory contains Unifiler workspace. If it is set, hashes will be cached in the workspace.")
+	addHashFlags(dirhashCmd)
+	rootCmd.AddCommand(dirhashCmd)
+
+	duplicateCmd := &cobra.Command{
+		Use:   "duplicate <input>...",
+		Short: "Find files having identical contents.",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := InitApp()
+			defer c.Close()
+			flags := ParseFileFlags(cmd, args)
+			m := NewFileModule(c, "duplicate")
+			m.logError(m.Duplicate(flags.Inputs, flags.Fast, flags.WorkspaceDir, flags.HashOptions))
+		},
+	}
+	duplicateCmd.Flags().Bool("fast", false, "Use XXH3-128 instead of SHA-256 for quick scanning. Matches are confirmed using SHA-256.")
+	duplicateCmd.Flags().StringArrayP("inputs", "i", []string{}, "Files/Directories to scan.")
+	duplicateCmd.Flags().StringP("workspace", "w", "", "Directory contains Unifiler workspace. If it is set, hashes will be cached in the workspace.")
+	addHashFlags(duplicateCmd)
+	rootCmd.AddCommand(duplicateCmd)
+
+	duplicateDirCmd := &cobra.Command{
+		Use:   "duplicate-dir <input>...",
+		Short: "Find directories having identical contents, and directories being strict subsets of another one.",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := InitApp()
+			defer c.Close()
+			flags := ParseFileFlags(cmd, args)
+			m := NewFileModule(c, "duplicate-dir")
+			m.logError(m.DuplicateDirectory(flags.Inputs, flags.Fast, flags.WorkspaceDir, flags.HashOptions))
+		},
+	}
+	duplicateDirCmd.Flags().Bool("fast", false, "Use XXH3-128 instead of SHA-256 for quick scanning. Matches should be verified using cryptographic hashes.")
+	duplicateDirCmd.Flags().StringArrayP("inputs", "i", []string{}, "Directories to scan.")
+	duplicateDirCmd.Flags().StringP("workspace", "w", "", "Directory contains Unifiler workspace. If it is set, hashes will be cached in the workspace.")
+	addHashFlags(duplicateDirCmd)
+	rootCmd.AddCommand(duplicateDirCmd)
+
+	hashCmd := &cobra.Command{
+		Use:   "hash <input>...",
+		Long:  "Compute hashes for files, using common algorithms (MD5, SHA-1, SHA-256, SHA-512) by default. Use - as input to hash stdin.",
+		Short: "Compute hashes for files, using common algorithms (MD5, SHA-1, SHA-256, SHA-512) by default.",
+		Run: func(cmd *cobra.Command, args []string) {
+			flags := ParseFileFlags(cmd, args)
+			var c *Controller
+			if flags.Format == "" || flags.Format == "log" {
+				c = InitApp()
+			} else {
+				c = InitAppStderr()
+			}
+			defer c.Close()
 			m := NewFileModule(c, "hash")
-			m.logError(m.Hash(flags.Inputs))
+			if err := flags.KeyOptions.Apply(); err != nil {
+				m.logError(err)
+				return
+			}
+			m.logError(m.Hash(flags.Inputs, flags.Algorithms, flags.Links, flags.Format, flags.WorkspaceDir, flags.HashOptions))
 		},
 	}
+	hashCmd.Flags().StringSliceP("algo", "a", hasher.CommonAlgorithms(), "Hash algorithms to use, comma-separated list supported. "+hashAlgorithmsUsage())
+	hashCmd.Flags().StringP("format", "f", "log", fmt.Sprintf("Output format. Formats other than log write records to stdout and logs to stderr. Supported formats: %s.", strings.Join(hashOutputFormats, ", ")))
 	hashCmd.Flags().StringArrayP("inputs", "i", []string{}, "Files/Directories to hash.")
+	hashCmd.Flags().Bool("links", false, "Also compute ed2k and TTH, then print ed2k and magnet links for each file.")
+	hashCmd.Flags().StringP("workspace", "w", "", "Directory contains Unifiler workspace. If it is set, hashes will be cached in the workspace.")
+	addHashFlags(hashCmd)
+	addKeyFlags(hashCmd)
 	rootCmd.AddCommand(hashCmd)
 
 	renameCmd := &cobra.Command{
@@ -259,7 +501,7 @@ func FileCmd() *cobra.Command {
 		},
 	}
 	renameCmd.Flags().StringArrayP("inputs", "i", []string{}, "Files to rename. Directories will be ignored.")
-	renameCmd.Flags().StringP("preset", "p", "", "Name of pre-defined settings for renaming.")
+	renameCmd.Flags().StringP("preset", "p", "", fmt.Sprintf("Name of pre-defined settings for renaming. Supported presets: %s.", strings.Join(hasher.Names(), ", ")))
 	rootCmd.AddCommand(renameCmd)
 
 	return rootCmd
@@ -267,18 +509,70 @@ func FileCmd() *cobra.Command {
 
 // Struct FileFlags contains all flags used by File module.
 type FileFlags struct {
-	Inputs []string
-	Preset string
+	Algorithms   []string
+	Fast         bool
+	Format       string
+	HashOptions  *HashOptions
+	Inputs       []string
+	KeyOptions   *KeyOptions
+	Links        bool
+	Preset       string
+	WorkspaceDir string
 }
 
 // Extract all flags from a Cobra Command.
 func ParseFileFlags(cmd *cobra.Command, args []string) *FileFlags {
+	algorithms, _ := cmd.Flags().GetStringSlice("algo")
+	fast, _ := cmd.Flags().GetBool("fast")
+	format, _ := cmd.Flags().GetString("format")
 	inputs, _ := cmd.Flags().GetStringArray("inputs")
+	links, _ := cmd.Flags().GetBool("links")
 	preset, _ := cmd.Flags().GetString("preset")
+	workspaceDir, _ := cmd.Flags().GetString("workspace")
 	inputs = append(args, inputs...)
 
 	return &FileFlags{
-		Inputs: inputs,
-		Preset: preset,
+		Algorithms:   algorithms,
+		Fast:         fast,
+		Format:       format,
+		HashOptions:  ParseHashOptions(cmd),
+		Inputs:       inputs,
+		KeyOptions:   ParseKeyOptions(cmd),
+		Links:        links,
+		Preset:       preset,
+		WorkspaceDir: workspaceDir,
+	}
+}
+
+// Append algorithms which are not in algos yet.
+func appendMissingAlgorithms(algos []string, algorithms ...string) []string {
+	for _, a := range algorithms {
+		if !slicext.Contains(algos, a) {
+			algos = append(algos, a)
+		}
+	}
+	return algos
+}
+
+// Split each group of files by their keys, groups having only 1 file are discarded.
+// Order of files is preserved, groups are ordered by their first files.
+func splitFileGroups(groups [][]string, keys map[string]string) [][]string {
+	result := [][]string{}
+	for _, group := range groups {
+		subGroups := map[string][]string{}
+		subKeys := []string{}
+		for _, fPath := range group {
+			key := keys[fPath]
+			if _, ok := subGroups[key]; !ok {
+				subKeys = append(subKeys, key)
+			}
+			subGroups[key] = append(subGroups[key], fPath)
+		}
+		for _, key := range subKeys {
+			if len(subGroups[key]) > 1 {
+				result = append(result, subGroups[key])
+			}
+		}
 	}
+	return result
 }
@@ -0,0 +1,191 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+)
+
+func TestSplitFileGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		groups   [][]string
+		keys     map[string]string
+		expected [][]string
+	}{
+		{
+			"unique files",
+			[][]string{{"a", "b", "c"}},
+			map[string]string{"a": "1", "b": "2", "c": "3"},
+			[][]string{},
+		},
+		{
+			"keep order",
+			[][]string{{"a", "b", "c", "d", "e"}},
+			map[string]string{"a": "2", "b": "1", "c": "2", "d": "1", "e": "3"},
+			[][]string{{"a", "c"}, {"b", "d"}},
+		},
+		{
+			"multiple groups",
+			[][]string{{"a", "b"}, {"c", "d", "e"}},
+			map[string]string{"a": "1", "b": "1", "c": "1", "d": "2", "e": "2"},
+			[][]string{{"a", "b"}, {"d", "e"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := splitFileGroups(tt.groups, tt.keys)
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Wrong groups. Expected '%v'. Actual '%v'.", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestHashFileGroups(t *testing.T) {
+	dir := t.TempDir()
+	contents := map[string]string{"a": "hello", "b": "hello", "c": "world", "d": "world", "e": "12345"}
+	for name, content := range contents {
+		filesystem.WriteLines(filepath.Join(dir, name), []string{content})
+	}
+	path := func(name string) string { return filepath.Join(dir, name) }
+	tests := []struct {
+		name     string
+		algo     string
+		groups   [][]string
+		expected [][]string
+	}{
+		{"sha256", "sha256", [][]string{{path("a"), path("b"), path("c"), path("e")}}, [][]string{{path("a"), path("b")}}},
+		{"xxh3-128", "xxh3-128", [][]string{{path("a"), path("c"), path("d")}, {path("b"), path("e")}}, [][]string{{path("c"), path("d")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module := &FileModule{
+				logger: log.Logger,
+			}
+			actual, hashes, err := module.hashFileGroups(tt.groups, tt.algo, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Wrong groups. Expected '%v'. Actual '%v'.", tt.expected, actual)
+			}
+			for _, group := range actual {
+				if hashes[group[0]] == "" || hashes[group[0]] != hashes[group[1]] {
+					t.Errorf("Wrong hashes. Actual '%v'.", hashes)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareDirectories(t *testing.T) {
+	fp := func(dirPath, hash string, files map[string]string) *directoryFingerprint {
+		return &directoryFingerprint{Path: dirPath, Hash: hash, Files: files}
+	}
+	tests := []struct {
+		name         string
+		fingerprints []*directoryFingerprint
+		duplicates   [][]string
+		subsets      [][2]string
+	}{
+		{
+			"identical siblings",
+			[]*directoryFingerprint{
+				fp("lib", "0", map[string]string{"a/x": "1", "b/x": "1"}),
+				fp("lib/a", "1", map[string]string{"x": "1"}),
+				fp("lib/b", "1", map[string]string{"x": "1"}),
+			},
+			[][]string{{"lib/a", "lib/b"}},
+			[][2]string{},
+		},
+		{
+			"implied by parents",
+			[]*directoryFingerprint{
+				fp("a", "1", map[string]string{"s/x": "1", "y": "2"}),
+				fp("a/s", "2", map[string]string{"x": "1"}),
+				fp("b", "1", map[string]string{"s/x": "1", "y": "2"}),
+				fp("b/s", "2", map[string]string{"x": "1"}),
+				fp("e1", "3", map[string]string{}),
+				fp("e2", "3", map[string]string{}),
+			},
+			[][]string{{"a", "b"}},
+			[][2]string{},
+		},
+		{
+			"strict subset",
+			[]*directoryFingerprint{
+				fp("full", "1", map[string]string{"s/x": "1", "y": "2"}),
+				fp("full/s", "2", map[string]string{"x": "1"}),
+				fp("part", "3", map[string]string{"s/x": "1"}),
+				fp("part/s", "2", map[string]string{"x": "1"}),
+				fp("other", "4", map[string]string{"y": "3"}),
+			},
+			[][]string{{"full/s", "part/s"}},
+			[][2]string{{"part", "full"}},
+		},
+		{
+			"multiple supersets",
+			[]*directoryFingerprint{
+				fp("p", "1", map[string]string{"x": "1", "y": "2"}),
+				fp("q", "2", map[string]string{"x": "1", "y": "2", "z": "3"}),
+				fp("r", "3", map[string]string{"x": "1", "y": "2", "w": "4"}),
+				fp("s", "4", map[string]string{"x": "1", "y": "5", "z": "3"}),
+				fp("t", "5", map[string]string{"v": "6"}),
+			},
+			[][]string{},
+			[][2]string{{"p", "q"}, {"p", "r"}},
+		},
+		{
+			"nested subset",
+			[]*directoryFingerprint{
+				fp("a", "1", map[string]string{"x": "1", "a/x": "1"}),
+				fp("a/a", "2", map[string]string{"x": "1"}),
+			},
+			[][]string{},
+			[][2]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duplicates, subsets := compareDirectories(tt.fingerprints)
+			actualDuplicates := [][]string{}
+			for _, group := range duplicates {
+				dirs := []string{}
+				for _, fp := range group {
+					dirs = append(dirs, fp.Path)
+				}
+				actualDuplicates = append(actualDuplicates, dirs)
+			}
+			actualSubsets := [][2]string{}
+			for _, pair := range subsets {
+				actualSubsets = append(actualSubsets, [2]string{pair[0].Path, pair[1].Path})
+			}
+			if !reflect.DeepEqual(actualDuplicates, tt.duplicates) {
+				t.Errorf("Wrong duplicates. Expected '%v'. Actual '%v'.", tt.duplicates, actualDuplicates)
+			}
+			if !reflect.DeepEqual(actualSubsets, tt.subsets) {
+				t.Errorf("Wrong subsets. Expected '%v'. Actual '%v'.", tt.subsets, actualSubsets)
+			}
+		})
+	}
+}
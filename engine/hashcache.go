@@ -0,0 +1,248 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"encoding/hex"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+	"github.com/tforceaio/tf-unifiler-go/db"
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+)
+
+// Number of cached hashes written to database in a single batch.
+const hashCacheBatchSize = 512
+
+// Struct hashCache implements hasher.Cache and hasher.CheckpointStore using hash cache database
+// of a workspace. Files are identified by device and inode, cached hashes and checkpoints are
+// invalidated when size or modification time of a file changes.
+// If noCache is set, only checkpoints are stored.
+type hashCache struct {
+	mu      sync.Mutex
+	ctx     *db.DbContext
+	logger  zerolog.Logger
+	noCache bool
+	rehash  bool
+	keys    map[string]*hashCacheKey
+	pending []*db.CachedHash
+}
+
+// Struct hashCacheKey is the state of a file observed before it is hashed.
+type hashCacheKey struct {
+	device  uint64
+	inode   uint64
+	size    int64
+	modTime time.Time
+}
+
+// Return hashCache of a workspace, or nil if workspaceDir is empty or both caching and
+// checkpoints are disabled.
+func openHashCache(logger zerolog.Logger, workspaceDir string, opts *HashOptions) (*hashCache, error) {
+	if workspaceDir == "" || (opts.NoCache && opts.CheckpointInterval <= 0) {
+		return nil, nil
+	}
+	ctx, err := db.ConnectCache(HashCacheDatabase(workspaceDir))
+	if err != nil {
+		return nil, err
+	}
+	return &hashCache{
+		ctx:     ctx,
+		logger:  logger,
+		noCache: opts.NoCache,
+		rehash:  opts.Rehash,
+		keys:    map[string]*hashCacheKey{},
+	}, nil
+}
+
+// Return cached hashes of a file if all algorithms are found and the file is unchanged.
+func (c *hashCache) Get(fPath string, algorithms []string) ([]*hasher.HashResult, bool) {
+	key, err := newHashCacheKey(fPath)
+	if err != nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys[fPath] = key
+	if c.noCache || c.rehash {
+		return nil, false
+	}
+	hashes, err := c.ctx.GetCachedHashes(key.device, key.inode, key.size, key.modTime)
+	if err != nil {
+		c.logger.Warn().Err(err).Str("path", fPath).Msg("Failed to read hash cache.")
+		return nil, false
+	}
+	results := make([]*hasher.HashResult, len(algorithms))
+	for i, a := range algorithms {
+		for _, h := range hashes {
+			if h.Algorithm == cacheAlgorithm(a) {
+				hash, err := hex.DecodeString(h.Hash)
+				if err != nil {
+					return nil, false
+				}
+				results[i] = &hasher.HashResult{
+					Path:      fPath,
+					Size:      key.size,
+					Algorithm: a,
+					Hash:      hash,
+				}
+				break
+			}
+		}
+		if results[i] == nil {
+			return nil, false
+		}
+	}
+	return results, true
+}
+
+// Queue hashes of a file to be written to cache. Results are discarded if the file size
+// has changed during hashing.
+func (c *hashCache) Put(fPath string, results []*hasher.HashResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key, ok := c.keys[fPath]
+	if !ok {
+		return
+	}
+	delete(c.keys, fPath)
+	if c.noCache || len(results) == 0 || results[0].Size != key.size {
+		return
+	}
+	for _, r := range results {
+		c.pending = append(c.pending, db.NewCachedHash(key.device, key.inode, key.size, key.modTime, cacheAlgorithm(r.Algorithm), hex.EncodeToString(r.Hash)))
+	}
+	if len(c.pending) >= hashCacheBatchSize {
+		c.flush()
+	}
+}
+
+// Return name of an algorithm in cache. Keyed algorithms are suffixed with fingerprint of
+// the current key, so digests computed using another key are never returned.
+func cacheAlgorithm(algorithm string) string {
+	if hasher.IsKeyed(algorithm) {
+		return algorithm + "@" + hasher.CurrentKey().Fingerprint()
+	}
+	return algorithm
+}
+
+// Return checkpoint of a file if it is unchanged since the checkpoint was saved.
+func (c *hashCache) LoadCheckpoint(fPath string) (*hasher.Checkpoint, bool) {
+	key, err := c.checkpointKey(fPath)
+	if err != nil || c.rehash {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	doc, err := c.ctx.GetHashCheckpoint(key.device, key.inode, key.size, key.modTime)
+	if err != nil {
+		c.logger.Warn().Err(err).Str("path", fPath).Msg("Failed to read checkpoint.")
+		return nil, false
+	}
+	if doc == nil {
+		return nil, false
+	}
+	states, err := doc.GetStates()
+	if err != nil {
+		return nil, false
+	}
+	c.logger.Info().
+		Int64("offset", doc.Offset).
+		Str("path", fPath).
+		Msg("Resuming hashing from checkpoint.")
+	return &hasher.Checkpoint{
+		Algorithms: doc.GetAlgorithms(),
+		Offset:     doc.Offset,
+		States:     states,
+	}, true
+}
+
+// Save checkpoint of a file using its state observed before it is hashed.
+func (c *hashCache) SaveCheckpoint(fPath string, cp *hasher.Checkpoint) error {
+	key, err := c.checkpointKey(fPath)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ctx.SaveHashCheckpoint(db.NewHashCheckpoint(key.device, key.inode, key.size, key.modTime, cp.Algorithms, cp.Offset, cp.States))
+}
+
+// Delete checkpoint of a file which has been hashed completely.
+func (c *hashCache) DeleteCheckpoint(fPath string) {
+	key, err := c.checkpointKey(fPath)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.ctx.DeleteHashCheckpoint(key.device, key.inode); err != nil {
+		c.logger.Warn().Err(err).Str("path", fPath).Msg("Failed to delete checkpoint.")
+	}
+}
+
+// Return key of a file recorded by Get, so checkpoints saved while the file is being
+// modified are never matched later. The file is inspected if it has not been recorded.
+func (c *hashCache) checkpointKey(fPath string) (*hashCacheKey, error) {
+	c.mu.Lock()
+	key, ok := c.keys[fPath]
+	c.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	return newHashCacheKey(fPath)
+}
+
+// Write pending hashes and close the cache. Nil hashCache is a no-op.
+func (c *hashCache) Close() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flush()
+	c.ctx.Disconnect()
+}
+
+func (c *hashCache) flush() {
+	err := c.ctx.SaveCachedHashes(c.pending)
+	if err != nil {
+		c.logger.Warn().Err(err).Int("count", len(c.pending)).Msg("Failed to write hash cache.")
+	}
+	c.pending = nil
+}
+
+// Return identity, size and modification time of a file.
+func newHashCacheKey(fPath string) (*hashCacheKey, error) {
+	fi, err := os.Stat(fPath)
+	if err != nil {
+		return nil, err
+	}
+	device, inode, err := filesystem.GetFileID(fPath)
+	if err != nil {
+		return nil, err
+	}
+	return &hashCacheKey{
+		device:  device,
+		inode:   inode,
+		size:    fi.Size(),
+		modTime: fi.ModTime(),
+	}, nil
+}
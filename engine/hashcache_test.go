@@ -0,0 +1,81 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+)
+
+func TestOpenHashCache(t *testing.T) {
+	tests := []struct {
+		group      string
+		opts       *HashOptions
+		opened     bool
+		checkpoint bool
+		cached     bool
+	}{
+		{"default", &HashOptions{CheckpointInterval: 1024}, true, true, true},
+		{"no_cache", &HashOptions{CheckpointInterval: 1024, NoCache: true}, true, true, false},
+		{"no_checkpoint", &HashOptions{}, true, false, true},
+		{"disabled", &HashOptions{NoCache: true}, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.group, func(t *testing.T) {
+			dir := t.TempDir()
+			fPath := filepath.Join(dir, "a.txt")
+			filesystem.WriteLines(fPath, []string{"hello"})
+			cache, err := openHashCache(log.Logger, dir, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (cache != nil) != tt.opened {
+				t.Fatalf("unexpected cache. expected %v actual %v", tt.opened, cache != nil)
+			}
+			if cache == nil {
+				return
+			}
+			defer cache.Close()
+
+			results, err := hasher.Hash(fPath, []string{"sha256"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cache.Get(fPath, []string{"sha256"})
+			cache.Put(fPath, results)
+			cache.flush()
+			if _, ok := cache.Get(fPath, []string{"sha256"}); ok != tt.cached {
+				t.Errorf("unexpected cached hashes. expected %v actual %v", tt.cached, ok)
+			}
+
+			if !tt.checkpoint {
+				return
+			}
+			cp := &hasher.Checkpoint{Algorithms: []string{"sha256"}, Offset: 0, States: [][]byte{{}}}
+			if err := cache.SaveCheckpoint(fPath, cp); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := cache.LoadCheckpoint(fPath); !ok {
+				t.Errorf("checkpoint is not saved")
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+)
+
+// Struct HashOptions contains settings shared by all commands that hash many files.
+type HashOptions struct {
+	BlockSize          int64 // size of blocks of block maps in bytes, 0 disables block maps
+	CheckpointInterval int64 // bytes hashed between checkpoints, 0 disables checkpoints
+	Jobs               int
+	NoCache            bool
+	Progress           bool
+	Rehash             bool
+}
+
+// Return path to hash cache database of a workspace.
+func HashCacheDatabase(workspaceDir string) string {
+	return filepath.Join(workspaceDir, ".unifiler", "cache.db")
+}
+
+// Compute hashes of files using settings in opts, results are delivered to fn in the order of fPaths.
+// Hash cache of workspaceDir is used unless workspaceDir is empty or caching is disabled,
+// checkpoints of large files are also saved to it so interrupted hashing can be resumed.
+// Checkpoints are saved even if caching is disabled, unless checkpoint interval is 0.
+// If block size of opts is set, block map of each file is appended to its results.
+func hashFiles(logger zerolog.Logger, fPaths, algorithms []string, workspaceDir string, opts *HashOptions, fn hasher.HashCallback) error {
+	if opts == nil {
+		opts = &HashOptions{Jobs: 1}
+	}
+	cache, err := openHashCache(logger, workspaceDir, opts)
+	if err != nil {
+		return err
+	}
+	defer cache.Close()
+
+	display := newHashProgress(os.Stderr, len(fPaths), opts.Progress)
+	poolOpts := &hasher.PoolOptions{
+		BlockSize: opts.BlockSize,
+		Jobs:      opts.Jobs,
+		Progress:  display.Func(),
+	}
+	if cache != nil {
+		poolOpts.Cache = cache
+		if opts.CheckpointInterval > 0 {
+			poolOpts.Checkpoints = cache
+			poolOpts.CheckpointInterval = opts.CheckpointInterval
+		}
+	}
+	return hasher.HashFiles(fPaths, algorithms, poolOpts, func(i int, results []*hasher.HashResult, err error) error {
+		display.Done(fPaths[i])
+		return fn(i, results, err)
+	})
+}
+
+// Define flags for HashOptions.
+func addHashFlags(cmd *cobra.Command) {
+	cmd.Flags().Int64("checkpoint", 1024, "Save state of hashing large files to the workspace every N MiB, so interrupted hashing can be resumed. Use 0 to disable.")
+	cmd.Flags().IntP("jobs", "j", 1, "Number of files to hash concurrently. Use 0 to match number of CPUs.")
+	cmd.Flags().Bool("no-cache", false, "Neither read from nor write to hash cache of the workspace. Checkpoints are still saved unless --checkpoint is 0.")
+	cmd.Flags().Bool("progress", false, "Show progress of hashing on stderr.")
+	cmd.Flags().Bool("rehash", false, "Hash all files again and refresh hash cache of the workspace.")
+}
+
+// Extract HashOptions from a Cobra Command.
+func ParseHashOptions(cmd *cobra.Command) *HashOptions {
+	blockSize, _ := cmd.Flags().GetInt64("block-size")
+	checkpoint, _ := cmd.Flags().GetInt64("checkpoint")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+	progress, _ := cmd.Flags().GetBool("progress")
+	rehash, _ := cmd.Flags().GetBool("rehash")
+
+	return &HashOptions{
+		BlockSize:          blockSize * 1024,
+		CheckpointInterval: checkpoint * 1024 * 1024,
+		Jobs:               jobs,
+		NoCache:            noCache,
+		Progress:           progress,
+		Rehash:             rehash,
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+)
+
+// Formats of hash results supported by file hash. Log format only writes log lines,
+// the others write records to stdout.
+var hashOutputFormats = []string{"log", "json", "ndjson", "csv", "table"}
+
+// Struct HashRecord contains hex encoded hashes of a file, keyed by algorithm name.
+type HashRecord struct {
+	Path       string            `json:"path"`
+	Size       int64             `json:"size"`
+	Hashes     map[string]string `json:"hashes"`
+	Ed2kLink   string            `json:"ed2kLink,omitempty"`
+	MagnetLink string            `json:"magnetLink,omitempty"`
+}
+
+// Return new HashRecord of a file from its hash results.
+// ed2k and magnet links are included if links is true.
+func NewHashRecord(fPath string, fhResults []*hasher.HashResult, links bool) *HashRecord {
+	record := &HashRecord{
+		Path:   fPath,
+		Hashes: map[string]string{},
+	}
+	if len(fhResults) > 0 {
+		record.Size = fhResults[0].Size
+	}
+	for _, r := range fhResults {
+		record.Hashes[r.Algorithm] = hex.EncodeToString(r.Hash)
+	}
+	if links {
+		name := filepath.Base(fPath)
+		if r := hasher.FindResult(fhResults, "ed2k"); r != nil {
+			record.Ed2kLink = hasher.Ed2kLink(name, r.Size, r.Hash)
+		}
+		if r := hasher.FindResult(fhResults, "tth"); r != nil {
+			record.MagnetLink = hasher.MagnetLink(name, r.Size, r.Hash)
+		}
+	}
+	return record
+}
+
+// Interface hashRecordWriter writes HashRecords in a specific format.
+// Close must be called after the last record so buffered records are written.
+type hashRecordWriter interface {
+	Write(record *HashRecord) error
+	Close() error
+}
+
+// Return hashRecordWriter of format which writes to out, or nil for log format.
+// Columns of tabular formats are ordered as algorithms.
+func newHashRecordWriter(out io.Writer, format string, algorithms []string, links bool) (hashRecordWriter, error) {
+	switch format {
+	case "", "log":
+		return nil, nil
+	case "json":
+		return &jsonRecordWriter{out: out, records: []*HashRecord{}}, nil
+	case "ndjson":
+		return &ndjsonRecordWriter{encoder: json.NewEncoder(out)}, nil
+	case "csv":
+		return newTabularRecordWriter(csvRowWriter{csv.NewWriter(out)}, algorithms, links, false)
+	case "table":
+		return newTabularRecordWriter(tableRowWriter{tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)}, algorithms, links, true)
+	}
+	return nil, fmt.Errorf("unsupported output format '%s'. Supported formats: %s", format, strings.Join(hashOutputFormats, ", "))
+}
+
+// Struct jsonRecordWriter writes all records as a single JSON array when it is closed.
+type jsonRecordWriter struct {
+	out     io.Writer
+	records []*HashRecord
+}
+
+func (w *jsonRecordWriter) Write(record *HashRecord) error {
+	w.records = append(w.records, record)
+	return nil
+}
+
+func (w *jsonRecordWriter) Close() error {
+	encoder := json.NewEncoder(w.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(w.records)
+}
+
+// Struct ndjsonRecordWriter writes each record as a JSON object on its own line.
+type ndjsonRecordWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonRecordWriter) Write(record *HashRecord) error {
+	return w.encoder.Encode(record)
+}
+
+func (w *ndjsonRecordWriter) Close() error {
+	return nil
+}
+
+// Interface rowWriter writes rows of cells, used by tabularRecordWriter.
+type rowWriter interface {
+	WriteRow(cells []string) error
+	Flush() error
+}
+
+// Struct csvRowWriter writes rows as CSV records, each row is flushed immediately.
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (w csvRowWriter) WriteRow(cells []string) error {
+	w.w.Write(cells)
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w csvRowWriter) Flush() error {
+	return nil
+}
+
+// Struct tableRowWriter writes rows as aligned columns, all rows are buffered until flushed.
+type tableRowWriter struct {
+	w *tabwriter.Writer
+}
+
+func (w tableRowWriter) WriteRow(cells []string) error {
+	_, err := fmt.Fprintln(w.w, strings.Join(cells, "\t"))
+	return err
+}
+
+func (w tableRowWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// Struct tabularRecordWriter writes records as rows having a header row of column names.
+type tabularRecordWriter struct {
+	algorithms []string
+	links      bool
+	rows       rowWriter
+}
+
+// Return new tabularRecordWriter after writing its header row.
+// Column names are upper case if upper is true.
+func newTabularRecordWriter(rows rowWriter, algorithms []string, links, upper bool) (*tabularRecordWriter, error) {
+	header := append([]string{"path", "size"}, algorithms...)
+	if links {
+		header = append(header, "ed2kLink", "magnetLink")
+	}
+	if upper {
+		for i, h := range header {
+			header[i] = strings.ToUpper(h)
+		}
+	}
+	err := rows.WriteRow(header)
+	if err != nil {
+		return nil, err
+	}
+	return &tabularRecordWriter{
+		algorithms: algorithms,
+		links:      links,
+		rows:       rows,
+	}, nil
+}
+
+func (w *tabularRecordWriter) Write(record *HashRecord) error {
+	row := []string{record.Path, strconv.FormatInt(record.Size, 10)}
+	for _, a := range w.algorithms {
+		row = append(row, record.Hashes[a])
+	}
+	if w.links {
+		row = append(row, record.Ed2kLink, record.MagnetLink)
+	}
+	return w.rows.WriteRow(row)
+}
+
+func (w *tabularRecordWriter) Close() error {
+	return w.rows.Flush()
+}
+
+// Close writer if it is not nil.
+func closeHashRecordWriter(writer hashRecordWriter) error {
+	if writer == nil {
+		return nil
+	}
+	return writer.Close()
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+)
+
+func TestHashRecordWriter(t *testing.T) {
+	records := []*HashRecord{
+		NewHashRecord("d/a.txt", []*hasher.HashResult{
+			{Algorithm: "md5", Size: 6, Hash: bytesFromHex("b1946ac92492d2347c6235b4d2611184")},
+			{Algorithm: "crc32", Size: 6, Hash: bytesFromHex("363a3020")},
+		}, false),
+		NewHashRecord("d/b, c.txt", []*hasher.HashResult{
+			{Algorithm: "md5", Size: 6, Hash: bytesFromHex("591785b794601e212b260e25925636fd")},
+			{Algorithm: "crc32", Size: 6, Hash: bytesFromHex("dd3861a8")},
+		}, false),
+	}
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"json", `[
+  {
+    "path": "d/a.txt",
+    "size": 6,
+    "hashes": {
+      "crc32": "363a3020",
+      "md5": "b1946ac92492d2347c6235b4d2611184"
+    }
+  },
+  {
+    "path": "d/b, c.txt",
+    "size": 6,
+    "hashes": {
+      "crc32": "dd3861a8",
+      "md5": "591785b794601e212b260e25925636fd"
+    }
+  }
+]
+`},
+		{"ndjson", `{"path":"d/a.txt","size":6,"hashes":{"crc32":"363a3020","md5":"b1946ac92492d2347c6235b4d2611184"}}
+{"path":"d/b, c.txt","size":6,"hashes":{"crc32":"dd3861a8","md5":"591785b794601e212b260e25925636fd"}}
+`},
+		{"csv", `path,size,md5,crc32
+d/a.txt,6,b1946ac92492d2347c6235b4d2611184,363a3020
+"d/b, c.txt",6,591785b794601e212b260e25925636fd,dd3861a8
+`},
+		{"table", `PATH        SIZE  MD5                               CRC32
+d/a.txt     6     b1946ac92492d2347c6235b4d2611184  363a3020
+d/b, c.txt  6     591785b794601e212b260e25925636fd  dd3861a8
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			writer, err := newHashRecordWriter(out, tt.format, []string{"md5", "crc32"}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range records {
+				if err := writer.Write(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("wrong output. expected '%s' actual '%s'", tt.expected, out.String())
+			}
+		})
+	}
+	if writer, err := newHashRecordWriter(&bytes.Buffer{}, "log", nil, false); writer != nil || err != nil {
+		t.Errorf("log format must not have writer")
+	}
+	if _, err := newHashRecordWriter(&bytes.Buffer{}, "xml", nil, false); err == nil {
+		t.Errorf("unsupported format is accepted")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+	"github.com/tforceaio/tf-unifiler-go/db"
+)
+
+// Keyed algorithm identifying files in metadata database when a key is used.
+const keyedMetadataAlgorithm = "hmac-sha256"
+
+// Struct KeyOptions contains sources of the key used by keyed algorithms.
+type KeyOptions struct {
+	Env  string
+	File string
+}
+
+// Load the key and use it for keyed algorithms. Nothing changes if no source is set.
+func (o *KeyOptions) Apply() error {
+	if o == nil {
+		return nil
+	}
+	key, err := hasher.LoadKey(o.File, o.Env)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		hasher.SetKey(key)
+	}
+	return nil
+}
+
+// Check whether sets were scanned using the current key, or without key if none is set.
+func checkSetKeys(sets []*db.Set) error {
+	fingerprint := hasher.CurrentKey().Fingerprint()
+	for _, set := range sets {
+		if set.KeyFingerprint != fingerprint {
+			return fmt.Errorf("collection '%s' was scanned using %s, but %s is used", set.Name, describeKey(set.KeyFingerprint), describeKey(fingerprint))
+		}
+	}
+	return nil
+}
+
+// Check whether files in workspace were scanned using the current key, or without key if none is set.
+func checkWorkspaceKey(ctx *db.DbContext) error {
+	fingerprint := hasher.CurrentKey().Fingerprint()
+	fingerprints, err := ctx.GetHashKeyFingerprints()
+	if err != nil {
+		return err
+	}
+	for _, fp := range fingerprints {
+		if fp != fingerprint {
+			return fmt.Errorf("workspace contains files scanned using %s, but %s is used", describeKey(fp), describeKey(fingerprint))
+		}
+	}
+	return nil
+}
+
+// Return description of a key by its fingerprint.
+func describeKey(fingerprint string) string {
+	if fingerprint == "" {
+		return "no key"
+	}
+	return "key " + fingerprint
+}
+
+// Define flags for KeyOptions.
+func addKeyFlags(cmd *cobra.Command) {
+	cmd.Flags().String("key-env", "", "Name of environment variable containing the key of keyed algorithms (hmac-sha256, keyed-blake2b).")
+	cmd.Flags().String("key-file", "", "File containing the key of keyed algorithms (hmac-sha256, keyed-blake2b).")
+}
+
+// Extract KeyOptions from a Cobra Command.
+func ParseKeyOptions(cmd *cobra.Command) *KeyOptions {
+	env, _ := cmd.Flags().GetString("key-env")
+	file, _ := cmd.Flags().GetString("key-file")
+
+	return &KeyOptions{
+		Env:  env,
+		File: file,
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tforce-io/tf-golib/opx"
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+	"github.com/tforceaio/tf-unifiler-go/db"
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+)
+
+func TestCheckSetKeys(t *testing.T) {
+	key := hasher.Key("0123456789abcdef0123456789abcdef")
+	fingerprint := key.Fingerprint()
+	tests := []struct {
+		group   string
+		key     hasher.Key
+		sets    []*db.Set
+		wantErr bool
+	}{
+		{"no_key", nil, []*db.Set{{Name: "a"}, {Name: "b"}}, false},
+		{"same_key", key, []*db.Set{{Name: "a", KeyFingerprint: fingerprint}}, false},
+		{"missing_key", nil, []*db.Set{{Name: "a", KeyFingerprint: fingerprint}}, true},
+		{"unexpected_key", key, []*db.Set{{Name: "a"}}, true},
+		{"mixed_sets", key, []*db.Set{{Name: "a", KeyFingerprint: fingerprint}, {Name: "b"}}, true},
+	}
+	defer hasher.SetKey(nil)
+	for _, tt := range tests {
+		t.Run(tt.group, func(t *testing.T) {
+			hasher.SetKey(tt.key)
+			err := checkSetKeys(tt.sets)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error. expected %v actual %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckWorkspaceKey(t *testing.T) {
+	key := hasher.Key("0123456789abcdef0123456789abcdef")
+	fingerprint := key.Fingerprint()
+	tests := []struct {
+		group   string
+		key     hasher.Key
+		saved   []string
+		wantErr bool
+	}{
+		{"empty_workspace", key, []string{}, false},
+		{"no_key", nil, []string{"", ""}, false},
+		{"same_key", key, []string{fingerprint}, false},
+		{"missing_key", nil, []string{fingerprint}, true},
+		{"unexpected_key", key, []string{""}, true},
+		{"mixed_keys", key, []string{fingerprint, ""}, true},
+	}
+	defer hasher.SetKey(nil)
+	for _, tt := range tests {
+		t.Run(tt.group, func(t *testing.T) {
+			ctx, err := db.Connect(filepath.Join(t.TempDir(), "metadata.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			hashes := make([]*db.Hash, len(tt.saved))
+			for i, fp := range tt.saved {
+				hashes[i] = &db.Hash{Sha256: fmt.Sprintf("%064d", i), KeyFingerprint: fp}
+			}
+			if err := ctx.SaveHashes(hashes); err != nil {
+				t.Fatal(err)
+			}
+			hasher.SetKey(tt.key)
+			err = checkWorkspaceKey(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error. expected %v actual %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifyKeyedChecksumFile(t *testing.T) {
+	key := hasher.Key("0123456789abcdef0123456789abcdef")
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("hello\n"))
+	digest := hex.EncodeToString(mac.Sum(nil))
+	tests := []struct {
+		group   string
+		key     hasher.Key
+		lines   []string
+		wantErr bool
+	}{
+		{"gnu_no_key", nil, []string{"0000 *a.txt"}, true},
+		{"bsd_no_key", nil, []string{"HMAC-SHA256 (a.txt) = 0000"}, true},
+		{"gnu_with_key", key, []string{digest + " *a.txt"}, false},
+		{"bsd_with_key", key, []string{"HMAC-SHA256 (a.txt) = " + digest}, false},
+		{"gnu_wrong_hash", key, []string{"0000 *a.txt"}, true},
+	}
+	defer hasher.SetKey(nil)
+	for _, tt := range tests {
+		t.Run(tt.group, func(t *testing.T) {
+			hasher.SetKey(tt.key)
+			dir := t.TempDir()
+			filesystem.WriteLines(filepath.Join(dir, "a.txt"), []string{"hello"})
+			name := opx.Ternary(strings.HasPrefix(tt.lines[0], "HMAC"), "checksum.sum", "checksum.hmac-sha256")
+			filesystem.WriteLines(filepath.Join(dir, name), tt.lines)
+
+			module := &ChecksumModule{
+				logger: log.Logger,
+			}
+			err := module.Verify([]string{dir}, "", "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error. expected %v actual %v", tt.wantErr, err)
+			}
+			if err != nil && tt.key == nil && !strings.Contains(err.Error(), "requires a key") {
+				t.Errorf("unexpected error message: %v", err)
+			}
+		})
+	}
+}
@@ -18,12 +18,14 @@ package engine
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/tforce-io/tf-golib/opx"
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
 	"github.com/tforceaio/tf-unifiler-go/filesystem"
 	"github.com/tforceaio/tf-unifiler-go/filesystem/exec"
 )
@@ -57,10 +59,25 @@ func version() string {
 	return fmt.Sprintf("%d.%d.%s.%d", majorVersion, minor, patch, duration.Milliseconds()/int64(86400000))
 }
 
+// Return usage text listing supported hash algorithms for command flags.
+func hashAlgorithmsUsage() string {
+	return fmt.Sprintf("Supported algorithms: %s. Output length of SHAKE can be set in bits, e.g. shake256-1024. Keyed algorithms (hmac-sha256, keyed-blake2b) require a key.", strings.Join(hasher.Names(), ", "))
+}
+
 // Initialize configurations, loggings for internal modules, and display basic
 // information about this invocation.
 func InitApp() *Controller {
-	cfg := NewController(true)
+	return initApp(os.Stdout)
+}
+
+// Same as InitApp, but console logs are written to stderr so stdout only contains
+// outputs meant to be consumed by other programs.
+func InitAppStderr() *Controller {
+	return initApp(os.Stderr)
+}
+
+func initApp(consoleOut io.Writer) *Controller {
+	cfg := NewController(true, consoleOut)
 
 	filesystem.SetLogger(cfg.ModuleLogger("filesystem"))
 	exec.SetLogger(cfg.ModuleLogger("exec"))
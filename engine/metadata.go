@@ -66,6 +66,7 @@ func (m *MetadataModule) Refine(workspaceDir string, inputs, collections []strin
 		Bool("erase", erase).
 		Strs("files", inputs).
 		Bool("invert", invert).
+		Str("keyFingerprint", hasher.CurrentKey().Fingerprint()).
 		Bool("onlyObsoleted", onlyObsoleted).
 		Str("workspace", workspaceDir).
 		Msg("Start refining file system.")
@@ -79,32 +80,37 @@ func (m *MetadataModule) Refine(workspaceDir string, inputs, collections []strin
 	if err != nil {
 		return err
 	}
+	sets, err := ctx.GetSetsByNames(collections)
+	if err != nil {
+		return err
+	}
+	if err := checkSetKeys(sets); err != nil {
+		return err
+	}
+	if err := checkWorkspaceKey(ctx); err != nil {
+		return err
+	}
+
+	// fingerprint can only rule out known files if all of them have one.
+	missingCount, err := ctx.CountHashesWithoutFingerprint()
+	if err != nil {
+		return err
+	}
+	useFingerprint := missingCount == 0
+	if !useFingerprint {
+		m.logger.Info().
+			Int64("count", missingCount).
+			Msg("Some files in database have no fingerprint. Run scan again to enable fingerprint pre-filter.")
+	}
 
-	algos := []string{"md5", "sha1", "sha256", "sha512"}
 	for _, c := range contents {
 		if c.IsDir {
 			continue
 		}
-		fhResults, err := hasher.Hash(c.RelativePath, algos)
-		if err != nil {
-			m.logger.Info().
-				Str("path", c.RelativePath).
-				Msg("Failed to compute hash.")
-			return err
-		}
-		sha256 := hex.EncodeToString(fhResults[2].Hash)
-		m.logger.Info().
-			Str("md5", hex.EncodeToString(fhResults[0].Hash)).
-			Str("path", c.RelativePath).
-			Str("sha1", hex.EncodeToString(fhResults[1].Hash)).
-			Str("sha256", sha256).
-			Int("size", fhResults[0].Size).
-			Msg("Hashed file.")
-		metadatas, err := ctx.GetHashesInSets(collections, []string{sha256}, onlyObsoleted)
+		noMetadata, err := m.isUnknownFile(ctx, c.RelativePath, collections, onlyObsoleted, useFingerprint)
 		if err != nil {
 			return err
 		}
-		noMetadata := len(metadatas) == 0
 		if invert == noMetadata {
 			newFile := strfmt.NewPathFromStr(c.AbsolutePath)
 			intDir := opx.Ternary(invert, ".extra", ".backup")
@@ -137,10 +143,65 @@ func (m *MetadataModule) Refine(workspaceDir string, inputs, collections []strin
 	return nil
 }
 
+// Check whether a file doesn't exist in metadata database. If useFingerprint is true, quick fingerprint of large files
+// is checked first so full hashes are only computed when there are known files having it.
+func (m *MetadataModule) isUnknownFile(ctx *db.DbContext, fPath string, collections []string, onlyObsoleted, useFingerprint bool) (bool, error) {
+	// small files are read entirely by fingerprint, so they are hashed right away instead.
+	fi, err := os.Stat(fPath)
+	if err != nil {
+		return false, err
+	}
+	if useFingerprint && fi.Size() > hasher.DefaultSampleSize*3 {
+		fingerprint, err := hasher.Fingerprint(fPath, hasher.DefaultSampleSize)
+		if err != nil {
+			m.logger.Info().
+				Str("path", fPath).
+				Msg("Failed to compute fingerprint.")
+			return false, err
+		}
+		fingerprintHex := hex.EncodeToString(metadataFingerprint(fingerprint.Hash))
+		candidates, err := ctx.GetHashesByFingerprints([]string{fingerprintHex})
+		if err != nil {
+			return false, err
+		}
+		if len(candidates) == 0 {
+			m.logger.Info().
+				Str("fingerprint", fingerprintHex).
+				Str("path", fPath).
+				Int64("size", fingerprint.Size).
+				Msg("Skipped hashing. Fingerprint is unknown.")
+			return true, nil
+		}
+	}
+
+	fhResults, err := hasher.Hash(fPath, metadataAlgorithms())
+	if err != nil {
+		m.logger.Info().
+			Str("path", fPath).
+			Msg("Failed to compute hash.")
+		return false, err
+	}
+	fileMultiHash := newFileMultiHash(fhResults, nil, "")
+	sha256 := fileMultiHash.Sha256.HexStr()
+	m.logger.Info().
+		Str("md5", fileMultiHash.Md5.HexStr()).
+		Str("path", fPath).
+		Str("sha1", fileMultiHash.Sha1.HexStr()).
+		Str("sha256", sha256).
+		Int64("size", fhResults[0].Size).
+		Msg("Hashed file.")
+	metadatas, err := ctx.GetHashesInSets(collections, []string{sha256}, onlyObsoleted)
+	if err != nil {
+		return false, err
+	}
+	return len(metadatas) == 0, nil
+}
+
 // Scan and compute hashes using common algorithms (MD5, SHA-1, SHA-256, SHA-512) for inputs (files/folders)
-// and add them to collection.
+// and add them to collection. If a key is set, files are identified by their HMAC-SHA256 instead,
+// and collections must have been scanned using the same key.
 // Mark them as obseleted if delete is true.
-func (m *MetadataModule) Scan(workspaceDir string, inputs, collections []string, delete bool) error {
+func (m *MetadataModule) Scan(workspaceDir string, inputs, collections []string, delete bool, opts *HashOptions) error {
 	if workspaceDir == "" {
 		return errors.New("workspace is not set")
 	} else if !filesystem.IsDirectoryExist(workspaceDir) {
@@ -156,48 +217,76 @@ func (m *MetadataModule) Scan(workspaceDir string, inputs, collections []string,
 		Strs("collections", collections).
 		Bool("delete", delete).
 		Strs("files", inputs).
+		Str("keyFingerprint", hasher.CurrentKey().Fingerprint()).
 		Str("workspace", workspaceDir).
 		Msg("Start scanning files metadata.")
 
+	dbFile := MetadataWorkspaceDatabase(workspaceDir)
+	ctx, err := db.Connect(dbFile)
+	if err != nil {
+		return err
+	}
+	sets, err := ctx.GetSetsByNames(collections)
+	if err != nil {
+		return err
+	}
+	if err := checkSetKeys(sets); err != nil {
+		return err
+	}
+	if err := checkWorkspaceKey(ctx); err != nil {
+		return err
+	}
+
 	contents, err := filesystem.List(inputs, true)
 	if err != nil {
 		return err
 	}
 
-	hResults := []*core.FileMultiHash{}
-	algos := []string{"md5", "sha1", "sha256", "sha512"}
+	files := []*filesystem.FsEntry{}
+	fPaths := []string{}
 	for _, c := range contents {
-		if c.IsDir {
-			continue
+		if !c.IsDir {
+			files = append(files, c)
+			fPaths = append(fPaths, c.RelativePath)
 		}
-		fhResults, err := hasher.Hash(c.RelativePath, algos)
+	}
+	hResults := []*core.FileMultiHash{}
+	algos := metadataAlgorithms()
+	// fingerprint is computed in the same pass, so files are only read once.
+	passAlgos := append(append([]string{}, algos...), hasher.FingerprintAlgorithm)
+	err = hashFiles(m.logger, fPaths, passAlgos, workspaceDir, opts, func(i int, fhResults []*hasher.HashResult, err error) error {
 		if err != nil {
 			m.logger.Info().
-				Str("path", c.RelativePath).
+				Str("path", fPaths[i]).
 				Msg("Failed to compute hash.")
 			return err
 		}
 		m.logger.Info().
 			Strs("algos", algos).
-			Str("path", c.RelativePath).
-			Int("size", fhResults[0].Size).
+			Str("path", fPaths[i]).
+			Int64("size", fhResults[0].Size).
 			Msg("Hashed file.")
-		fileMultiHash := &core.FileMultiHash{
-			Md5:      fhResults[0].Hash,
-			Sha1:     fhResults[1].Hash,
-			Sha256:   fhResults[2].Hash,
-			Sha512:   fhResults[3].Hash,
-			Size:     uint32(fhResults[0].Size),
-			FileName: c.Name,
+		fingerprint := hasher.FindResult(fhResults, hasher.FingerprintAlgorithm)
+		fileMultiHash := newFileMultiHash(fhResults, fingerprint.Hash, files[i].Name)
+		if opts != nil && opts.BlockSize > 0 {
+			blockMap, err := hasher.NewBlockMap(fhResults[len(fhResults)-1], opts.BlockSize)
+			if err != nil {
+				return err
+			}
+			if err := WriteWorkspaceBlockMap(workspaceDir, fileMultiHash.Sha256.HexStr(), fPaths[i], blockMap); err != nil {
+				m.logger.Info().
+					Str("path", fPaths[i]).
+					Msg("Failed to write block map.")
+				return err
+			}
 		}
 		hResults = append(hResults, fileMultiHash)
-	}
-
-	dbFile := MetadataWorkspaceDatabase(workspaceDir)
-	ctx, err := db.Connect(dbFile)
+		return nil
+	})
 	if err != nil {
 		return err
 	}
+
 	err = m.saveHResults(ctx, hResults, delete, collections)
 	if err != nil {
 		return err
@@ -363,6 +452,7 @@ func (m *MetadataModule) saveHResults(ctx *db.DbContext, hResults []*core.FileMu
 	hashes := make([]*db.Hash, len(hResults))
 	for i, res := range hResults {
 		hashes[i] = db.NewHash(res, ignore)
+		hashes[i].KeyFingerprint = hasher.CurrentKey().Fingerprint()
 		hashes[i].SessionID = sessionID
 	}
 	err = ctx.SaveHashes(hashes)
@@ -400,6 +490,7 @@ func (m *MetadataModule) saveHResults(ctx *db.DbContext, hResults []*core.FileMu
 		sets := make([]*db.Set, len(collections))
 		for i, name := range collections {
 			sets[i] = db.NewSet(name)
+			sets[i].KeyFingerprint = hasher.CurrentKey().Fingerprint()
 			sets[i].SessionID = sessionID
 		}
 		err = ctx.SaveSets(sets)
@@ -432,6 +523,44 @@ func (m *MetadataModule) saveHResults(ctx *db.DbContext, hResults []*core.FileMu
 	return err
 }
 
+// Return algorithms identifying files in metadata database, which are common algorithms,
+// or the keyed algorithm only if a key is set so no plain digest is stored.
+func metadataAlgorithms() []string {
+	if hasher.CurrentKey() != nil {
+		return []string{keyedMetadataAlgorithm}
+	}
+	return hasher.CommonAlgorithms()
+}
+
+// Return quick fingerprint to be stored in metadata database, which is keyed if a key is set.
+func metadataFingerprint(fingerprint []byte) []byte {
+	if key := hasher.CurrentKey(); key != nil {
+		return key.Sum(fingerprint)[:len(fingerprint)]
+	}
+	return fingerprint
+}
+
+// Return FileMultiHash from results computed using metadataAlgorithms. If a key is set,
+// the keyed digest takes place of SHA-256 and other digests are left empty.
+func newFileMultiHash(fhResults []*hasher.HashResult, fingerprint []byte, fileName string) *core.FileMultiHash {
+	fileMultiHash := &core.FileMultiHash{
+		Size:     uint32(fhResults[0].Size),
+		FileName: fileName,
+	}
+	if fingerprint != nil {
+		fileMultiHash.Fingerprint = metadataFingerprint(fingerprint)
+	}
+	if hasher.CurrentKey() != nil {
+		fileMultiHash.Sha256 = hasher.FindResult(fhResults, keyedMetadataAlgorithm).Hash
+		return fileMultiHash
+	}
+	fileMultiHash.Md5 = hasher.FindResult(fhResults, "md5").Hash
+	fileMultiHash.Sha1 = hasher.FindResult(fhResults, "sha1").Hash
+	fileMultiHash.Sha256 = hasher.FindResult(fhResults, "sha256").Hash
+	fileMultiHash.Sha512 = hasher.FindResult(fhResults, "sha512").Hash
+	return fileMultiHash
+}
+
 // Return database path to store Metadata module's ouputs inside Unifiler workspace.
 func MetadataWorkspaceDatabase(workspaceDir string) string {
 	return filepath.Join(workspaceDir, ".unifiler", "metadata.db")
@@ -452,6 +581,10 @@ func MetadataCmd() *cobra.Command {
 			defer c.Close()
 			flags := ParseMetadataFlags(cmd, args)
 			m := NewMetadataModule(c, "scan")
+			if err := flags.KeyOptions.Apply(); err != nil {
+				m.logError(err)
+				return
+			}
 			m.logError(m.Refine(flags.WorkspaceDir, flags.Inputs, flags.Collections, flags.OnlyObsoleted, flags.Invert, flags.Erase))
 		},
 	}
@@ -461,6 +594,7 @@ func MetadataCmd() *cobra.Command {
 	refineCmd.Flags().Bool("invert", false, "Take action on non-matched files instead of matched ones.")
 	refineCmd.Flags().BoolP("obsoleted", "o", false, "Only match obsoleted files.")
 	refineCmd.Flags().StringP("workspace", "w", "", "Directory contains Unifiler workspace.")
+	addKeyFlags(refineCmd)
 	rootCmd.AddCommand(refineCmd)
 
 	scanCmd := &cobra.Command{
@@ -471,13 +605,20 @@ func MetadataCmd() *cobra.Command {
 			defer c.Close()
 			flags := ParseMetadataFlags(cmd, args)
 			m := NewMetadataModule(c, "scan")
-			m.logError(m.Scan(flags.WorkspaceDir, flags.Inputs, flags.Collections, flags.Deleted))
+			if err := flags.KeyOptions.Apply(); err != nil {
+				m.logError(err)
+				return
+			}
+			m.logError(m.Scan(flags.WorkspaceDir, flags.Inputs, flags.Collections, flags.Deleted, flags.HashOptions))
 		},
 	}
 	scanCmd.Flags().StringSliceP("collections", "c", []string{}, "Names of collections of known files, comma-separated list supported. If a collection existed, files will be appended to that collection.")
 	scanCmd.Flags().Bool("delete", false, "Mark the inputs as obsoleted.")
 	scanCmd.Flags().StringArrayP("inputs", "i", []string{}, "Files/Directories to hash.")
 	scanCmd.Flags().StringP("workspace", "w", "", "Directory contains Unifiler workspace.")
+	addBlockMapFlag(scanCmd)
+	addHashFlags(scanCmd)
+	addKeyFlags(scanCmd)
 	rootCmd.AddCommand(scanCmd)
 
 	rootCmd.AddCommand(metadataQueryCmd())
@@ -546,10 +687,12 @@ type MetadataFlags struct {
 	Collections   []string
 	Deleted       bool
 	Erase         bool
+	HashOptions   *HashOptions
 	Hashes        []string
 	ID            string
 	Inputs        []string
 	Invert        bool
+	KeyOptions    *KeyOptions
 	Name          string
 	OnlyObsoleted bool
 	WorkspaceDir  string
@@ -573,10 +716,12 @@ func ParseMetadataFlags(cmd *cobra.Command, args []string) *MetadataFlags {
 		Collections:   collections,
 		Deleted:       deleted,
 		Erase:         erase,
+		HashOptions:   ParseHashOptions(cmd),
 		Hashes:        hashes,
 		ID:            id,
 		Inputs:        inputs,
 		Invert:        invert,
+		KeyOptions:    ParseKeyOptions(cmd),
 		Name:          name,
 		OnlyObsoleted: obsoleted,
 		WorkspaceDir:  workspaceDir,
@@ -131,7 +131,7 @@ func (m *MirrorModule) Export(workspaceDir, checksumFile, targetDir string) erro
 
 // Scan and calculate SHA-256 hashes for inputs (files/folders),
 // then create hardlink to workspaceDir.
-func (m *MirrorModule) Scan(workspaceDir string, inputs []string) error {
+func (m *MirrorModule) Scan(workspaceDir string, inputs []string, opts *HashOptions) error {
 	if workspaceDir == "" {
 		return errors.New("workspace is not set")
 	} else if !filesystem.IsDirectoryExist(workspaceDir) {
@@ -151,25 +151,33 @@ func (m *MirrorModule) Scan(workspaceDir string, inputs []string) error {
 		return err
 	}
 
-	hResults := []*hasher.HashResult{}
+	files := []*filesystem.FsEntry{}
+	fPaths := []string{}
 	for _, c := range contents {
-		if c.IsDir {
-			continue
+		if !c.IsDir {
+			files = append(files, c)
+			fPaths = append(fPaths, c.RelativePath)
 		}
-		fhResult, err := hasher.HashSha256(c.RelativePath)
+	}
+	hResults := []*hasher.HashResult{}
+	err = hashFiles(m.logger, fPaths, []string{"sha256"}, workspaceDir, opts, func(i int, fhResults []*hasher.HashResult, err error) error {
 		if err != nil {
 			m.logger.Info().
-				Str("path", c.RelativePath).
+				Str("path", fPaths[i]).
 				Msg("Failed to compute hash.")
 			return err
 		}
 		m.logger.Info().
 			Str("algo", "sha256").
-			Str("path", c.RelativePath).
-			Int("size", fhResult.Size).
+			Str("path", fPaths[i]).
+			Int64("size", fhResults[0].Size).
 			Msg("Hashed file.")
-		fhResult.Path = c.AbsolutePath
-		hResults = append(hResults, fhResult)
+		fhResults[0].Path = files[i].AbsolutePath
+		hResults = append(hResults, fhResults[0])
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	mappings := []*FileMirrorMapping{}
@@ -267,11 +275,12 @@ func MirrorCmd() *cobra.Command {
 			defer c.Close()
 			flags := ParseMirrorFlags(cmd)
 			m := NewMirrorModule(c, "export")
-			m.logError(m.Scan(flags.WorkspaceDir, flags.Inputs))
+			m.logError(m.Scan(flags.WorkspaceDir, flags.Inputs, flags.HashOptions))
 		},
 	}
 	scanCmd.Flags().StringSliceP("inputs", "i", []string{}, "Files/Directories to import.")
 	scanCmd.Flags().StringP("workspace", "w", "", "Directory contains Unifiler workspace.")
+	addHashFlags(scanCmd)
 	rootCmd.AddCommand(scanCmd)
 
 	return rootCmd
@@ -280,6 +289,7 @@ func MirrorCmd() *cobra.Command {
 // Struct MirrorFlags contains all flags used by Mirror module.
 type MirrorFlags struct {
 	ChecksumFile string
+	HashOptions  *HashOptions
 	Inputs       []string
 	Output       string
 	WorkspaceDir string
@@ -294,6 +304,7 @@ func ParseMirrorFlags(cmd *cobra.Command) *MirrorFlags {
 
 	return &MirrorFlags{
 		ChecksumFile: checksumFile,
+		HashOptions:  ParseHashOptions(cmd),
 		Inputs:       inputs,
 		Output:       output,
 		WorkspaceDir: workspaceDir,
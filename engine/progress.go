@@ -0,0 +1,160 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+)
+
+// Minimum interval between two redraws of progress line.
+const progressRedrawInterval = 200 * time.Millisecond
+
+// Struct hashProgress renders progress of a hashing run as a single line, which is redrawn in place.
+// All methods are safe for concurrent use and no-op on nil receiver.
+type hashProgress struct {
+	mu        sync.Mutex
+	out       io.Writer
+	active    map[string]*hasher.Progress
+	fileCount int
+	fileDone  int
+	bytesDone int64
+	start     time.Time
+	drawn     time.Time
+	width     int
+}
+
+// Return new hashProgress which writes to out, or nil if enabled is false.
+func newHashProgress(out io.Writer, fileCount int, enabled bool) *hashProgress {
+	if !enabled {
+		return nil
+	}
+	return &hashProgress{
+		out:       out,
+		active:    map[string]*hasher.Progress{},
+		fileCount: fileCount,
+		start:     time.Now(),
+	}
+}
+
+// Return callback to be passed to hasher, or nil if progress is disabled.
+func (p *hashProgress) Func() hasher.ProgressFunc {
+	if p == nil {
+		return nil
+	}
+	return p.Update
+}
+
+// Record progress of a file and redraw if needed.
+func (p *hashProgress) Update(prog *hasher.Progress) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active[prog.Path] = prog
+	if time.Since(p.drawn) >= progressRedrawInterval {
+		p.draw()
+	}
+}
+
+// Mark a file as finished and erase progress line, so log lines can be printed cleanly.
+func (p *hashProgress) Done(fPath string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if prog, ok := p.active[fPath]; ok {
+		p.bytesDone += prog.Done
+		delete(p.active, fPath)
+	}
+	p.fileDone++
+	p.clear()
+}
+
+// Erase progress line.
+func (p *hashProgress) Clear() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+}
+
+func (p *hashProgress) clear() {
+	if p.width > 0 {
+		fmt.Fprintf(p.out, "\r%s\r", strings.Repeat(" ", p.width))
+		p.width = 0
+	}
+}
+
+func (p *hashProgress) draw() {
+	// files finished hashing stay in active until they are delivered in listing order.
+	done := p.bytesDone
+	finished := p.fileDone
+	running := []*hasher.Progress{}
+	for _, prog := range p.active {
+		done += prog.Done
+		if prog.Total >= 0 && prog.Done >= prog.Total {
+			finished++
+		} else {
+			running = append(running, prog)
+		}
+	}
+	rate := float64(0)
+	if elapsed := time.Since(p.start).Seconds(); elapsed > 0 {
+		rate = float64(done) / elapsed
+	}
+	line := fmt.Sprintf("[%d/%d] %s hashed, %s/s", finished, p.fileCount, formatSize(done), formatSize(int64(rate)))
+	if len(running) == 1 {
+		line += " | " + filepath.Base(running[0].Path)
+		if running[0].Total > 0 {
+			line += fmt.Sprintf(" %.1f%%", float64(running[0].Done)*100/float64(running[0].Total))
+		}
+	} else if len(running) > 1 {
+		line += fmt.Sprintf(" | %d files in progress", len(running))
+	}
+	padding := ""
+	if len(line) < p.width {
+		padding = strings.Repeat(" ", p.width-len(line))
+	}
+	fmt.Fprintf(p.out, "\r%s%s", line, padding)
+	p.width = len(line)
+	p.drawn = time.Now()
+}
+
+// Return human readable size using binary prefixes.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
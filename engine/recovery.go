@@ -0,0 +1,338 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/tforce-io/tf-golib/opx"
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+	"github.com/tforceaio/tf-unifiler-go/par2"
+)
+
+// RecoveryModule handles user requests related to PAR2 recovery files creation, verification and repair.
+type RecoveryModule struct {
+	logger zerolog.Logger
+}
+
+// Return new RecoveryModule.
+func NewRecoveryModule(c *Controller, cmdName string) *RecoveryModule {
+	return &RecoveryModule{
+		logger: c.CommandLogger("recovery", cmdName),
+	}
+}
+
+// Create PAR2 recovery files for inputs. Directories will be searched recursively.
+// If output is empty, recovery.par2 in working directory is used. Inputs must be inside
+// directory of output since names of files are stored relative to it.
+func (m *RecoveryModule) Create(inputs []string, output string, opts *par2.CreateOptions) error {
+	if len(inputs) == 0 {
+		return errors.New("inputs is empty")
+	}
+	output = opx.Ternary(output == "", "recovery.par2", output)
+	if !strings.EqualFold(filepath.Ext(output), ".par2") {
+		output += ".par2"
+	}
+	m.logger.Info().
+		Strs("files", inputs).
+		Str("output", output).
+		Int("redundancy", opts.Redundancy).
+		Int64("sliceSize", opts.SliceSize).
+		Msg("Start creating recovery files.")
+
+	contents, err := filesystem.List(inputs, true)
+	if err != nil {
+		return err
+	}
+	fPaths := []string{}
+	for _, c := range contents {
+		if !c.IsDir {
+			fPaths = append(fPaths, c.RelativePath)
+		}
+	}
+	s, written, err := par2.Create(fPaths, output, opts, func(localPath string, f *par2.File) {
+		m.logger.Info().
+			Str("path", localPath).
+			Int64("size", f.Length).
+			Msg("Hashed file.")
+	})
+	if err != nil {
+		return err
+	}
+	for _, w := range written {
+		m.logger.Info().
+			Str("path", w).
+			Msg("Written recovery file.")
+	}
+	m.logger.Info().
+		Int("fileCount", len(s.Files)).
+		Int("recoverySlices", len(s.Recovery)).
+		Str("setID", hex.EncodeToString(s.ID)).
+		Int("slices", s.SliceCount()).
+		Int64("sliceSize", s.SliceSize).
+		Msg("Created recovery set.")
+	return nil
+}
+
+// Verify files protected by PAR2 recovery files of inputs, then report whether damaged files
+// can be repaired. Files are resolved relative to directory of each recovery file.
+func (m *RecoveryModule) Verify(inputs []string) error {
+	if len(inputs) == 0 {
+		return errors.New("inputs is empty")
+	}
+	m.logger.Info().
+		Strs("files", inputs).
+		Msg("Start verifying recovery files.")
+
+	result := &checksumVerifyResult{}
+	for _, input := range inputs {
+		s, results, err := m.verifySet(input, result)
+		if err != nil {
+			return err
+		}
+		if lost := lostSlices(results); lost > 0 {
+			event := opx.Ternary(lost > len(s.Recovery), m.logger.Warn(), m.logger.Info())
+			event.
+				Int("lostSlices", lost).
+				Str("path", input).
+				Int("recoverySlices", len(s.Recovery)).
+				Msg(opx.Ternary(lost > len(s.Recovery), "Not enough recovery slices to repair.", "Damaged files can be repaired."))
+		}
+	}
+
+	m.logger.Info().
+		Int("failed", result.Failed).
+		Int("missing", result.Missing).
+		Int("ok", result.OK).
+		Int("total", result.OK+result.Failed+result.Missing).
+		Msgf("Verified %d file(s). %d OK, %d FAILED, %d MISSING.", result.OK+result.Failed+result.Missing, result.OK, result.Failed, result.Missing)
+	if result.Failed > 0 || result.Missing > 0 {
+		return errors.New("recovery verification failed")
+	}
+	return nil
+}
+
+// Repair damaged and missing files protected by PAR2 recovery files of inputs.
+func (m *RecoveryModule) Repair(inputs []string) error {
+	if len(inputs) == 0 {
+		return errors.New("inputs is empty")
+	}
+	m.logger.Info().
+		Strs("files", inputs).
+		Msg("Start repairing files.")
+
+	repaired := 0
+	for _, input := range inputs {
+		s, results, err := m.verifySet(input, &checksumVerifyResult{})
+		if err != nil {
+			return err
+		}
+		if !hasDamagedFile(results) {
+			m.logger.Info().
+				Str("path", input).
+				Msg("All files are intact.")
+			continue
+		}
+		err = par2.Repair(s, results, func(r *par2.VerifyResult) error {
+			repaired++
+			m.logger.Info().
+				Str("path", r.LocalPath).
+				Int64("size", r.File.Length).
+				Msg("Repaired file.")
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot repair files of '%s': %w", input, err)
+		}
+	}
+
+	m.logger.Info().
+		Int("repaired", repaired).
+		Msgf("Repaired %d file(s).", repaired)
+	return nil
+}
+
+// Read a recovery set and verify its files, statuses are accumulated to result.
+func (m *RecoveryModule) verifySet(input string, result *checksumVerifyResult) (*par2.RecoverySet, []*par2.VerifyResult, error) {
+	s, err := par2.Open(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid recovery file '%s': %w", input, err)
+	}
+	m.logger.Info().
+		Str("creator", s.Creator).
+		Int("fileCount", len(s.Files)).
+		Str("path", input).
+		Int("recoverySlices", len(s.Recovery)).
+		Int64("sliceSize", s.SliceSize).
+		Msg("Parsed recovery files.")
+
+	results, err := par2.Verify(s, filepath.Dir(input), func(r *par2.VerifyResult) error {
+		switch r.Status {
+		case par2.StatusOK:
+			result.OK++
+			m.logger.Info().
+				Str("path", r.LocalPath).
+				Str("status", r.Status).
+				Msg("Verified file.")
+		case par2.StatusMissing:
+			result.Missing++
+			m.logger.Warn().
+				Str("path", r.LocalPath).
+				Str("status", r.Status).
+				Msg("File not found.")
+		default:
+			result.Failed++
+			m.logger.Warn().
+				Int("badSlices", len(r.BadSlices)).
+				Str("path", r.LocalPath).
+				Str("status", r.Status).
+				Msg("Content mismatch.")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, results, nil
+}
+
+// Return number of slices which need to be recovered.
+func lostSlices(results []*par2.VerifyResult) int {
+	lost := 0
+	for _, r := range results {
+		lost += len(r.BadSlices)
+	}
+	return lost
+}
+
+// Return whether any file is not intact.
+func hasDamagedFile(results []*par2.VerifyResult) bool {
+	for _, r := range results {
+		if r.Status != par2.StatusOK {
+			return true
+		}
+	}
+	return false
+}
+
+// Decorator to log error occurred when calling handlers.
+func (m *RecoveryModule) logError(err error) {
+	if err != nil {
+		m.logger.Err(err).Msg("Unexpected error has occurred. Program will exit.")
+	}
+}
+
+// Define Cobra Command for Recovery module.
+func RecoveryCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "recovery",
+		Short: "Create PAR2 recovery files, verify and repair files using them.",
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create <input>...",
+		Short: "Create PAR2 recovery files for files and directories.",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := InitApp()
+			defer c.Close()
+			flags := ParseRecoveryFlags(cmd, args)
+			m := NewRecoveryModule(c, "create")
+			opts := &par2.CreateOptions{
+				Creator:    "Created by TF Unifiler v" + version(),
+				Redundancy: flags.Redundancy,
+				SliceSize:  flags.SliceSize * 1024,
+			}
+			m.logError(m.Create(flags.Inputs, flags.Output, opts))
+		},
+	}
+	createCmd.Flags().StringArrayP("inputs", "i", []string{}, "Files/Directories to protect.")
+	createCmd.Flags().StringP("output", "o", "", "Path of the index recovery file, recovery volumes are written next to it. Inputs must be inside its directory. Defaults to recovery.par2 in working directory.")
+	createCmd.Flags().IntP("redundancy", "r", par2.DefaultRedundancy, "Number of recovery slices in percentage of input slices, from 0 to 100.")
+	createCmd.Flags().Int64("slice-size", 0, "Slice size in KiB. Chosen automatically if it is 0.")
+	rootCmd.AddCommand(createCmd)
+
+	repairCmd := &cobra.Command{
+		Use:   "repair <input>...",
+		Short: "Repair damaged and missing files using PAR2 recovery files.",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := InitApp()
+			defer c.Close()
+			flags := ParseRecoveryFlags(cmd, args)
+			m := NewRecoveryModule(c, "repair")
+			err := m.Repair(flags.Inputs)
+			m.logError(err)
+			if err != nil {
+				c.Close()
+				os.Exit(1)
+			}
+		},
+	}
+	repairCmd.Flags().StringArrayP("inputs", "i", []string{}, "PAR2 files of recovery sets.")
+	rootCmd.AddCommand(repairCmd)
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify <input>...",
+		Short: "Verify files using PAR2 recovery files.",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := InitApp()
+			defer c.Close()
+			flags := ParseRecoveryFlags(cmd, args)
+			m := NewRecoveryModule(c, "verify")
+			err := m.Verify(flags.Inputs)
+			m.logError(err)
+			if err != nil {
+				c.Close()
+				os.Exit(1)
+			}
+		},
+	}
+	verifyCmd.Flags().StringArrayP("inputs", "i", []string{}, "PAR2 files of recovery sets.")
+	rootCmd.AddCommand(verifyCmd)
+
+	return rootCmd
+}
+
+// Struct RecoveryFlags contains all flags used by Recovery module.
+type RecoveryFlags struct {
+	Inputs     []string
+	Output     string
+	Redundancy int
+	SliceSize  int64
+}
+
+// Extract all flags from a Cobra Command.
+func ParseRecoveryFlags(cmd *cobra.Command, args []string) *RecoveryFlags {
+	inputs, _ := cmd.Flags().GetStringArray("inputs")
+	output, _ := cmd.Flags().GetString("output")
+	redundancy, _ := cmd.Flags().GetInt("redundancy")
+	sliceSize, _ := cmd.Flags().GetInt64("slice-size")
+	inputs = append(args, inputs...)
+
+	return &RecoveryFlags{
+		Inputs:     inputs,
+		Output:     output,
+		Redundancy: redundancy,
+		SliceSize:  sliceSize,
+	}
+}
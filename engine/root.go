@@ -45,6 +45,8 @@ Licensed under GPL-3.0 license. See COPYING file along with this program for mor
 	rootCmd.AddCommand(FileCmd())
 	rootCmd.AddCommand(MetadataCmd())
 	rootCmd.AddCommand(MirrorCmd())
+	rootCmd.AddCommand(RecoveryCmd())
+	rootCmd.AddCommand(TorrentCmd())
 	rootCmd.AddCommand(VideoCmd())
 
 	if err := rootCmd.Execute(); err != nil {
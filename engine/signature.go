@@ -0,0 +1,193 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tforceaio/tf-unifiler-go/crypto/minisign"
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+)
+
+// Extension of detached signature files.
+const SignatureExt = ".sig"
+
+// Name of key pair files generated by checksum keygen.
+const signingKeyName = "unifiler"
+
+// Generate a new key pair for signing checksum files and store it in outputDir.
+// Secret key is encrypted if password is not empty. Existing keys are only replaced if force is true.
+func (m *ChecksumModule) Keygen(outputDir string, password []byte, force bool) error {
+	if outputDir == "" {
+		return errors.New("output directory is not specified")
+	}
+	pubKeyFile, secKeyFile := SigningKeyPaths(outputDir)
+	m.logger.Info().
+		Bool("encrypted", len(password) > 0).
+		Str("output", outputDir).
+		Msg("Start generating signing key pair.")
+	if !force && (filesystem.IsExist(pubKeyFile) || filesystem.IsExist(secKeyFile)) {
+		return fmt.Errorf("key pair already exists in '%s', use --force to replace it", outputDir)
+	}
+
+	pubKey, secKey, err := minisign.GenerateKey()
+	if err != nil {
+		return err
+	}
+	secContent, err := secKey.Encode(password)
+	if err != nil {
+		return err
+	}
+	err = filesystem.CreateDirectoryRecursive(outputDir)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(secKeyFile, secContent, 0600)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(pubKeyFile, pubKey.Encode(), 0644)
+	if err != nil {
+		return err
+	}
+	m.logger.Info().
+		Str("keyId", pubKey.KeyID()).
+		Str("publicKey", pubKey.String()).
+		Str("pubkey", pubKeyFile).
+		Str("seckey", secKeyFile).
+		Msg("Generated signing key pair.")
+	return nil
+}
+
+// Create detached signatures for checksum files of inputs using secret key. Directories will
+// be searched recursively for checksum files. Trusted comment is signed along with each file,
+// a comment containing timestamp and file name is used if it is empty.
+func (m *ChecksumModule) Sign(inputs []string, secKeyFile string, password []byte, trustedComment string) error {
+	if len(inputs) == 0 {
+		return errors.New("inputs is empty")
+	}
+	m.logger.Info().
+		Strs("files", inputs).
+		Str("seckey", secKeyFile).
+		Msg("Start signing checksum files.")
+	secKey, err := minisign.ReadSecretKey(secKeyFile, password)
+	if err != nil {
+		return err
+	}
+
+	checksumFiles := []string{}
+	for _, input := range inputs {
+		if !filesystem.IsDirectoryExist(input) {
+			checksumFiles = append(checksumFiles, input)
+			continue
+		}
+		contents, err := filesystem.List([]string{input}, true)
+		if err != nil {
+			return err
+		}
+		for _, c := range contents {
+			if !c.IsDir && IsChecksumFile(c.Name) {
+				checksumFiles = append(checksumFiles, c.RelativePath)
+			}
+		}
+	}
+	for _, checksumFile := range checksumFiles {
+		comment := trustedComment
+		if comment == "" {
+			comment = fmt.Sprintf("timestamp:%d\tfile:%s\thashed", time.Now().Unix(), filepath.Base(checksumFile))
+		}
+		sig, err := minisign.SignFile(secKey, checksumFile, comment)
+		if err != nil {
+			return err
+		}
+		sigFile := SignaturePath(checksumFile)
+		err = os.WriteFile(sigFile, sig.Encode(), 0664)
+		if err != nil {
+			return err
+		}
+		m.logger.Info().
+			Str("keyId", secKey.Public().KeyID()).
+			Str("path", checksumFile).
+			Str("signature", sigFile).
+			Msg("Signed checksum file.")
+	}
+	return nil
+}
+
+// Verify detached signature of a checksum file. Nil public key is ignored.
+func (m *ChecksumModule) verifySignature(checksumFile string, pubKey *minisign.PublicKey) error {
+	if pubKey == nil {
+		return nil
+	}
+	sigFile := SignaturePath(checksumFile)
+	if !filesystem.IsFileExist(sigFile) {
+		return fmt.Errorf("signature of checksum file '%s' not found", checksumFile)
+	}
+	sig, err := minisign.ReadSignature(sigFile)
+	if err != nil {
+		return err
+	}
+	err = minisign.VerifyFile(pubKey, checksumFile, sig)
+	if err != nil {
+		return fmt.Errorf("invalid signature of checksum file '%s': %w", checksumFile, err)
+	}
+	m.logger.Info().
+		Str("keyId", pubKey.KeyID()).
+		Str("path", checksumFile).
+		Str("trustedComment", sig.TrustedComment).
+		Msg("Verified signature.")
+	return nil
+}
+
+// Read public key from a file, or return nil if pubKeyFile is empty.
+func readPublicKey(pubKeyFile string) (*minisign.PublicKey, error) {
+	if pubKeyFile == "" {
+		return nil, nil
+	}
+	return minisign.ReadPublicKey(pubKeyFile)
+}
+
+// Read password from an environment variable, or return nil if envName is empty.
+func readPassword(envName string) ([]byte, error) {
+	if envName == "" {
+		return nil, nil
+	}
+	value, ok := os.LookupEnv(envName)
+	if !ok {
+		return nil, fmt.Errorf("environment variable '%s' is not set", envName)
+	}
+	return []byte(value), nil
+}
+
+// Return path to detached signature of a file.
+func SignaturePath(fPath string) string {
+	return fPath + SignatureExt
+}
+
+// Return directory storing signing keys inside config directory.
+func SigningKeyDir(configDir string) string {
+	return filepath.Join(configDir, "keys")
+}
+
+// Return paths to public key and secret key inside a directory.
+func SigningKeyPaths(dir string) (pubKeyFile, secKeyFile string) {
+	return filepath.Join(dir, signingKeyName+".pub"), filepath.Join(dir, signingKeyName+".key")
+}
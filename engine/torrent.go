@@ -0,0 +1,269 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/tforce-io/tf-golib/opx"
+	"github.com/tforceaio/tf-unifiler-go/torrent"
+)
+
+// TorrentModule handles user requests related to BitTorrent metainfo creation and verification.
+type TorrentModule struct {
+	logger zerolog.Logger
+}
+
+// Return new TorrentModule.
+func NewTorrentModule(c *Controller, cmdName string) *TorrentModule {
+	return &TorrentModule{
+		logger: c.CommandLogger("torrent", cmdName),
+	}
+}
+
+// Create .torrent file for a single file or directory. If output is empty,
+// the torrent file is written to working directory using name of the input.
+func (m *TorrentModule) Create(inputs []string, output string, opts *torrent.CreateOptions) error {
+	if len(inputs) != 1 {
+		return errors.New("torrent must be created from a single file or directory")
+	}
+	m.logger.Info().
+		Str("comment", opts.Comment).
+		Str("input", inputs[0]).
+		Str("output", output).
+		Int64("pieceLength", opts.PieceLength).
+		Bool("private", opts.Private).
+		Strs("trackers", opts.Trackers).
+		Str("version", string(opts.Version)).
+		Msg("Start creating torrent.")
+
+	mi, err := torrent.Create(inputs[0], opts, func(localPath string, f *torrent.File) {
+		m.logger.Info().
+			Str("path", localPath).
+			Int64("size", f.Length).
+			Msg("Hashed file.")
+	})
+	if err != nil {
+		return err
+	}
+	data, err := mi.Marshal()
+	if err != nil {
+		return err
+	}
+	output = opx.Ternary(output == "", mi.Name+".torrent", output)
+	if err := os.WriteFile(output, data, 0664); err != nil {
+		return err
+	}
+
+	event := m.logger.Info()
+	if mi.Version.HasV1() {
+		infoHash, _ := mi.InfoHashV1()
+		event = event.Str("infoHashV1", hex.EncodeToString(infoHash))
+	}
+	if mi.Version.HasV2() {
+		infoHash, _ := mi.InfoHashV2()
+		event = event.Str("infoHashV2", hex.EncodeToString(infoHash))
+	}
+	event.
+		Int("fileCount", len(mi.Files)).
+		Int64("pieceLength", mi.PieceLength).
+		Str("path", output).
+		Int64("size", mi.TotalLength()).
+		Msg("Written torrent file.")
+	return nil
+}
+
+// Verify content downloaded to dir against .torrent file(s) of inputs.
+// If dir is empty, working directory is used.
+func (m *TorrentModule) Verify(inputs []string, dir string) error {
+	if len(inputs) == 0 {
+		return errors.New("inputs is empty")
+	}
+	dir = opx.Ternary(dir == "", ".", dir)
+	m.logger.Info().
+		Str("dir", dir).
+		Strs("files", inputs).
+		Msg("Start verifying torrent files.")
+
+	result := &checksumVerifyResult{}
+	for _, input := range inputs {
+		data, err := os.ReadFile(input)
+		if err != nil {
+			return err
+		}
+		mi, err := torrent.Parse(data)
+		if err != nil {
+			return fmt.Errorf("invalid torrent file '%s': %w", input, err)
+		}
+		m.logger.Info().
+			Int("fileCount", len(mi.Files)).
+			Str("name", mi.Name).
+			Str("path", input).
+			Str("version", string(mi.Version)).
+			Msg("Parsed torrent file.")
+
+		err = torrent.Verify(mi, dir, func(r *torrent.VerifyResult) error {
+			switch r.Status {
+			case torrent.StatusOK:
+				result.OK++
+				m.logger.Info().
+					Str("path", r.LocalPath).
+					Str("status", r.Status).
+					Msg("Verified file.")
+			case torrent.StatusMissing:
+				result.Missing++
+				m.logger.Warn().
+					Str("path", r.LocalPath).
+					Str("status", r.Status).
+					Msg("File not found.")
+			default:
+				result.Failed++
+				m.logger.Warn().
+					Int("badPieces", r.BadPieces).
+					Str("path", r.LocalPath).
+					Str("status", r.Status).
+					Msg("Content mismatch.")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	m.logger.Info().
+		Int("failed", result.Failed).
+		Int("missing", result.Missing).
+		Int("ok", result.OK).
+		Int("total", result.OK+result.Failed+result.Missing).
+		Msgf("Verified %d file(s). %d OK, %d FAILED, %d MISSING.", result.OK+result.Failed+result.Missing, result.OK, result.Failed, result.Missing)
+	if result.Failed > 0 || result.Missing > 0 {
+		return errors.New("torrent verification failed")
+	}
+	return nil
+}
+
+// Decorator to log error occurred when calling handlers.
+func (m *TorrentModule) logError(err error) {
+	if err != nil {
+		m.logger.Err(err).Msg("Unexpected error has occurred. Program will exit.")
+	}
+}
+
+// Define Cobra Command for Torrent module.
+func TorrentCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "torrent",
+		Short: "Create and verify BitTorrent metainfo files.",
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create <input>",
+		Short: "Create .torrent file for a file or directory.",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := InitApp()
+			defer c.Close()
+			flags := ParseTorrentFlags(cmd, args)
+			m := NewTorrentModule(c, "create")
+			metaVersion, err := torrent.ParseVersion(flags.MetaVersion)
+			if err != nil {
+				m.logError(err)
+				return
+			}
+			opts := &torrent.CreateOptions{
+				Comment:     flags.Comment,
+				CreatedBy:   "TF Unifiler v" + version(),
+				PieceLength: flags.PieceSize * 1024,
+				Private:     flags.Private,
+				Trackers:    flags.Trackers,
+				Version:     metaVersion,
+			}
+			m.logError(m.Create(flags.Inputs, flags.Output, opts))
+		},
+	}
+	createCmd.Flags().StringP("comment", "c", "", "Comment stored in the torrent.")
+	createCmd.Flags().StringArrayP("inputs", "i", []string{}, "File/Directory to create torrent.")
+	createCmd.Flags().String("meta-version", "v1", "Torrent version. Supported versions: v1 (SHA-1 pieces), v2 (per-file SHA-256 merkle trees), hybrid (both).")
+	createCmd.Flags().StringP("output", "o", "", "Path of the torrent file. Defaults to input name with .torrent extension in working directory.")
+	createCmd.Flags().Int64("piece-size", 0, "Piece size in KiB, must be a power of two and at least 16. Chosen automatically if it is 0.")
+	createCmd.Flags().Bool("private", false, "Mark the torrent as private, which disables DHT and peer exchange.")
+	createCmd.Flags().StringArrayP("tracker", "t", []string{}, "Tracker announce URL, can be specified multiple times.")
+	rootCmd.AddCommand(createCmd)
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify <input>...",
+		Short: "Verify downloaded content against .torrent file(s).",
+		Run: func(cmd *cobra.Command, args []string) {
+			c := InitApp()
+			defer c.Close()
+			flags := ParseTorrentFlags(cmd, args)
+			m := NewTorrentModule(c, "verify")
+			err := m.Verify(flags.Inputs, flags.Dir)
+			m.logError(err)
+			if err != nil {
+				c.Close()
+				os.Exit(1)
+			}
+		},
+	}
+	verifyCmd.Flags().StringP("dir", "d", "", "Directory containing downloaded content. Defaults to working directory.")
+	verifyCmd.Flags().StringArrayP("inputs", "i", []string{}, "Torrent files to verify.")
+	rootCmd.AddCommand(verifyCmd)
+
+	return rootCmd
+}
+
+// Struct TorrentFlags contains all flags used by Torrent module.
+type TorrentFlags struct {
+	Comment     string
+	Dir         string
+	Inputs      []string
+	MetaVersion string
+	Output      string
+	PieceSize   int64
+	Private     bool
+	Trackers    []string
+}
+
+// Extract all flags from a Cobra Command.
+func ParseTorrentFlags(cmd *cobra.Command, args []string) *TorrentFlags {
+	comment, _ := cmd.Flags().GetString("comment")
+	dir, _ := cmd.Flags().GetString("dir")
+	inputs, _ := cmd.Flags().GetStringArray("inputs")
+	metaVersion, _ := cmd.Flags().GetString("meta-version")
+	output, _ := cmd.Flags().GetString("output")
+	pieceSize, _ := cmd.Flags().GetInt64("piece-size")
+	private, _ := cmd.Flags().GetBool("private")
+	trackers, _ := cmd.Flags().GetStringArray("tracker")
+	inputs = append(args, inputs...)
+
+	return &TorrentFlags{
+		Comment:     comment,
+		Dir:         dir,
+		Inputs:      inputs,
+		MetaVersion: metaVersion,
+		Output:      output,
+		PieceSize:   pieceSize,
+		Private:     private,
+		Trackers:    trackers,
+	}
+}
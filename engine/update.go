@@ -0,0 +1,433 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package engine
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tforce-io/tf-golib/opx"
+	"github.com/tforce-io/tf-golib/opx/slicext"
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+	"github.com/tforceaio/tf-unifiler-go/parser/checksum"
+	"github.com/tforceaio/tf-unifiler-go/parser/sfv"
+)
+
+// Extension of state files written along with checksum files by update.
+const ChecksumStateExt = ".state"
+
+// Struct ChecksumState is the content of a state file, which stores size and modification time
+// of files listed in a checksum file when they were hashed, so modified files can be detected.
+type ChecksumState struct {
+	Files map[string]*ChecksumFileState `json:"files"`
+}
+
+// Struct ChecksumFileState contains size and modification time of a file when it was hashed.
+type ChecksumFileState struct {
+	Size    int64 `json:"size"`  // -1 if size is unknown
+	ModTime int64 `json:"mtime"` // Unix time in nanoseconds
+}
+
+// Return new ChecksumFileState of a file.
+func NewChecksumFileState(fi os.FileInfo) *ChecksumFileState {
+	return &ChecksumFileState{
+		Size:    fi.Size(),
+		ModTime: fi.ModTime().UnixNano(),
+	}
+}
+
+// Check whether a file is modified since its state was recorded.
+func (s *ChecksumFileState) IsModified(fi os.FileInfo) bool {
+	return (s.Size >= 0 && s.Size != fi.Size()) || s.ModTime != fi.ModTime().UnixNano()
+}
+
+// Write the state to a file.
+func (s *ChecksumState) Write(fPath string) error {
+	fContent, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fPath, fContent, 0664)
+}
+
+// Read state of a checksum file. Empty state is returned if the state file does not exist.
+func ReadChecksumState(fPath string) (*ChecksumState, error) {
+	state := &ChecksumState{Files: map[string]*ChecksumFileState{}}
+	fContent, err := os.ReadFile(fPath)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(fContent, state); err != nil {
+		return nil, err
+	}
+	if state.Files == nil {
+		state.Files = map[string]*ChecksumFileState{}
+	}
+	return state, nil
+}
+
+// Return path of state file written along with a checksum file, e.g. checksum.sha1 uses checksum.sha1.state.
+func ChecksumStatePath(checksumFile string) string {
+	return checksumFile + ChecksumStateExt
+}
+
+// Struct checksumUpdateResult counts files by the changes made to a checksum file.
+type checksumUpdateResult struct {
+	Added     int
+	Changed   int
+	Removed   int
+	Unchanged int
+}
+
+// Update existing checksum file(s) of inputs. Files inside the directory containing each checksum
+// file which are not listed yet are hashed and appended in sorted order, while entries of files
+// which are gone are dropped unless keepMissing is true. Existing entries keep their order.
+// Subdirectories are only scanned if the checksum file already lists files inside them, and those
+// having their own checksum files are skipped unless they are listed too, so checksum files created
+// per directory stay separate.
+// Files whose size or modification time differ from the ones recorded in the state file are reported,
+// and re-hashed if refresh is true. Files without recorded state, e.g. when the checksum file is updated
+// for the first time, are reported if they are modified after the checksum file was written.
+// Reported files which are not re-hashed keep their recorded state, so they are reported again.
+// Hash cache of workspaceDir is used if it is set.
+func (m *ChecksumModule) Update(inputs []string, keepMissing, refresh bool, workspaceDir string, opts *HashOptions) error {
+	if len(inputs) == 0 {
+		return errors.New("inputs is empty")
+	}
+	m.logger.Info().
+		Strs("files", inputs).
+		Bool("keepMissing", keepMissing).
+		Bool("refresh", refresh).
+		Str("workspace", workspaceDir).
+		Msg("Start updating checksum files.")
+
+	for _, input := range inputs {
+		result, err := m.updateFile(input, keepMissing, refresh, workspaceDir, opts)
+		if err != nil {
+			return err
+		}
+		m.logger.Info().
+			Int("added", result.Added).
+			Int("changed", result.Changed).
+			Int("removed", result.Removed).
+			Int("unchanged", result.Unchanged).
+			Str("path", input).
+			Msgf("Updated checksum file. %d added, %d changed, %d removed, %d unchanged.", result.Added, result.Changed, result.Removed, result.Unchanged)
+		if filesystem.IsFileExist(SignaturePath(input)) {
+			m.logger.Warn().
+				Str("path", input).
+				Str("signature", SignaturePath(input)).
+				Msg("Signature is outdated, the checksum file must be signed again.")
+		}
+	}
+	return nil
+}
+
+// Update a single checksum file, then return number of files by their changes.
+func (m *ChecksumModule) updateFile(checksumFile string, keepMissing, refresh bool, workspaceDir string, opts *HashOptions) (*checksumUpdateResult, error) {
+	fi, err := os.Stat(checksumFile)
+	if err != nil {
+		return nil, err
+	}
+	statePath := ChecksumStatePath(checksumFile)
+	state, err := ReadChecksumState(statePath)
+	if err != nil {
+		return nil, err
+	}
+	checksumReader, err := os.Open(checksumFile)
+	if err != nil {
+		return nil, err
+	}
+	isSfv := strings.EqualFold(filepath.Ext(checksumFile), ".sfv")
+	var items []*checksum.ChecksumItem
+	if isSfv {
+		items, err = sfv.NewParser(checksumReader).Parse()
+	} else {
+		items, err = checksum.NewParser(checksumReader).Parse()
+	}
+	checksumReader.Close()
+	if err != nil {
+		return nil, err
+	}
+	m.logger.Info().
+		Int("count", len(items)).
+		Str("path", checksumFile).
+		Msg("Parsed checksum file.")
+
+	// algorithms of new entries are the ones already used in the checksum file.
+	fileAlgo := ChecksumFileAlgorithm(checksumFile)
+	tagged := strings.EqualFold(filepath.Ext(checksumFile), ".sum")
+	algorithms := []string{}
+	itemAlgos := make([]string, len(items))
+	for i, item := range items {
+		tagged = tagged || item.Algorithm != ""
+		name := opx.Ternary(item.Algorithm == "", fileAlgo, item.Algorithm)
+		algo, ok := hasher.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("cannot detect hash algorithm of checksum file '%s'", checksumFile)
+		}
+		itemAlgos[i] = algo.Name
+		if !slicext.Contains(algorithms, algo.Name) {
+			algorithms = append(algorithms, algo.Name)
+		}
+	}
+	if len(algorithms) == 0 && fileAlgo != "" {
+		algorithms = append(algorithms, fileAlgo)
+	}
+	if len(algorithms) == 0 {
+		return nil, fmt.Errorf("cannot detect hash algorithm of checksum file '%s'", checksumFile)
+	}
+
+	baseDir := filepath.Dir(checksumFile)
+	result := &checksumUpdateResult{}
+	newState := &ChecksumState{Files: map[string]*ChecksumFileState{}}
+	listed := map[string]bool{}
+	statuses := map[string]string{}
+	hashPaths := []string{}
+	for _, item := range items {
+		fPath := m.resolvePath(item.Path, baseDir)
+		key := comparablePath(fPath)
+		if listed[key] {
+			continue
+		}
+		listed[key] = true
+		relPath, err := relativePath(fPath, baseDir)
+		if err != nil {
+			return nil, err
+		}
+		itemState := state.Files[relPath]
+		itemFi, err := os.Stat(fPath)
+		modified := false
+		if err == nil && itemState != nil {
+			modified = itemState.IsModified(itemFi)
+		} else if err == nil {
+			modified = itemFi.ModTime().After(fi.ModTime())
+		}
+		switch {
+		case err != nil && !os.IsNotExist(err):
+			return nil, err
+		case err != nil && keepMissing:
+			if itemState != nil {
+				newState.Files[relPath] = itemState
+			}
+			statuses[key] = "MISSING"
+			result.Unchanged++
+			m.logger.Warn().
+				Str("path", fPath).
+				Str("status", "MISSING").
+				Msg("File not found. Entry is kept.")
+		case err != nil:
+			statuses[key] = "REMOVED"
+			result.Removed++
+			m.logger.Info().
+				Str("path", fPath).
+				Str("status", "REMOVED").
+				Msg("File not found. Entry is removed.")
+		case modified && refresh:
+			newState.Files[relPath] = NewChecksumFileState(itemFi)
+			statuses[key] = "CHANGED"
+			result.Changed++
+			hashPaths = append(hashPaths, fPath)
+		case modified:
+			// unknown size and time of the checksum file keep the file reported until it is re-hashed.
+			newState.Files[relPath] = opx.Ternary(itemState != nil, itemState, &ChecksumFileState{Size: -1, ModTime: fi.ModTime().UnixNano()})
+			statuses[key] = "CHANGED"
+			result.Changed++
+			m.logger.Warn().
+				Str("path", fPath).
+				Int64("size", itemFi.Size()).
+				Str("status", "CHANGED").
+				Time("mtime", itemFi.ModTime()).
+				Msg("File is modified after it was hashed. Use --refresh to re-hash it.")
+		default:
+			newState.Files[relPath] = NewChecksumFileState(itemFi)
+			result.Unchanged++
+		}
+	}
+
+	// subdirectories are scanned only if the checksum file already lists files inside them,
+	// and those having their own checksum files are left to them unless they are listed here too.
+	baseKey := comparablePath(baseDir)
+	entryDirs := map[string]bool{}
+	for key := range listed {
+		for dir := path.Dir(key); dir != baseKey && isSubPath(dir, baseKey) && dir != path.Dir(dir); dir = path.Dir(dir) {
+			entryDirs[dir] = true
+		}
+	}
+	includeSubdirs := len(entryDirs) > 0
+	contents, err := filesystem.List([]string{baseDir}, true)
+	if err != nil {
+		return nil, err
+	}
+	coveredDirs := map[string]bool{}
+	for _, c := range contents {
+		dir := path.Dir(comparablePath(c.RelativePath))
+		if !c.IsDir && IsChecksumFile(c.Name) && dir != baseKey && !entryDirs[dir] {
+			coveredDirs[dir] = true
+		}
+	}
+	newPaths := []string{}
+	for _, c := range contents {
+		if c.IsDir || isChecksumArtifact(c.Name) || isWorkspacePath(c.RelativePath) {
+			continue
+		}
+		key := comparablePath(c.RelativePath)
+		if listed[key] || (!includeSubdirs && path.Dir(key) != baseKey) || isCoveredPath(key, baseKey, coveredDirs) {
+			continue
+		}
+		if relPath, _ := relativePath(c.RelativePath, baseDir); isSfv && !sfv.IsSupportedPath(relPath) {
+			m.logger.Warn().
+				Str("path", c.RelativePath).
+				Msg("Skipped file. Its path cannot be written to SFV file.")
+			continue
+		}
+		newPaths = append(newPaths, c.RelativePath)
+	}
+	sort.Strings(newPaths)
+	hashPaths = append(hashPaths, newPaths...)
+	newFis := map[string]os.FileInfo{}
+	for _, fPath := range newPaths {
+		newFi, err := os.Stat(fPath)
+		if err != nil {
+			return nil, err
+		}
+		newFis[fPath] = newFi
+	}
+
+	digests := map[string]map[string]string{}
+	sizes := map[string]int64{}
+	err = hashFiles(m.logger, hashPaths, algorithms, workspaceDir, opts, func(i int, fhResults []*hasher.HashResult, err error) error {
+		if err != nil {
+			m.logger.Info().
+				Str("path", hashPaths[i]).
+				Msg("Failed to compute hash.")
+			return err
+		}
+		key := comparablePath(hashPaths[i])
+		digests[key] = map[string]string{}
+		for _, r := range fhResults {
+			digests[key][r.Algorithm] = hex.EncodeToString(r.Hash)
+		}
+		sizes[key] = fhResults[0].Size
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	newItems := []*checksum.ChecksumItem{}
+	for i, item := range items {
+		key := comparablePath(m.resolvePath(item.Path, baseDir))
+		switch statuses[key] {
+		case "REMOVED":
+			continue
+		case "CHANGED":
+			if hash, ok := digests[key][itemAlgos[i]]; ok {
+				if !strings.EqualFold(hash, item.Hash) {
+					m.logger.Warn().
+						Str("actual", hash).
+						Str("algo", itemAlgos[i]).
+						Str("expected", item.Hash).
+						Str("path", item.Path).
+						Str("status", "CHANGED").
+						Msg("Replaced hash of modified file.")
+				}
+				item.Hash = hash
+			}
+		}
+		newItems = append(newItems, item)
+	}
+	for _, fPath := range newPaths {
+		relPath, err := relativePath(fPath, baseDir)
+		if err != nil {
+			return nil, err
+		}
+		key := comparablePath(fPath)
+		newState.Files[relPath] = NewChecksumFileState(newFis[fPath])
+		for _, a := range algorithms {
+			newItems = append(newItems, &checksum.ChecksumItem{
+				Algorithm:  opx.Ternary(tagged, a, ""),
+				Hash:       digests[key][a],
+				BinaryMode: true,
+				Path:       relPath,
+			})
+		}
+		result.Added++
+		m.logger.Info().
+			Str("path", relPath).
+			Int64("size", sizes[key]).
+			Str("status", "ADDED").
+			Msg("Added file.")
+	}
+
+	fContents := []string{}
+	if isSfv {
+		fContents = append(fContents, sfv.FormatComment("Generated by TF Unifiler v"+version()))
+	}
+	for _, item := range newItems {
+		switch {
+		case isSfv:
+			fContents = append(fContents, sfv.Format(item))
+		case item.Algorithm != "":
+			fContents = append(fContents, checksum.FormatBsd(item))
+		default:
+			fContents = append(fContents, checksum.FormatGnu(item))
+		}
+	}
+	err = m.writeChecksumFile(checksumFile, fContents)
+	if err != nil {
+		return nil, err
+	}
+	return result, newState.Write(statePath)
+}
+
+// Check whether any directory between fPath and baseDir is in coveredDirs. All paths must be comparable.
+func isCoveredPath(fPath, baseDir string, coveredDirs map[string]bool) bool {
+	for dir := path.Dir(fPath); dir != baseDir && dir != path.Dir(dir); dir = path.Dir(dir) {
+		if coveredDirs[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// Check whether a file is created along with checksum files, so it must not be listed in them.
+func isChecksumArtifact(fPath string) bool {
+	ext := filepath.Ext(fPath)
+	for _, e := range []string{SignatureExt, BlockMapExt, ChecksumStateExt, ".hashdeep", ".dfxml"} {
+		if strings.EqualFold(ext, e) {
+			return true
+		}
+	}
+	return IsChecksumFile(fPath)
+}
+
+// Check whether a file or directory is inside Unifiler workspace data, so it must not be hashed.
+func isWorkspacePath(fPath string) bool {
+	return strings.Contains("/"+filesystem.NormalizePath(fPath)+"/", "/.unifiler/")
+}
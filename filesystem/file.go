@@ -99,7 +99,7 @@ func IsAbsPath(fPath string) bool {
 	if strings.HasPrefix(fPath, "/") {
 		return true
 	}
-	if isAbs, _ := regexp.MatchString(fPath, "^[a-zA-Z]:[\\/]"); isAbs {
+	if isAbs, _ := regexp.MatchString(`^[a-zA-Z]:[\\/]`, fPath); isAbs {
 		return true
 	}
 	return false
@@ -166,17 +166,21 @@ func NormalizePath(fPath string) string {
 }
 
 func WriteLines(fPath string, lines []string) error {
-	f, err := os.OpenFile(fPath, os.O_WRONLY|os.O_CREATE, 0664)
+	f, err := os.OpenFile(fPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0664)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
 	writer := bufio.NewWriter(f)
 	for _, line := range lines {
 		writer.WriteString(line)
 		writer.WriteString("\n")
 	}
-	writer.Flush()
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return f.Close()
 }
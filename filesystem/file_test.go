@@ -21,6 +21,29 @@ import (
 	"testing"
 )
 
+func TestIsAbsPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		fPath  string
+		result bool
+	}{
+		{"unix absolute", "/home/user", true},
+		{"windows absolute", "C:\\Users", true},
+		{"windows forward slash", "d:/data", true},
+		{"single letter", "a", false},
+		{"relative", "sub/a.txt", false},
+		{"drive relative", "c:file", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := IsAbsPath(tt.fPath)
+			if result != tt.result {
+				t.Errorf("Wrong absolute path check for '%s'. Expected %t Actual %t", tt.fPath, tt.result, result)
+			}
+		})
+	}
+}
+
 func TestList(t *testing.T) {
 	prepareTests()
 
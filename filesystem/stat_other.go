@@ -0,0 +1,28 @@
+//go:build !unix && !windows
+
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package filesystem
+
+import (
+	"errors"
+)
+
+// Return identity of a file. Not supported on this platform.
+func GetFileID(fPath string) (uint64, uint64, error) {
+	return 0, 0, errors.New("file identity is not supported on this platform")
+}
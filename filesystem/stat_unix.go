@@ -0,0 +1,38 @@
+//go:build unix
+
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Return identity of a file, which is device ID and inode number of the file.
+func GetFileID(fPath string) (uint64, uint64, error) {
+	fi, err := os.Stat(fPath)
+	if err != nil {
+		return 0, 0, err
+	}
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, errors.New("inode is not available")
+	}
+	return uint64(stat.Dev), uint64(stat.Ino), nil
+}
@@ -0,0 +1,291 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package par2
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// Default percentage of recovery slices to input slices.
+	DefaultRedundancy = 10
+	// Largest number of input slices supported by PAR2.
+	MaxSliceCount = 32768
+	// Number of input slices automatic slice size aims for.
+	targetSliceCount = 2000
+)
+
+// Struct CreateOptions contains settings for creating recovery files.
+type CreateOptions struct {
+	Creator    string
+	Redundancy int   // number of recovery slices in percentage of input slices, from 0 to 100
+	SliceSize  int64 // must be a multiple of 4, 0 chooses automatically
+}
+
+// Create recovery files for files. output is the index file, e.g. data.par2, and recovery slices
+// are written to volumes next to it, e.g. data.vol00+01.par2. Files are named relative to
+// directory of output, which must contain all of them. Empty files are skipped.
+// All recovery slices are kept in memory until all files are read.
+// fn is called after each file is hashed if it is not nil.
+func Create(fPaths []string, output string, opts *CreateOptions, fn func(localPath string, f *File)) (*RecoverySet, []string, error) {
+	if opts == nil {
+		opts = &CreateOptions{Redundancy: DefaultRedundancy}
+	}
+	if opts.Redundancy < 0 || opts.Redundancy > 100 {
+		return nil, nil, fmt.Errorf("redundancy must be from 0 to 100, got %d", opts.Redundancy)
+	}
+	if opts.SliceSize < 0 || opts.SliceSize%4 != 0 {
+		return nil, nil, fmt.Errorf("slice size must be a multiple of 4, got %d", opts.SliceSize)
+	}
+	files, localPaths, err := listFiles(fPaths, filepath.Dir(output))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil, errors.New("no file to protect")
+	}
+
+	total := int64(0)
+	for _, f := range files {
+		total += f.Length
+	}
+	s := &RecoverySet{
+		Creator:   opts.Creator,
+		Files:     files,
+		Recovery:  []*RecoverySlice{},
+		SliceSize: opts.SliceSize,
+	}
+	if s.SliceSize == 0 {
+		s.SliceSize = autoSliceSize(total)
+	}
+	count := s.SliceCount()
+	if count > MaxSliceCount {
+		return nil, nil, fmt.Errorf("%d input slices exceed limit of %d, slice size must be larger", count, MaxSliceCount)
+	}
+	s.ID = md5Sum(mainBody(s.SliceSize, files))
+
+	constants, err := inputConstants(count)
+	if err != nil {
+		return nil, nil, err
+	}
+	recoveryCount := (count*opts.Redundancy + 99) / 100
+	recovery := make([][]byte, recoveryCount)
+	for i := range recovery {
+		recovery[i] = make([]byte, s.SliceSize)
+	}
+	factors := make([]uint16, recoveryCount)
+	buf := make([]byte, s.SliceSize)
+	index := 0
+	for i, f := range files {
+		err := readSlices(localPaths[i], f, s.SliceSize, buf, func(slice []byte) {
+			for j := range factors {
+				factors[j] = gfPow(constants[index], uint32(j))
+			}
+			gfMulAddAll(recovery, slice, factors)
+			index++
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if fn != nil {
+			fn(localPaths[i], f)
+		}
+	}
+
+	written, err := writeRecoveryFiles(s, output, recovery)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, written, nil
+}
+
+// Return slice size resulting in about targetSliceCount slices.
+func autoSliceSize(total int64) int64 {
+	size := (total + targetSliceCount - 1) / targetSliceCount
+	size = (size + 3) &^ 3
+	if size < 4 {
+		return 4
+	}
+	return size
+}
+
+// Return descriptions of files without hashes, sorted by their IDs, along with their local paths.
+func listFiles(fPaths []string, baseDir string) ([]*File, []string, error) {
+	absBase, err := filepath.Abs(baseDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := []*File{}
+	localPaths := map[string]string{}
+	for _, fPath := range fPaths {
+		fi, err := os.Stat(fPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !fi.Mode().IsRegular() || fi.Size() == 0 {
+			continue
+		}
+		absPath, err := filepath.Abs(fPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		name, err := filepath.Rel(absBase, absPath)
+		if err != nil || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return nil, nil, fmt.Errorf("file '%s' is outside of '%s'", fPath, baseDir)
+		}
+		name = filepath.ToSlash(name)
+		if _, ok := localPaths[name]; ok {
+			continue
+		}
+		hash16k, err := hashHead(fPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, &File{
+			ID:      fileID(hash16k, fi.Size(), name),
+			Hash16k: hash16k,
+			Length:  fi.Size(),
+			Name:    name,
+		})
+		localPaths[name] = fPath
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return compareIDs(files[i].ID, files[j].ID) < 0
+	})
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = localPaths[f.Name]
+	}
+	return files, paths, nil
+}
+
+// Return MD5 of the first 16 KiB of a file.
+func hashHead(fPath string) ([]byte, error) {
+	fHandle, err := os.Open(fPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fHandle.Close()
+
+	h := md5.New()
+	if _, err := io.CopyN(h, fHandle, hash16kSize); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Read a file slice by slice to compute its MD5 and checksums of slices. fn is called
+// with every slice padded with zeros to slice size.
+func readSlices(fPath string, f *File, sliceSize int64, buf []byte, fn func(slice []byte)) error {
+	fHandle, err := os.Open(fPath)
+	if err != nil {
+		return err
+	}
+	defer fHandle.Close()
+
+	h := md5.New()
+	f.Slices = make([]*Slice, sliceCount(f.Length, sliceSize))
+	for i := range f.Slices {
+		n, err := io.ReadFull(fHandle, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		if expected := f.Length - int64(i)*sliceSize; expected < sliceSize && int64(n) != expected || expected >= sliceSize && err != nil {
+			return fmt.Errorf("file '%s' has been changed while reading", fPath)
+		}
+		h.Write(buf[:n])
+		zero(buf[n:])
+		f.Slices[i] = &Slice{
+			Hash:  md5Sum(buf),
+			CRC32: crc32.ChecksumIEEE(buf),
+		}
+		fn(buf)
+	}
+	f.Hash = h.Sum(nil)
+	return nil
+}
+
+func zero(buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+}
+
+func md5Sum(data []byte) []byte {
+	sum := md5.Sum(data)
+	return sum[:]
+}
+
+// Write index file and recovery volumes. Volumes contain 1, 2, 4... recovery slices,
+// every one of them also contains all packets of the index file.
+func writeRecoveryFiles(s *RecoverySet, output string, recovery [][]byte) ([]string, error) {
+	if err := writeRecoveryFile(output, s, nil, 0); err != nil {
+		return nil, err
+	}
+	written := []string{output}
+	stem := strings.TrimSuffix(output, filepath.Ext(output))
+	width := len(fmt.Sprint(len(recovery)))
+	if width < 2 {
+		width = 2
+	}
+	for start, count := 0, 1; start < len(recovery); start, count = start+count, count*2 {
+		if start+count > len(recovery) {
+			count = len(recovery) - start
+		}
+		vPath := fmt.Sprintf("%s.vol%0*d+%0*d.par2", stem, width, start, width, count)
+		if err := writeRecoveryFile(vPath, s, recovery[start:start+count], start); err != nil {
+			return nil, err
+		}
+		written = append(written, vPath)
+	}
+	return written, nil
+}
+
+// Write a recovery file containing recovery slices starting from exponent first, then critical packets.
+func writeRecoveryFile(fPath string, s *RecoverySet, recovery [][]byte, first int) error {
+	fHandle, err := os.Create(fPath)
+	if err != nil {
+		return err
+	}
+	defer fHandle.Close()
+
+	for i, data := range recovery {
+		exponent := uint32(first + i)
+		if err := writePacket(fHandle, s.ID, typeRecoverySlic, binary.LittleEndian.AppendUint32(nil, exponent), data); err != nil {
+			return err
+		}
+		s.Recovery = append(s.Recovery, &RecoverySlice{
+			Exponent: exponent,
+			Offset:   int64(i)*(headerSize+4+s.SliceSize) + headerSize + 4,
+			Path:     fPath,
+			size:     s.SliceSize,
+		})
+	}
+	if err := writeCriticalPackets(fHandle, s); err != nil {
+		return err
+	}
+	return fHandle.Close()
+}
@@ -0,0 +1,204 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package par2
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+const (
+	// Generator polynomial of GF(2^16) used by PAR2, x^16 + x^12 + x^3 + x + 1.
+	gfPolynomial = 0x1100b
+	// Number of non-zero elements of GF(2^16).
+	gfLimit = 65535
+	// Smallest part of a slice processed by a single goroutine.
+	minChunkSize = 64 * 1024
+)
+
+var (
+	gfLog [gfLimit + 1]uint16
+	gfExp [2 * gfLimit]uint16
+)
+
+func init() {
+	x := 1
+	for i := 0; i < gfLimit; i++ {
+		gfExp[i] = uint16(x)
+		gfExp[i+gfLimit] = uint16(x)
+		gfLog[x] = uint16(i)
+		x <<= 1
+		if x&0x10000 != 0 {
+			x ^= gfPolynomial
+		}
+	}
+}
+
+func gfMul(a, b uint16) uint16 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfInv(a uint16) uint16 {
+	return gfExp[gfLimit-int(gfLog[a])]
+}
+
+func gfPow(a uint16, e uint32) uint16 {
+	if e == 0 {
+		return 1
+	}
+	if a == 0 {
+		return 0
+	}
+	return gfExp[uint64(gfLog[a])*uint64(e)%gfLimit]
+}
+
+// Return constants of input slices. The constant of each slice is a power of two whose
+// exponent is relatively prime to 65535, in ascending order of exponents.
+func inputConstants(n int) ([]uint16, error) {
+	constants := make([]uint16, n)
+	exp := 0
+	for i := range constants {
+		for exp%3 == 0 || exp%5 == 0 || exp%17 == 0 || exp%257 == 0 {
+			exp++
+		}
+		if exp >= gfLimit {
+			return nil, errors.New("too many input slices")
+		}
+		constants[i] = gfExp[exp]
+		exp++
+	}
+	return constants, nil
+}
+
+// Multiply src by c, then add the product to dst. Both are treated as arrays of
+// little-endian 16-bit words.
+func gfMulAdd(dst, src []byte, c uint16) {
+	switch c {
+	case 0:
+		return
+	case 1:
+		for i := range src {
+			dst[i] ^= src[i]
+		}
+		return
+	}
+	var lo, hi [256]uint16
+	for x := 1; x < 256; x++ {
+		lo[x] = gfMul(c, uint16(x))
+		hi[x] = gfMul(c, uint16(x)<<8)
+	}
+	src = src[:len(src)&^1]
+	dst = dst[:len(src)]
+	for i := 0; i+1 < len(src); i += 2 {
+		p := lo[src[i]] ^ hi[src[i+1]]
+		dst[i] ^= byte(p)
+		dst[i+1] ^= byte(p >> 8)
+	}
+}
+
+// Add src multiplied by factors[i] to dsts[i] for every i. Large slices are split into chunks,
+// otherwise destinations are split into groups, which are processed concurrently.
+func gfMulAddAll(dsts [][]byte, src []byte, factors []uint16) {
+	workers := runtime.NumCPU()
+	wg := &sync.WaitGroup{}
+	if len(src) >= 2*minChunkSize {
+		chunkSize := (len(src)/workers + 1) &^ 1
+		if chunkSize < minChunkSize {
+			chunkSize = minChunkSize
+		}
+		for start := 0; start < len(src); start += chunkSize {
+			end := start + chunkSize
+			if end > len(src) {
+				end = len(src)
+			}
+			wg.Add(1)
+			go func(start, end int) {
+				defer wg.Done()
+				for i, dst := range dsts {
+					gfMulAdd(dst[start:end], src[start:end], factors[i])
+				}
+			}(start, end)
+		}
+		wg.Wait()
+		return
+	}
+	groupSize := (len(dsts) + workers - 1) / workers
+	if groupSize == 0 {
+		return
+	}
+	for start := 0; start < len(dsts); start += groupSize {
+		end := start + groupSize
+		if end > len(dsts) {
+			end = len(dsts)
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				gfMulAdd(dsts[i], src, factors[i])
+			}
+		}(start, end)
+	}
+	wg.Wait()
+}
+
+// Return inverse of a square matrix using Gauss-Jordan elimination.
+func gfInvert(matrix [][]uint16) ([][]uint16, error) {
+	n := len(matrix)
+	a := make([][]uint16, n)
+	inv := make([][]uint16, n)
+	for i := range matrix {
+		a[i] = append([]uint16{}, matrix[i]...)
+		inv[i] = make([]uint16, n)
+		inv[i][i] = 1
+	}
+	for col := 0; col < n; col++ {
+		pivot := -1
+		for row := col; row < n; row++ {
+			if a[row][col] != 0 {
+				pivot = row
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, errors.New("matrix is singular")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+		if f := gfInv(a[col][col]); f != 1 {
+			for k := 0; k < n; k++ {
+				a[col][k] = gfMul(a[col][k], f)
+				inv[col][k] = gfMul(inv[col][k], f)
+			}
+		}
+		for row := 0; row < n; row++ {
+			f := a[row][col]
+			if row == col || f == 0 {
+				continue
+			}
+			for k := 0; k < n; k++ {
+				a[row][k] ^= gfMul(a[col][k], f)
+				inv[row][k] ^= gfMul(inv[col][k], f)
+			}
+		}
+	}
+	return inv, nil
+}
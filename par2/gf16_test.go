@@ -0,0 +1,99 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package par2
+
+import (
+	"testing"
+)
+
+func TestInputConstants(t *testing.T) {
+	// exponents 1, 2, 4, 7, 8, 11, 13, 14, 16 are the first ones relatively prime to 65535.
+	expected := []uint16{2, 4, 16, 128, 256, 2048, 8192, 16384, 0x100b}
+	constants, err := inputConstants(len(expected))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range expected {
+		if constants[i] != expected[i] {
+			t.Errorf("wrong constant %d. expected %#x actual %#x", i, expected[i], constants[i])
+		}
+	}
+	if _, err := inputConstants(MaxSliceCount); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := inputConstants(MaxSliceCount + 1); err == nil {
+		t.Error("expected error for too many input slices")
+	}
+}
+
+func TestGfArithmetic(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        uint16
+		b        uint16
+		expected uint16
+	}{
+		{"zero", 0, 0x1234, 0},
+		{"one", 1, 0x1234, 0x1234},
+		{"no reduction", 0x100, 0x80, 0x8000},
+		{"reduction", 0x8000, 2, 0x100b},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := gfMul(tt.a, tt.b); actual != tt.expected {
+				t.Errorf("wrong product. expected %#x actual %#x", tt.expected, actual)
+			}
+		})
+	}
+	for a := 1; a <= gfLimit; a += 97 {
+		if p := gfMul(uint16(a), gfInv(uint16(a))); p != 1 {
+			t.Fatalf("wrong inverse of %#x", a)
+		}
+	}
+	if gfPow(2, 16) != 0x100b || gfPow(3, gfLimit) != 1 {
+		t.Error("wrong power")
+	}
+}
+
+func TestGfInvert(t *testing.T) {
+	constants, _ := inputConstants(5)
+	matrix := make([][]uint16, len(constants))
+	for j := range matrix {
+		matrix[j] = make([]uint16, len(constants))
+		for i, c := range constants {
+			matrix[j][i] = gfPow(c, uint32(j))
+		}
+	}
+	inverse, err := gfInvert(matrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range matrix {
+		for j := range matrix {
+			sum := uint16(0)
+			for k := range matrix {
+				sum ^= gfMul(matrix[i][k], inverse[k][j])
+			}
+			if (i == j && sum != 1) || (i != j && sum != 0) {
+				t.Fatalf("product is not identity at %d, %d", i, j)
+			}
+		}
+	}
+	if _, err := gfInvert([][]uint16{{1, 1}, {1, 1}}); err == nil {
+		t.Error("expected error for singular matrix")
+	}
+}
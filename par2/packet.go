@@ -0,0 +1,430 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+/*
+Package par2 creates, verifies and repairs PAR 2.0 recovery sets. Recovery data is
+computed using Reed-Solomon codes over GF(2^16) as described in the PAR 2.0
+specification, so the files are interchangeable with other PAR2 clients.
+*/
+package par2
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// Size of packet header.
+	headerSize = 64
+	// Largest packet other than recovery slices which will be read into memory.
+	maxPacketSize = 64 * 1024 * 1024
+	// Size of the beginning of files whose MD5 is used to identify them.
+	hash16kSize = 16 * 1024
+)
+
+var (
+	packetMagic      = []byte("PAR2\x00PKT")
+	typeMain         = []byte("PAR 2.0\x00Main\x00\x00\x00\x00")
+	typeFileDesc     = []byte("PAR 2.0\x00FileDesc")
+	typeSliceHash    = []byte("PAR 2.0\x00IFSC\x00\x00\x00\x00")
+	typeRecoverySlic = []byte("PAR 2.0\x00RecvSlic")
+	typeCreator      = []byte("PAR 2.0\x00Creator\x00")
+
+	volumeRegex = regexp.MustCompile(`(?i)\.vol\d+[+-]\d+$`)
+)
+
+// Struct File describes a file protected by a recovery set.
+type File struct {
+	ID      []byte
+	Hash    []byte // MD5 of the whole file
+	Hash16k []byte // MD5 of the first 16 KiB of the file
+	Length  int64
+	Name    string   // slash separated path relative to directory of the recovery files
+	Slices  []*Slice // nil if checksums of slices are not available
+}
+
+// Struct Slice contains checksums of an input slice, padded with zeros to slice size.
+type Slice struct {
+	Hash  []byte
+	CRC32 uint32
+}
+
+// Struct RecoverySlice is the location of a recovery slice in a recovery file.
+type RecoverySlice struct {
+	Exponent uint32
+	Offset   int64
+	Path     string
+	size     int64
+}
+
+// Struct RecoverySet contains information read from recovery files.
+type RecoverySet struct {
+	Creator   string
+	Files     []*File // in the order of input slices
+	ID        []byte
+	Recovery  []*RecoverySlice // sorted by exponent
+	SliceSize int64
+}
+
+// Return number of input slices of all files.
+func (s *RecoverySet) SliceCount() int {
+	count := 0
+	for _, f := range s.Files {
+		count += sliceCount(f.Length, s.SliceSize)
+	}
+	return count
+}
+
+// Return number of slices of content having specified length.
+func sliceCount(length, sliceSize int64) int {
+	return int((length + sliceSize - 1) / sliceSize)
+}
+
+// Return ID of a file computed from its description.
+func fileID(hash16k []byte, length int64, name string) []byte {
+	h := md5.New()
+	h.Write(hash16k)
+	binary.Write(h, binary.LittleEndian, uint64(length))
+	h.Write([]byte(name))
+	return h.Sum(nil)
+}
+
+// Compare IDs as 128-bit little-endian integers, which is the order of files in main packet.
+func compareIDs(a, b []byte) int {
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Write a packet. Its body is the concatenation of parts.
+func writePacket(w io.Writer, setID, packetType []byte, parts ...[]byte) error {
+	length := uint64(headerSize)
+	h := md5.New()
+	h.Write(setID)
+	h.Write(packetType)
+	for _, p := range parts {
+		length += uint64(len(p))
+		h.Write(p)
+	}
+	header := make([]byte, 0, headerSize)
+	header = append(header, packetMagic...)
+	header = binary.LittleEndian.AppendUint64(header, length)
+	header = h.Sum(header)
+	header = append(header, setID...)
+	header = append(header, packetType...)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	for _, p := range parts {
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return bytes of a string padded with zeros to a multiple of 4 bytes.
+func padString(s string) []byte {
+	b := []byte(s)
+	return append(b, make([]byte, (4-len(b)%4)%4)...)
+}
+
+// Return main packet body.
+func mainBody(sliceSize int64, files []*File) []byte {
+	body := binary.LittleEndian.AppendUint64(nil, uint64(sliceSize))
+	body = binary.LittleEndian.AppendUint32(body, uint32(len(files)))
+	for _, f := range files {
+		body = append(body, f.ID...)
+	}
+	return body
+}
+
+// Write packets required to verify files: main, file descriptions, slice checksums and creator.
+func writeCriticalPackets(w io.Writer, s *RecoverySet) error {
+	if err := writePacket(w, s.ID, typeMain, mainBody(s.SliceSize, s.Files)); err != nil {
+		return err
+	}
+	for _, f := range s.Files {
+		length := binary.LittleEndian.AppendUint64(nil, uint64(f.Length))
+		if err := writePacket(w, s.ID, typeFileDesc, f.ID, f.Hash, f.Hash16k, length, padString(f.Name)); err != nil {
+			return err
+		}
+		checksums := make([]byte, 0, len(f.Slices)*20)
+		for _, slice := range f.Slices {
+			checksums = append(checksums, slice.Hash...)
+			checksums = binary.LittleEndian.AppendUint32(checksums, slice.CRC32)
+		}
+		if err := writePacket(w, s.ID, typeSliceHash, f.ID, checksums); err != nil {
+			return err
+		}
+	}
+	return writePacket(w, s.ID, typeCreator, padString(s.Creator))
+}
+
+// Return paths of recovery files belonging to the same set as fPath, including fPath itself,
+// e.g. for data.par2 they are data.par2 and data.vol*.par2 in the same directory.
+func VolumePaths(fPath string) ([]string, error) {
+	dir := filepath.Dir(fPath)
+	name := filepath.Base(fPath)
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+	stem = volumeRegex.ReplaceAllString(stem, "")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for _, e := range entries {
+		eName := e.Name()
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(eName), ".par2") || !strings.HasPrefix(eName, stem) {
+			continue
+		}
+		eStem := strings.TrimSuffix(eName, filepath.Ext(eName))
+		if eStem == stem || volumeRegex.ReplaceAllString(eStem, "") == stem {
+			paths = append(paths, filepath.Join(dir, eName))
+		}
+	}
+	return paths, nil
+}
+
+// Struct packetSet contains packets of a recovery set read from recovery files.
+type packetSet struct {
+	creator  string
+	files    map[string]*File
+	main     []byte
+	recovery map[uint32]*RecoverySlice
+	slices   map[string][]*Slice
+}
+
+// Read recovery set from fPath and other recovery files of the same set.
+// Damaged packets are skipped, so the set can be read as long as an intact copy of every
+// packet exists in any of the files.
+func Open(fPath string) (*RecoverySet, error) {
+	paths, err := VolumePaths(fPath)
+	if err != nil {
+		return nil, err
+	}
+	sets := map[string]*packetSet{}
+	setID := ""
+	for _, p := range paths {
+		err := readPackets(p, func(id string, packetType, body []byte, offset, length int64) {
+			ps, ok := sets[id]
+			if !ok {
+				ps = &packetSet{
+					files:    map[string]*File{},
+					recovery: map[uint32]*RecoverySlice{},
+					slices:   map[string][]*Slice{},
+				}
+				sets[id] = ps
+			}
+			switch {
+			case bytes.Equal(packetType, typeMain):
+				ps.main = body
+				if setID == "" {
+					setID = id
+				}
+			case bytes.Equal(packetType, typeFileDesc):
+				if f := parseFileDesc(body); f != nil {
+					ps.files[string(f.ID)] = f
+				}
+			case bytes.Equal(packetType, typeSliceHash):
+				if len(body) >= 16 && (len(body)-16)%20 == 0 {
+					ps.slices[string(body[:16])] = parseSliceHashes(body[16:])
+				}
+			case bytes.Equal(packetType, typeRecoverySlic):
+				exponent := binary.LittleEndian.Uint32(body)
+				ps.recovery[exponent] = &RecoverySlice{
+					Exponent: exponent,
+					Offset:   offset + headerSize + 4,
+					Path:     p,
+					size:     length - headerSize - 4,
+				}
+			case bytes.Equal(packetType, typeCreator):
+				ps.creator = string(bytes.TrimRight(body, "\x00"))
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if setID == "" {
+		return nil, errors.New("main packet is not found")
+	}
+	return sets[setID].recoverySet([]byte(setID))
+}
+
+// Return recovery set from its packets.
+func (ps *packetSet) recoverySet(id []byte) (*RecoverySet, error) {
+	if len(ps.main) < 12 || (len(ps.main)-12)%16 != 0 {
+		return nil, errors.New("invalid main packet")
+	}
+	s := &RecoverySet{
+		Creator:   ps.creator,
+		Files:     []*File{},
+		ID:        id,
+		Recovery:  []*RecoverySlice{},
+		SliceSize: int64(binary.LittleEndian.Uint64(ps.main)),
+	}
+	if s.SliceSize <= 0 || s.SliceSize%4 != 0 {
+		return nil, fmt.Errorf("invalid slice size %d", s.SliceSize)
+	}
+	count := int(binary.LittleEndian.Uint32(ps.main[8:]))
+	if 12+count*16 > len(ps.main) {
+		return nil, errors.New("invalid main packet")
+	}
+	for i := 0; i < count; i++ {
+		fileID := ps.main[12+i*16 : 28+i*16]
+		f, ok := ps.files[string(fileID)]
+		if !ok {
+			return nil, fmt.Errorf("description of file %x is not found", fileID)
+		}
+		if !isValidName(f.Name) {
+			return nil, fmt.Errorf("unsafe file name: '%s'", f.Name)
+		}
+		if slices, ok := ps.slices[string(fileID)]; ok && len(slices) == sliceCount(f.Length, s.SliceSize) {
+			f.Slices = slices
+		}
+		s.Files = append(s.Files, f)
+	}
+	for _, r := range ps.recovery {
+		if r.size == s.SliceSize {
+			s.Recovery = append(s.Recovery, r)
+		}
+	}
+	sort.Slice(s.Recovery, func(i, j int) bool {
+		return s.Recovery[i].Exponent < s.Recovery[j].Exponent
+	})
+	return s, nil
+}
+
+// Return file description from packet body, or nil if it is invalid.
+func parseFileDesc(body []byte) *File {
+	if len(body) < 56 {
+		return nil
+	}
+	return &File{
+		ID:      body[0:16],
+		Hash:    body[16:32],
+		Hash16k: body[32:48],
+		Length:  int64(binary.LittleEndian.Uint64(body[48:56])),
+		Name:    string(bytes.TrimRight(body[56:], "\x00")),
+	}
+}
+
+func parseSliceHashes(body []byte) []*Slice {
+	slices := make([]*Slice, len(body)/20)
+	for i := range slices {
+		entry := body[i*20 : i*20+20]
+		slices[i] = &Slice{
+			Hash:  entry[:16],
+			CRC32: binary.LittleEndian.Uint32(entry[16:]),
+		}
+	}
+	return slices
+}
+
+// Return whether a file name is a relative path which stays inside the base directory.
+func isValidName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return false
+	}
+	for _, c := range strings.Split(filepath.ToSlash(name), "/") {
+		if c == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// Read all intact packets of a file. Body of recovery slice packets only contains the exponent
+// so recovery data is not loaded into memory. Data between packets is skipped.
+func readPackets(fPath string, fn func(setID string, packetType, body []byte, offset, length int64)) error {
+	fHandle, err := os.Open(fPath)
+	if err != nil {
+		return err
+	}
+	defer fHandle.Close()
+
+	r := bufio.NewReaderSize(fHandle, 1024*1024)
+	offset := int64(0)
+	for {
+		header, err := r.Peek(headerSize)
+		if err != nil {
+			return nil
+		}
+		if !bytes.Equal(header[:8], packetMagic) {
+			r.Discard(1)
+			offset++
+			continue
+		}
+		length := int64(binary.LittleEndian.Uint64(header[8:]))
+		packetType := header[48:64]
+		isRecovery := bytes.Equal(packetType, typeRecoverySlic)
+		if length < headerSize || length%4 != 0 || (isRecovery && length < headerSize+4) || (!isRecovery && length > maxPacketSize) {
+			r.Discard(1)
+			offset++
+			continue
+		}
+		expected := bytes.Clone(header[16:32])
+		setID := string(header[32:48])
+		packetType = bytes.Clone(packetType)
+		h := md5.New()
+		h.Write(header[32:64])
+		r.Discard(headerSize)
+
+		bodySize := length - headerSize
+		if isRecovery {
+			bodySize = 4
+		}
+		body := make([]byte, bodySize)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return nil
+		}
+		h.Write(body)
+		if isRecovery {
+			if _, err := io.CopyN(h, r, length-headerSize-4); err != nil {
+				return nil
+			}
+		}
+		if bytes.Equal(h.Sum(nil), expected) {
+			fn(setID, packetType, body, offset, length)
+			offset += length
+			continue
+		}
+
+		// the packet is damaged, look for the next one right after its magic.
+		offset++
+		if _, err := fHandle.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		r.Reset(fHandle)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package par2
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, dir string, sizes map[string]int) map[string][]byte {
+	rnd := rand.New(rand.NewSource(1))
+	contents := map[string][]byte{}
+	for name, size := range sizes {
+		content := make([]byte, size)
+		rnd.Read(content)
+		fPath := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(fPath), 0775)
+		os.WriteFile(fPath, content, 0664)
+		contents[name] = content
+	}
+	return contents
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	contents := writeTestFiles(t, dir, map[string]int{"a.bin": 1000, "sub/b.bin": 2500})
+	os.WriteFile(filepath.Join(dir, "empty.bin"), []byte{}, 0664)
+	fPaths := []string{filepath.Join(dir, "a.bin"), filepath.Join(dir, "sub", "b.bin"), filepath.Join(dir, "empty.bin")}
+	output := filepath.Join(dir, "data.par2")
+
+	s, written, err := Create(fPaths, output, &CreateOptions{Creator: "test", Redundancy: 50, SliceSize: 512}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 + 5 input slices, 4 recovery slices in volumes of 1, 2 and 1 slices.
+	expectedPaths := []string{"data.par2", "data.vol00+01.par2", "data.vol01+02.par2", "data.vol03+01.par2"}
+	if len(written) != len(expectedPaths) {
+		t.Fatalf("wrong recovery files. expected %v actual %v", expectedPaths, written)
+	}
+	for i, p := range expectedPaths {
+		if filepath.Base(written[i]) != p {
+			t.Errorf("wrong recovery file. expected '%s' actual '%s'", p, filepath.Base(written[i]))
+		}
+	}
+	if len(s.Files) != 2 || s.SliceCount() != 7 || len(s.Recovery) != 4 {
+		t.Fatalf("wrong recovery set. files %d slices %d recovery %d", len(s.Files), s.SliceCount(), len(s.Recovery))
+	}
+
+	opened, err := Open(filepath.Join(dir, "data.vol01+02.par2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened.ID, s.ID) || opened.Creator != "test" || opened.SliceSize != 512 || len(opened.Recovery) != 4 {
+		t.Fatalf("wrong recovery set is read")
+	}
+	for i, f := range opened.Files {
+		if f.Name != s.Files[i].Name || !bytes.Equal(f.Hash, s.Files[i].Hash) || len(f.Slices) != len(s.Files[i].Slices) {
+			t.Errorf("wrong file %d. expected '%s' actual '%s'", i, s.Files[i].Name, f.Name)
+		}
+		if !bytes.Equal(f.ID, fileID(f.Hash16k, f.Length, f.Name)) {
+			t.Errorf("wrong ID of file '%s'", f.Name)
+		}
+	}
+
+	// recovery slice of exponent 0 is the XOR of all input slices.
+	parity := make([]byte, 512)
+	for _, f := range opened.Files {
+		content := contents[f.Name]
+		for i := 0; i < len(content); i++ {
+			parity[i%512] ^= content[i]
+		}
+	}
+	actual, err := readRecoverySlice(opened.Recovery[0], opened.SliceSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(actual, parity) {
+		t.Error("wrong recovery slice of exponent 0")
+	}
+}
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage func(dir string)
+		status map[string]string
+		err    error
+	}{
+		{
+			"intact",
+			func(dir string) {},
+			map[string]string{"a.bin": StatusOK, "sub/b.bin": StatusOK, "c.bin": StatusOK},
+			nil,
+		},
+		{
+			"damaged and missing",
+			func(dir string) {
+				content, _ := os.ReadFile(filepath.Join(dir, "a.bin"))
+				content[100] ^= 0xff
+				content[3000] ^= 0xff
+				os.WriteFile(filepath.Join(dir, "a.bin"), content, 0664)
+				os.Remove(filepath.Join(dir, "c.bin"))
+			},
+			map[string]string{"a.bin": StatusFailed, "sub/b.bin": StatusOK, "c.bin": StatusMissing},
+			nil,
+		},
+		{
+			"truncated and extended",
+			func(dir string) {
+				content, _ := os.ReadFile(filepath.Join(dir, "a.bin"))
+				os.WriteFile(filepath.Join(dir, "a.bin"), content[:2100], 0664)
+				content, _ = os.ReadFile(filepath.Join(dir, "sub", "b.bin"))
+				os.WriteFile(filepath.Join(dir, "sub", "b.bin"), append(content, 1, 2, 3), 0664)
+			},
+			map[string]string{"a.bin": StatusFailed, "sub/b.bin": StatusFailed, "c.bin": StatusOK},
+			nil,
+		},
+		{
+			"too many lost slices",
+			func(dir string) {
+				os.Remove(filepath.Join(dir, "a.bin"))
+			},
+			map[string]string{"a.bin": StatusMissing, "sub/b.bin": StatusOK, "c.bin": StatusOK},
+			ErrInsufficientRecovery,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			contents := writeTestFiles(t, dir, map[string]int{"a.bin": 4000, "sub/b.bin": 1500, "c.bin": 700})
+			fPaths := []string{filepath.Join(dir, "a.bin"), filepath.Join(dir, "sub", "b.bin"), filepath.Join(dir, "c.bin")}
+			output := filepath.Join(dir, "data.par2")
+			if _, _, err := Create(fPaths, output, &CreateOptions{Redundancy: 40, SliceSize: 512}, nil); err != nil {
+				t.Fatal(err)
+			}
+			tt.damage(dir)
+
+			s, err := Open(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := Verify(s, dir, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range results {
+				if r.Status != tt.status[r.File.Name] {
+					t.Errorf("wrong status of '%s'. expected '%s' actual '%s'", r.File.Name, tt.status[r.File.Name], r.Status)
+				}
+			}
+			err = Repair(s, results, nil)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("wrong error. expected '%v' actual '%v'", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			for name, content := range contents {
+				actual, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+				if !bytes.Equal(actual, content) {
+					t.Errorf("file '%s' is not repaired", name)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenDamagedPackets(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]int{"a.bin": 3000})
+	output := filepath.Join(dir, "data.par2")
+	if _, _, err := Create([]string{filepath.Join(dir, "a.bin")}, output, &CreateOptions{Redundancy: 100, SliceSize: 1024}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// damage the index file, packets are still available in volumes.
+	content, _ := os.ReadFile(output)
+	for i := 0; i < len(content); i += 50 {
+		content[i] ^= 0xff
+	}
+	os.WriteFile(output, content, 0664)
+	// damage the first recovery slice of the last volume.
+	vPath := filepath.Join(dir, "data.vol01+02.par2")
+	content, _ = os.ReadFile(vPath)
+	content[headerSize+10] ^= 0xff
+	os.WriteFile(vPath, content, 0664)
+
+	s, err := Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Files) != 1 || len(s.Recovery) != 2 || s.Recovery[0].Exponent != 0 || s.Recovery[1].Exponent != 2 {
+		t.Errorf("wrong recovery set. files %d recovery %d", len(s.Files), len(s.Recovery))
+	}
+}
+
+func TestIsValidName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"a.bin", true},
+		{"dir/a.bin", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"../a.bin", false},
+		{"dir/../../a.bin", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := isValidName(tt.name); actual != tt.expected {
+				t.Errorf("wrong result. expected %t actual %t", tt.expected, actual)
+			}
+		})
+	}
+}
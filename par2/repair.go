@@ -0,0 +1,239 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package par2
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+)
+
+// Error returned when there are more lost slices than recovery slices.
+var ErrInsufficientRecovery = errors.New("not enough recovery slices")
+
+// Repair damaged and missing files using results of Verify. Lost slices are reconstructed
+// from intact input slices and recovery slices, then damaged files are rewritten and missing
+// files are created. All reconstructed slices are kept in memory.
+// fn is called after each file is repaired if it is not nil.
+func Repair(s *RecoverySet, results []*VerifyResult, fn func(r *VerifyResult) error) error {
+	first := map[*File]int{}
+	count := 0
+	for _, f := range s.Files {
+		first[f] = count
+		count += sliceCount(f.Length, s.SliceSize)
+	}
+	lost := []int{}
+	for _, r := range results {
+		if r.Status == StatusOK {
+			continue
+		}
+		for _, i := range r.BadSlices {
+			lost = append(lost, first[r.File]+i)
+		}
+	}
+	if len(lost) > len(s.Recovery) {
+		return fmt.Errorf("%w: %d slices are lost but only %d recovery slices are available", ErrInsufficientRecovery, len(lost), len(s.Recovery))
+	}
+	recovered, err := recoverSlices(s, results, first, count, lost)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, s.SliceSize)
+	for _, r := range results {
+		if r.Status == StatusOK {
+			continue
+		}
+		if err := rebuildFile(r, s.SliceSize, first[r.File], recovered, buf); err != nil {
+			return err
+		}
+		r.Status = StatusOK
+		r.BadSlices = []int{}
+		if fn != nil {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Return lost slices by their indexes in the recovery set. Recovery slices with the lowest
+// exponents are used, one for each lost slice.
+func recoverSlices(s *RecoverySet, results []*VerifyResult, first map[*File]int, count int, lost []int) (map[int][]byte, error) {
+	recovered := map[int][]byte{}
+	if len(lost) == 0 {
+		return recovered, nil
+	}
+	constants, err := inputConstants(count)
+	if err != nil {
+		return nil, err
+	}
+	recovery := s.Recovery[:len(lost)]
+	matrix := make([][]uint16, len(recovery))
+	for j, r := range recovery {
+		matrix[j] = make([]uint16, len(lost))
+		for m, i := range lost {
+			matrix[j][m] = gfPow(constants[i], r.Exponent)
+		}
+	}
+	inverse, err := gfInvert(matrix)
+	if err != nil {
+		return nil, err
+	}
+
+	// remove contribution of intact slices from recovery slices, what remains
+	// is the contribution of lost slices only.
+	sums := make([][]byte, len(recovery))
+	for j, r := range recovery {
+		sums[j], err = readRecoverySlice(r, s.SliceSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+	isLost := make([]bool, count)
+	for _, i := range lost {
+		isLost[i] = true
+	}
+	buf := make([]byte, s.SliceSize)
+	factors := make([]uint16, len(recovery))
+	for _, r := range results {
+		if r.Status == StatusMissing {
+			continue
+		}
+		err := func() error {
+			fHandle, err := os.Open(r.LocalPath)
+			if err != nil {
+				return err
+			}
+			defer fHandle.Close()
+
+			for i := 0; i < sliceCount(r.File.Length, s.SliceSize); i++ {
+				index := first[r.File] + i
+				if isLost[index] {
+					continue
+				}
+				if _, _, err := readSlice(fHandle, i, s.SliceSize, r.File.Length, buf); err != nil {
+					return err
+				}
+				for j, rs := range recovery {
+					factors[j] = gfPow(constants[index], rs.Exponent)
+				}
+				gfMulAddAll(sums, buf, factors)
+			}
+			return nil
+		}()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	outputs := make([][]byte, len(lost))
+	for m := range outputs {
+		outputs[m] = make([]byte, s.SliceSize)
+	}
+	factors = make([]uint16, len(lost))
+	for j, sum := range sums {
+		for m := range lost {
+			factors[m] = inverse[m][j]
+		}
+		gfMulAddAll(outputs, sum, factors)
+	}
+	for m, i := range lost {
+		recovered[i] = outputs[m]
+	}
+	return recovered, nil
+}
+
+func readRecoverySlice(r *RecoverySlice, sliceSize int64) ([]byte, error) {
+	fHandle, err := os.Open(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer fHandle.Close()
+
+	data := make([]byte, sliceSize)
+	if _, err := fHandle.ReadAt(data, r.Offset); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Write a file using its intact slices and recovered slices to a temporary file, which replaces
+// the file once its MD5 matches.
+func rebuildFile(r *VerifyResult, sliceSize int64, first int, recovered map[int][]byte, buf []byte) error {
+	dir := filepath.Dir(r.LocalPath)
+	if err := filesystem.CreateDirectoryRecursive(dir); err != nil {
+		return err
+	}
+	var src *os.File
+	mode := os.FileMode(0664)
+	if r.Status != StatusMissing {
+		fHandle, err := os.Open(r.LocalPath)
+		if err != nil {
+			return err
+		}
+		defer fHandle.Close()
+		src = fHandle
+		if fi, err := fHandle.Stat(); err == nil {
+			mode = fi.Mode().Perm()
+		}
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(r.LocalPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := md5.New()
+	for i := 0; i < sliceCount(r.File.Length, sliceSize); i++ {
+		size := r.File.Length - int64(i)*sliceSize
+		if size > sliceSize {
+			size = sliceSize
+		}
+		data, ok := recovered[first+i]
+		if !ok {
+			if _, _, err := readSlice(src, i, sliceSize, r.File.Length, buf); err != nil {
+				return err
+			}
+			data = buf
+		}
+		h.Write(data[:size])
+		if _, err := tmp.Write(data[:size]); err != nil {
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if !bytes.Equal(h.Sum(nil), r.File.Hash) {
+		return fmt.Errorf("file '%s' is still damaged after repair", r.LocalPath)
+	}
+	if src != nil {
+		src.Close()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.LocalPath)
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package par2
+
+import (
+	"bytes"
+	"crypto/md5"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Statuses of files after verification.
+const (
+	StatusOK      = "OK"
+	StatusFailed  = "FAILED"
+	StatusMissing = "MISSING"
+)
+
+// Struct VerifyResult is the verification result of a single file.
+type VerifyResult struct {
+	File      *File
+	LocalPath string
+	Status    string
+	BadSlices []int // indexes of slices of the file which are damaged or missing
+}
+
+// Verify files of a recovery set, their names are resolved relative to dir.
+// Slices are only checked at their original offsets, so inserting or removing data
+// in the middle of a file damages all slices after it.
+// fn is called for every file in the order of the recovery set if it is not nil.
+func Verify(s *RecoverySet, dir string, fn func(r *VerifyResult) error) ([]*VerifyResult, error) {
+	buf := make([]byte, s.SliceSize)
+	results := []*VerifyResult{}
+	for _, f := range s.Files {
+		r, err := verifyFile(f, filepath.Join(dir, filepath.FromSlash(f.Name)), s.SliceSize, buf)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+		if fn != nil {
+			if err := fn(r); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return results, nil
+}
+
+func verifyFile(f *File, localPath string, sliceSize int64, buf []byte) (*VerifyResult, error) {
+	r := &VerifyResult{
+		File:      f,
+		LocalPath: localPath,
+		BadSlices: []int{},
+	}
+	count := sliceCount(f.Length, sliceSize)
+	fi, err := os.Stat(localPath)
+	if err != nil || !fi.Mode().IsRegular() {
+		r.Status = StatusMissing
+		r.BadSlices = sliceIndexes(count)
+		return r, nil
+	}
+	fHandle, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fHandle.Close()
+
+	h := md5.New()
+	for i := 0; i < count; i++ {
+		n, complete, err := readSlice(fHandle, i, sliceSize, f.Length, buf)
+		if err != nil {
+			return nil, err
+		}
+		h.Write(buf[:n])
+		if f.Slices == nil {
+			continue
+		}
+		if !complete || !bytes.Equal(md5Sum(buf), f.Slices[i].Hash) || crc32.ChecksumIEEE(buf) != f.Slices[i].CRC32 {
+			r.BadSlices = append(r.BadSlices, i)
+		}
+	}
+	if fi.Size() == f.Length && bytes.Equal(h.Sum(nil), f.Hash) {
+		r.Status = StatusOK
+		r.BadSlices = []int{}
+		return r, nil
+	}
+	r.Status = StatusFailed
+	if f.Slices == nil {
+		r.BadSlices = sliceIndexes(count)
+	}
+	return r, nil
+}
+
+// Read a slice of a file having specified length into buf, padded with zeros to slice size.
+// Return number of bytes read and whether the slice is read completely.
+func readSlice(r io.ReaderAt, index int, sliceSize, length int64, buf []byte) (int, bool, error) {
+	offset := int64(index) * sliceSize
+	size := length - offset
+	if size > sliceSize {
+		size = sliceSize
+	}
+	n, err := r.ReadAt(buf[:size], offset)
+	if err != nil && err != io.EOF {
+		return 0, false, err
+	}
+	zero(buf[n:])
+	return n, int64(n) == size, nil
+}
+
+// Return indexes of all slices.
+func sliceIndexes(count int) []int {
+	indexes := make([]int, count)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
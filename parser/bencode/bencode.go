@@ -0,0 +1,195 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+// Package bencode implements encoding used by BitTorrent metainfo files.
+// Values are mapped to Go types as below:
+//   - integer: int64 (int is also accepted when encoding)
+//   - byte string: string ([]byte is also accepted when encoding)
+//   - list: []interface{}
+//   - dictionary: map[string]interface{}
+package bencode
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Return bencoded form of v. Dictionary keys are sorted as required by the specification.
+func Marshal(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := encode(buf, v); err != nil {
+		return []byte{}, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encode(buf *bytes.Buffer, v interface{}) error {
+	switch val := v.(type) {
+	case int:
+		fmt.Fprintf(buf, "i%de", val)
+	case int64:
+		fmt.Fprintf(buf, "i%de", val)
+	case string:
+		fmt.Fprintf(buf, "%d:", len(val))
+		buf.WriteString(val)
+	case []byte:
+		fmt.Fprintf(buf, "%d:", len(val))
+		buf.Write(val)
+	case []interface{}:
+		buf.WriteByte('l')
+		for _, item := range val {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	case []string:
+		buf.WriteByte('l')
+		for _, item := range val {
+			encode(buf, item)
+		}
+		buf.WriteByte('e')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('d')
+		for _, k := range keys {
+			encode(buf, k)
+			if err := encode(buf, val[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	default:
+		return fmt.Errorf("unsupported bencode type %T", v)
+	}
+	return nil
+}
+
+// Parse bencoded data which must contain exactly one value.
+func Unmarshal(data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+	v, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("unexpected data at offset %d", d.pos)
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) decode() (interface{}, error) {
+	if d.pos >= len(d.data) {
+		return nil, fmt.Errorf("unexpected end of data")
+	}
+	switch c := d.data[d.pos]; {
+	case c == 'i':
+		d.pos++
+		return d.decodeInt('e')
+	case c >= '0' && c <= '9':
+		return d.decodeString()
+	case c == 'l':
+		d.pos++
+		list := []interface{}{}
+		for !d.consume('e') {
+			item, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	case c == 'd':
+		d.pos++
+		dict := map[string]interface{}{}
+		for !d.consume('e') {
+			if d.pos < len(d.data) && (d.data[d.pos] < '0' || d.data[d.pos] > '9') {
+				return nil, fmt.Errorf("invalid dictionary key at offset %d", d.pos)
+			}
+			key, err := d.decodeString()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := dict[key]; ok {
+				return nil, fmt.Errorf("duplicated dictionary key '%s'", key)
+			}
+			value, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			dict[key] = value
+		}
+		return dict, nil
+	default:
+		return nil, fmt.Errorf("invalid token '%c' at offset %d", c, d.pos)
+	}
+}
+
+// Read an integer terminated by end, leading zeros and negative zero are rejected.
+func (d *decoder) decodeInt(end byte) (int64, error) {
+	start := d.pos
+	i := bytes.IndexByte(d.data[start:], end)
+	if i < 0 {
+		return 0, fmt.Errorf("unterminated integer at offset %d", start)
+	}
+	lit := string(d.data[start : start+i])
+	digits := lit
+	if len(digits) > 0 && digits[0] == '-' {
+		digits = digits[1:]
+	}
+	if digits == "" || (digits[0] == '0' && len(lit) > 1) {
+		return 0, fmt.Errorf("invalid integer '%s' at offset %d", lit, start)
+	}
+	n, err := strconv.ParseInt(lit, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer '%s' at offset %d", lit, start)
+	}
+	d.pos = start + i + 1
+	return n, nil
+}
+
+func (d *decoder) decodeString() (string, error) {
+	start := d.pos
+	n, err := d.decodeInt(':')
+	if err != nil {
+		return "", err
+	}
+	if n < 0 || n > int64(len(d.data)-d.pos) {
+		return "", fmt.Errorf("invalid string length %d at offset %d", n, start)
+	}
+	s := string(d.data[d.pos : d.pos+int(n)])
+	d.pos += int(n)
+	return s, nil
+}
+
+func (d *decoder) consume(c byte) bool {
+	if d.pos < len(d.data) && d.data[d.pos] == c {
+		d.pos++
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package bencode
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"int", 42, "i42e"},
+		{"negative int", int64(-3), "i-3e"},
+		{"string", "spam", "4:spam"},
+		{"empty string", "", "0:"},
+		{"bytes", []byte{0x00, 0xff}, "2:\x00\xff"},
+		{"list", []interface{}{"spam", 42}, "l4:spami42ee"},
+		{"string list", []string{"a", "b"}, "l1:a1:be"},
+		{"sorted dict", map[string]interface{}{"spam": "eggs", "cow": "moo"}, "d3:cow3:moo4:spam4:eggse"},
+		{"nested", map[string]interface{}{"a": []interface{}{map[string]interface{}{}}}, "d1:aldeee"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("wrong encoding. expected '%s' actual '%s'", tt.expected, data)
+			}
+		})
+	}
+
+	if _, err := Marshal(1.5); err == nil {
+		t.Errorf("float should not be supported")
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected interface{}
+	}{
+		{"int", "i42e", int64(42)},
+		{"zero", "i0e", int64(0)},
+		{"negative int", "i-3e", int64(-3)},
+		{"string", "4:spam", "spam"},
+		{"list", "l4:spami42ee", []interface{}{"spam", int64(42)}},
+		{"dict", "d3:cow3:moo4:spaml1:aee", map[string]interface{}{"cow": "moo", "spam": []interface{}{"a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := Unmarshal([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(v, tt.expected) {
+				t.Errorf("wrong value. expected %#v actual %#v", tt.expected, v)
+			}
+		})
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"leading zero", "i03e"},
+		{"negative zero", "i-0e"},
+		{"unterminated int", "i42"},
+		{"short string", "5:spam"},
+		{"unterminated list", "l4:spam"},
+		{"non-string key", "di1ei2ee"},
+		{"duplicated key", "d1:ai1e1:ai2ee"},
+		{"trailing data", "i1ei2e"},
+		{"unknown token", "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Unmarshal([]byte(tt.data)); err == nil {
+				t.Errorf("expected error for '%s'", tt.data)
+			}
+		})
+	}
+}
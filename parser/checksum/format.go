@@ -0,0 +1,95 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package checksum
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tforce-io/tf-golib/opx"
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
+)
+
+// Return a checksum line in GNU style: hash *path.
+// Line will be prefixed with a backslash if the path needs escaping.
+func FormatGnu(item *ChecksumItem) string {
+	path, escaped := EscapePath(item.Path)
+	prefix := opx.Ternary(escaped, "\\", "")
+	mode := opx.Ternary(item.BinaryMode, "*", " ")
+	return fmt.Sprintf("%s%s %s%s", prefix, item.Hash, mode, path)
+}
+
+// Return a checksum line in BSD style: ALGO (path) = hash.
+// Line will be prefixed with a backslash if the path needs escaping.
+func FormatBsd(item *ChecksumItem) string {
+	path, escaped := EscapePath(item.Path)
+	prefix := opx.Ternary(escaped, "\\", "")
+	return fmt.Sprintf("%s%s (%s) = %s", prefix, hasher.BsdTag(item.Algorithm), path, item.Hash)
+}
+
+// Escape backslash, line feed and carriage return in path the same way as GNU coreutils.
+// Return true if the path has been escaped.
+func EscapePath(path string) (string, bool) {
+	if !strings.ContainsAny(path, "\\\n\r") {
+		return path, false
+	}
+	var sb strings.Builder
+	for _, ch := range path {
+		switch ch {
+		case '\\':
+			sb.WriteString("\\\\")
+		case '\n':
+			sb.WriteString("\\n")
+		case '\r':
+			sb.WriteString("\\r")
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+	return sb.String(), true
+}
+
+// Reverse escaped path created by EscapePath.
+func UnescapePath(path string) (string, error) {
+	var sb strings.Builder
+	escaping := false
+	for _, ch := range path {
+		if !escaping {
+			if ch == '\\' {
+				escaping = true
+			} else {
+				sb.WriteRune(ch)
+			}
+			continue
+		}
+		switch ch {
+		case '\\':
+			sb.WriteRune('\\')
+		case 'n':
+			sb.WriteRune('\n')
+		case 'r':
+			sb.WriteRune('\r')
+		default:
+			return "", fmt.Errorf("invalid escape sequence '\\%c' in path '%s'", ch, path)
+		}
+		escaping = false
+	}
+	if escaping {
+		return "", fmt.Errorf("invalid escape sequence at the end of path '%s'", path)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package checksum
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	var tests = []struct {
+		name string
+		item *ChecksumItem
+		gnu  string
+		bsd  string
+	}{
+		{"Binary mode", &ChecksumItem{"sha1", "f572d396fae9206628714fb2ce00f72e94f2258f", true, "go.mod"}, "f572d396fae9206628714fb2ce00f72e94f2258f *go.mod", "SHA1 (go.mod) = f572d396fae9206628714fb2ce00f72e94f2258f"},
+		{"Text mode", &ChecksumItem{"md5", "a3c51dd48bf7fabbbd354bd4e16b0ec1", false, "file system/file.go"}, "a3c51dd48bf7fabbbd354bd4e16b0ec1  file system/file.go", "MD5 (file system/file.go) = a3c51dd48bf7fabbbd354bd4e16b0ec1"},
+		{"BLAKE2b tag", &ChecksumItem{"blake2b-512", "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce", true, "go.mod"}, "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce *go.mod", "BLAKE2b (go.mod) = 786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce"},
+		{"Escaped path", &ChecksumItem{"md5", "a3c51dd48bf7fabbbd354bd4e16b0ec1", true, "go\nmod\\file"}, "\\a3c51dd48bf7fabbbd354bd4e16b0ec1 *go\\nmod\\\\file", "\\MD5 (go\\nmod\\\\file) = a3c51dd48bf7fabbbd354bd4e16b0ec1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if line := FormatGnu(tt.item); line != tt.gnu {
+				t.Errorf("Wrong GNU line. Expected '%s'. Actual '%s'.", tt.gnu, line)
+			}
+			if line := FormatBsd(tt.item); line != tt.bsd {
+				t.Errorf("Wrong BSD line. Expected '%s'. Actual '%s'.", tt.bsd, line)
+			}
+		})
+	}
+}
+
+func TestEscapePath(t *testing.T) {
+	var tests = []struct {
+		name    string
+		path    string
+		escaped string
+	}{
+		{"Plain", "dir/file.go", "dir/file.go"},
+		{"Backslash", "dir\\file.go", "dir\\\\file.go"},
+		{"Line feed", "file\n.go", "file\\n.go"},
+		{"Carriage return", "file\r\n.go", "file\\r\\n.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			escaped, _ := EscapePath(tt.path)
+			if escaped != tt.escaped {
+				t.Errorf("Wrong escaped path. Expected %q. Actual %q.", tt.escaped, escaped)
+			}
+			path, err := UnescapePath(escaped)
+			if err != nil {
+				t.Error(err)
+			}
+			if path != tt.path {
+				t.Errorf("Wrong unescaped path. Expected %q. Actual %q.", tt.path, path)
+			}
+		})
+	}
+}
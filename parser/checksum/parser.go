@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"io"
 	"strings"
+
+	"github.com/tforceaio/tf-unifiler-go/crypto/hasher"
 )
 
 type Parser struct {
@@ -40,10 +42,14 @@ func (p *Parser) Parse() ([]*ChecksumItem, error) {
 
 	for {
 		item := &ChecksumItem{}
+		var first string
+		var escaped bool
 		if tok, lit := p.scan(); tok == EOF {
 			break
-		} else if tok == WORD {
-			item.Hash = lit
+		} else if tok == WORD && lit != "\\" {
+			// GNU coreutils prefixes lines having escaped path with a backslash.
+			escaped = strings.HasPrefix(lit, "\\")
+			first = strings.TrimPrefix(lit, "\\")
 		} else {
 			return []*ChecksumItem{}, fmt.Errorf("invalid token. expected %s actual '%s'", "hash", lit)
 		}
@@ -52,39 +58,22 @@ func (p *Parser) Parse() ([]*ChecksumItem, error) {
 			return []*ChecksumItem{}, fmt.Errorf("invalid token. expected %s actual '%s'", "whitespace", lit)
 		}
 
-		pathSlice := []string{}
-		if tok, lit := p.scan(); tok == ASTERISK {
-			item.BinaryMode = true
-		} else if tok == WORD || tok == SPACE {
-			p.unscan()
-		} else {
-			return []*ChecksumItem{}, fmt.Errorf("invalid token. expected %s actual '%s'", "whitespace", lit)
-		}
-
-		if tok, lit := p.scan(); tok == SPACE {
-			return []*ChecksumItem{}, fmt.Errorf("invalid token. expected %s actual '%s'", "path", lit)
+		if isTag(first) && p.peekTaggedPath() {
+			if err := p.parseTagged(item, first); err != nil {
+				return []*ChecksumItem{}, err
+			}
 		} else {
-			p.unscan()
-		}
-
-		var lastTok token
-		for {
-			if tok, lit := p.scan(); tok == SPACE || tok == WORD {
-				lastTok = tok
-				pathSlice = append(pathSlice, lit)
-			} else if tok == CR || tok == LF || tok == EOF {
-				p.unscan()
-				item.Path = strings.Join(pathSlice, "")
-				break
-			} else {
-				return []*ChecksumItem{}, fmt.Errorf("invalid token. expected %s actual '%s'", "path", lit)
+			item.Hash = first
+			if err := p.parseUntagged(item); err != nil {
+				return []*ChecksumItem{}, err
 			}
 		}
-		if len(pathSlice) == 0 {
-			return []*ChecksumItem{}, fmt.Errorf("invalid token. expected %s actual '%s'", "path", p.buf.lit)
-		}
-		if lastTok == SPACE {
-			return []*ChecksumItem{}, fmt.Errorf("invalid token. expected %s actual '%s'", "path", " ")
+		if escaped {
+			path, err := UnescapePath(item.Path)
+			if err != nil {
+				return []*ChecksumItem{}, err
+			}
+			item.Path = path
 		}
 
 		if tok, lit := p.scan(); tok == CR {
@@ -106,6 +95,77 @@ func (p *Parser) Parse() ([]*ChecksumItem, error) {
 	return items, nil
 }
 
+// Parse the remaining of a line in GNU style: [*]path.
+// Trailing whitespaces are considered part of the path.
+func (p *Parser) parseUntagged(item *ChecksumItem) error {
+	pathSlice := []string{}
+	if tok, lit := p.scan(); tok == ASTERISK {
+		item.BinaryMode = true
+	} else if tok == WORD || tok == SPACE {
+		p.unscan()
+	} else {
+		return fmt.Errorf("invalid token. expected %s actual '%s'", "whitespace", lit)
+	}
+
+	if tok, lit := p.scan(); tok == SPACE {
+		return fmt.Errorf("invalid token. expected %s actual '%s'", "path", lit)
+	} else {
+		p.unscan()
+	}
+
+	for {
+		if tok, lit := p.scan(); tok == SPACE || tok == WORD {
+			pathSlice = append(pathSlice, lit)
+		} else if tok == CR || tok == LF || tok == EOF {
+			p.unscan()
+			item.Path = strings.Join(pathSlice, "")
+			break
+		} else {
+			return fmt.Errorf("invalid token. expected %s actual '%s'", "path", lit)
+		}
+	}
+	if len(pathSlice) == 0 {
+		return fmt.Errorf("invalid token. expected %s actual '%s'", "path", p.buf.lit)
+	}
+	return nil
+}
+
+// Parse the remaining of a line in BSD style: (path) = hash.
+func (p *Parser) parseTagged(item *ChecksumItem, tag string) error {
+	var sb strings.Builder
+	for {
+		if tok, lit := p.scan(); tok == SPACE || tok == WORD || tok == ASTERISK {
+			sb.WriteString(lit)
+		} else if tok == CR || tok == LF || tok == EOF {
+			p.unscan()
+			break
+		} else {
+			return fmt.Errorf("invalid token. expected %s actual '%s'", "path", lit)
+		}
+	}
+	rest := sb.String()
+	sepIndex := strings.LastIndex(rest, ") = ")
+	if sepIndex < 1 {
+		return fmt.Errorf("invalid token. expected %s actual '%s'", "') = '", rest)
+	}
+	hash := rest[sepIndex+len(") = "):]
+	if hash == "" || strings.ContainsAny(hash, " \t") {
+		return fmt.Errorf("invalid token. expected %s actual '%s'", "hash", hash)
+	}
+	item.Algorithm = hasher.NameByBsdTag(tag)
+	item.BinaryMode = true
+	item.Hash = hash
+	item.Path = rest[1:sepIndex]
+	return nil
+}
+
+// Check whether the next token is the beginning of a BSD style path.
+func (p *Parser) peekTaggedPath() bool {
+	tok, lit := p.scan()
+	p.unscan()
+	return tok == WORD && strings.HasPrefix(lit, "(")
+}
+
 func (p *Parser) scan() (token, string) {
 	if p.buf.n != 0 {
 		p.buf.n = 0
@@ -120,3 +180,17 @@ func (p *Parser) scan() (token, string) {
 func (p *Parser) unscan() {
 	p.buf.n = 1
 }
+
+// Check whether a literal is an algorithm tag rather than a hexadecimal hash.
+func isTag(lit string) bool {
+	for _, ch := range lit {
+		if !isHexDigit(ch) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHexDigit(ch rune) bool {
+	return (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
@@ -61,6 +61,9 @@ func TestParserSyntax(t *testing.T) {
 		{"Path with multiple segment", "a3c51dd48bf7fabbbd354bd4e16b0ec1 filesystem/file.go", "filesystem/file.go", false, "a3c51dd48bf7fabbbd354bd4e16b0ec1"},
 		{"Path with space #1", "a3c51dd48bf7fabbbd354bd4e16b0ec1 file system/file.go", "file system/file.go", false, "a3c51dd48bf7fabbbd354bd4e16b0ec1"},
 		{"Path with space #2", "a3c51dd48bf7fabbbd354bd4e16b0ec1 *file system/file.go", "file system/file.go", true, "a3c51dd48bf7fabbbd354bd4e16b0ec1"},
+		{"Path with trailing space", "a3c51dd48bf7fabbbd354bd4e16b0ec1 *go.mod  \n", "go.mod  ", true, "a3c51dd48bf7fabbbd354bd4e16b0ec1"},
+		{"Escaped path", "\\a3c51dd48bf7fabbbd354bd4e16b0ec1 *go\\nmod\\\\file", "go\nmod\\file", true, "a3c51dd48bf7fabbbd354bd4e16b0ec1"},
+		{"Escaped BSD path", "\\MD5 (go\\r\\nmod) = a3c51dd48bf7fabbbd354bd4e16b0ec1", "go\r\nmod", true, "a3c51dd48bf7fabbbd354bd4e16b0ec1"},
 	}
 
 	for _, tt := range tests {
@@ -101,14 +104,98 @@ func TestParserError(t *testing.T) {
 		{"missing hash", " *go.mod\n", "invalid token. expected hash actual ' '"},
 		{"space before path", "a3c51dd48bf7fabbbd354bd4e16b0ec1  * go.mod", "invalid token. expected path actual ' '"},
 		{"space before path", "a3c51dd48bf7fabbbd354bd4e16b0ec1  * go.mod\n", "invalid token. expected path actual ' '"},
-		{"space after path", "a3c51dd48bf7fabbbd354bd4e16b0ec1  *go.mod ", "invalid token. expected path actual ' '"},
-		{"space after path", "a3c51dd48bf7fabbbd354bd4e16b0ec1  *go.mod \n", "invalid token. expected path actual ' '"},
+		{"space after path", "a3c51dd48bf7fabbbd354bd4e16b0ec1  *go.mod ", ""},
+		{"space after path", "a3c51dd48bf7fabbbd354bd4e16b0ec1  *go.mod \n", ""},
 		{"space before hash", " a3c51dd48bf7fabbbd354bd4e16b0ec1 go.mod", "invalid token. expected hash actual ' '"},
 		{"space before hash", " a3c51dd48bf7fabbbd354bd4e16b0ec1 go.mod\n", "invalid token. expected hash actual ' '"},
 		{"space in hash", "a3c51dd48bf7fabbbd 354bd4e16b0ec1 go.mod", ""},
 		{"space in hash", "a3c51dd48bf7fabbbd 354bd4e16b0ec1 go.mod\n", ""},
 		{"space in hash", "a3c51dd48bf7fabbbd 354bd4e16b0ec1 *go.mod", "invalid token. expected path actual '*'"},
 		{"space in hash", "a3c51dd48bf7fabbbd 354bd4e16b0ec1 *go.mod\n", "invalid token. expected path actual '*'"},
+		{"backslash only", "\\ a3c51dd48bf7fabbbd354bd4e16b0ec1 go.mod", "invalid token. expected hash actual '\\'"},
+		{"invalid escape", "\\a3c51dd48bf7fabbbd354bd4e16b0ec1 go\\t.mod", "invalid escape sequence '\\t' in path 'go\\t.mod'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(strings.NewReader(tt.content))
+			_, err := p.Parse()
+			errs := extension.ErrString(err)
+			if errs != tt.err {
+				t.Errorf("wrong error. Expected %q. Actual %q.", tt.err, errs)
+			}
+		})
+	}
+}
+
+func TestParserTagged(t *testing.T) {
+	var tests = []struct {
+		name      string
+		content   string
+		algorithm string
+		path      string
+		hash      string
+	}{
+		{"SHA-256", "SHA256 (go.mod) = 87428fc522803d31065e7bce3cf03fe475096631e5e07bbd7a0fde60c4cf25c7", "sha256", "go.mod", "87428fc522803d31065e7bce3cf03fe475096631e5e07bbd7a0fde60c4cf25c7"},
+		{"MD5", "MD5 (go.mod) = a3c51dd48bf7fabbbd354bd4e16b0ec1\n", "md5", "go.mod", "a3c51dd48bf7fabbbd354bd4e16b0ec1"},
+		{"Path with space", "SHA1 (file system/file.go) = f572d396fae9206628714fb2ce00f72e94f2258f", "sha1", "file system/file.go", "f572d396fae9206628714fb2ce00f72e94f2258f"},
+		{"Path with parentheses", "SHA1 ((1) a = b.txt) = f572d396fae9206628714fb2ce00f72e94f2258f", "sha1", "(1) a = b.txt", "f572d396fae9206628714fb2ce00f72e94f2258f"},
+		{"Path with asterisk", "SHA1 (*go.mod) = f572d396fae9206628714fb2ce00f72e94f2258f\r\n", "sha1", "*go.mod", "f572d396fae9206628714fb2ce00f72e94f2258f"},
+		{"Hyphenated tag", "SHA3-256 (go.mod) = 87428fc522803d31065e7bce3cf03fe475096631e5e07bbd7a0fde60c4cf25c7", "sha3-256", "go.mod", "87428fc522803d31065e7bce3cf03fe475096631e5e07bbd7a0fde60c4cf25c7"},
+		{"BLAKE2b tag", "BLAKE2b (go.mod) = 786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce", "blake2b-512", "go.mod", "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce"},
+		{"BLAKE2b-256 tag", "BLAKE2b-256 (go.mod) = 0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8", "blake2b-256", "go.mod", "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8"},
+		{"GNU path with parentheses", "a3c51dd48bf7fabbbd354bd4e16b0ec1 (go.mod)", "", "(go.mod)", "a3c51dd48bf7fabbbd354bd4e16b0ec1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(strings.NewReader(tt.content))
+			items, err := p.Parse()
+			if err != nil {
+				t.Fatal(err)
+			}
+			item := items[0]
+			if item.Algorithm != tt.algorithm {
+				t.Errorf("Wrong algorithm. Expected '%s'. Actual '%s'.", tt.algorithm, item.Algorithm)
+			}
+			if item.Path != tt.path {
+				t.Errorf("Wrong path. Expected '%s'. Actual '%s'.", tt.path, item.Path)
+			}
+			if item.Hash != tt.hash {
+				t.Errorf("Wrong hash. Expected '%s'. Actual '%s'.", tt.hash, item.Hash)
+			}
+		})
+	}
+}
+
+func TestParserMixed(t *testing.T) {
+	content := "MD5 (go.mod) = a3c51dd48bf7fabbbd354bd4e16b0ec1\n" +
+		"SHA1 (go.mod) = f572d396fae9206628714fb2ce00f72e94f2258f\n" +
+		"ca47868bca0d531a275f20e99eb04ba1 *go.sum\n"
+	p := NewParser(strings.NewReader(content))
+	items, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	algorithms := []string{"md5", "sha1", ""}
+	if len(items) != len(algorithms) {
+		t.Fatalf("Invalid number of items. Expected %d. Actual %d.", len(algorithms), len(items))
+	}
+	for i, item := range items {
+		if item.Algorithm != algorithms[i] {
+			t.Errorf("Wrong algorithm. Expected '%s'. Actual '%s'.", algorithms[i], item.Algorithm)
+		}
+	}
+}
+
+func TestParserTaggedError(t *testing.T) {
+	var tests = []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"missing separator", "SHA1 (go.mod)", "invalid token. expected ') = ' actual '(go.mod)'"},
+		{"missing hash", "SHA1 (go.mod) = \n", "invalid token. expected hash actual ''"},
 	}
 
 	for _, tt := range tests {
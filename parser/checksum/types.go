@@ -17,6 +17,7 @@
 package checksum
 
 type ChecksumItem struct {
+	Algorithm  string // algorithm name resolved from tag, only available for BSD style lines
 	Hash       string
 	BinaryMode bool
 	Path       string
@@ -0,0 +1,124 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+/*
+Package dfxml writes hash lists in Digital Forensics XML, in the same layout
+as hashdeep so they can be consumed by common forensic tools.
+*/
+package dfxml
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// Struct DFXML is the root element of a DFXML document.
+type DFXML struct {
+	XMLName       xml.Name      `xml:"dfxml"`
+	OutputVersion string        `xml:"xmloutputversion,attr"`
+	Metadata      *Metadata     `xml:"metadata"`
+	Creator       *Creator      `xml:"creator"`
+	FileObjects   []*FileObject `xml:"fileobject"`
+}
+
+// Struct Metadata describes type of the document using Dublin Core.
+type Metadata struct {
+	Xmlns    string `xml:"xmlns,attr"`
+	XmlnsXsi string `xml:"xmlns:xsi,attr"`
+	XmlnsDc  string `xml:"xmlns:dc,attr"`
+	Type     string `xml:"dc:type"`
+}
+
+// Struct Creator describes program creating the document.
+type Creator struct {
+	Version              string                `xml:"version,attr"`
+	Program              string                `xml:"program"`
+	ProgramVersion       string                `xml:"version"`
+	ExecutionEnvironment *ExecutionEnvironment `xml:"execution_environment"`
+}
+
+// Struct ExecutionEnvironment describes invocation of the program.
+type ExecutionEnvironment struct {
+	CommandLine string `xml:"command_line"`
+	StartTime   string `xml:"start_time"`
+}
+
+// Struct FileObject contains hashes of a file.
+type FileObject struct {
+	Filename    string        `xml:"filename"`
+	Filesize    int64         `xml:"filesize"`
+	HashDigests []*HashDigest `xml:"hashdigest"`
+}
+
+// Struct HashDigest is a hex encoded hash, Type is upper case algorithm name.
+type HashDigest struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Return new hash list document created by program.
+func NewHashList(program, version, commandLine, startTime string) *DFXML {
+	return &DFXML{
+		OutputVersion: "1.0",
+		Metadata: &Metadata{
+			Xmlns:    "http://www.forensicswiki.org/wiki/Category:Digital_Forensics_XML",
+			XmlnsXsi: "http://www.w3.org/2001/XMLSchema-instance",
+			XmlnsDc:  "http://purl.org/dc/elements/1.1/",
+			Type:     "Hash List",
+		},
+		Creator: &Creator{
+			Version:        "1.0",
+			Program:        program,
+			ProgramVersion: version,
+			ExecutionEnvironment: &ExecutionEnvironment{
+				CommandLine: commandLine,
+				StartTime:   startTime,
+			},
+		},
+		FileObjects: []*FileObject{},
+	}
+}
+
+// Add a file with its hashes keyed by algorithm name, hashes are ordered as algorithms.
+func (d *DFXML) Add(fPath string, size int64, algorithms []string, hashes map[string]string) {
+	obj := &FileObject{
+		Filename:    fPath,
+		Filesize:    size,
+		HashDigests: make([]*HashDigest, len(algorithms)),
+	}
+	for i, a := range algorithms {
+		obj.HashDigests[i] = &HashDigest{
+			Type:  strings.ToUpper(a),
+			Value: hashes[a],
+		}
+	}
+	d.FileObjects = append(d.FileObjects, obj)
+}
+
+// Write the document as indented XML.
+func (d *DFXML) Encode(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(d); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
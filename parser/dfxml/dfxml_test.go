@@ -0,0 +1,58 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package dfxml
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	d := NewHashList("TF Unifiler", "0.5.0", "unifiler checksum create -f dfxml d", "2024-08-13T00:00:00Z")
+	d.Add("d/a&b.txt", 6, []string{"md5", "sha256"}, map[string]string{
+		"md5":    "b1946ac92492d2347c6235b4d2611184",
+		"sha256": "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+	})
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<dfxml xmloutputversion="1.0">
+  <metadata xmlns="http://www.forensicswiki.org/wiki/Category:Digital_Forensics_XML" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:type>Hash List</dc:type>
+  </metadata>
+  <creator version="1.0">
+    <program>TF Unifiler</program>
+    <version>0.5.0</version>
+    <execution_environment>
+      <command_line>unifiler checksum create -f dfxml d</command_line>
+      <start_time>2024-08-13T00:00:00Z</start_time>
+    </execution_environment>
+  </creator>
+  <fileobject>
+    <filename>d/a&amp;b.txt</filename>
+    <filesize>6</filesize>
+    <hashdigest type="MD5">b1946ac92492d2347c6235b4d2611184</hashdigest>
+    <hashdigest type="SHA256">5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03</hashdigest>
+  </fileobject>
+</dfxml>
+`
+	out := &bytes.Buffer{}
+	if err := d.Encode(out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Errorf("Wrong document. Expected '%s'. Actual '%s'.", expected, out.String())
+	}
+}
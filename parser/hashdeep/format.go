@@ -0,0 +1,65 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hashdeep
+
+import (
+	"strconv"
+	"strings"
+)
+
+// First line of hashdeep files.
+const Magic = "%%%% HASHDEEP-1.0"
+
+// Algorithms supported by hashdeep, which are the only ones allowed as columns.
+// Whirlpool is omitted since it cannot be computed by hasher.
+var Algorithms = []string{"md5", "sha1", "sha256", "tiger"}
+
+// Struct Item is a file listed in hashdeep file, Hashes are ordered as algorithm columns.
+type Item struct {
+	Size   int64
+	Hashes []string
+	Path   string
+}
+
+// Check whether an algorithm can be a column of hashdeep file.
+func IsSupported(algorithm string) bool {
+	for _, a := range Algorithms {
+		if a == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+// Return header lines of hashdeep file having columns of algorithms.
+func FormatHeader(algorithms []string) []string {
+	columns := append([]string{"size"}, algorithms...)
+	columns = append(columns, "filename")
+	return []string{Magic, "%%%% " + strings.Join(columns, ",")}
+}
+
+// Return a hashdeep comment line.
+func FormatComment(comment string) string {
+	return "## " + comment
+}
+
+// Return a hashdeep line: size,hash...,filename.
+func Format(item *Item) string {
+	fields := append([]string{strconv.FormatInt(item.Size, 10)}, item.Hashes...)
+	fields = append(fields, item.Path)
+	return strings.Join(fields, ",")
+}
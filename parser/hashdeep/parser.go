@@ -0,0 +1,103 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hashdeep
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hashRegex = regexp.MustCompile("^[0-9A-Fa-f]+$")
+
+type Parser struct {
+	r *bufio.Reader
+}
+
+func NewParser(r io.Reader) *Parser {
+	return &Parser{r: bufio.NewReader(r)}
+}
+
+// Parse hashdeep content, then return algorithms of hash columns and all items.
+// Comment lines starting with ## and empty lines are ignored. Filename is the last column
+// so it may contain commas.
+func (p *Parser) Parse() ([]string, []*Item, error) {
+	algorithms := []string{}
+	items := []*Item{}
+
+	for lineNo := 1; ; lineNo++ {
+		line, err := p.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return []string{}, []*Item{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case lineNo == 1:
+			if line != Magic {
+				return []string{}, []*Item{}, fmt.Errorf("line %d: invalid token. expected %s actual '%s'", lineNo, "hashdeep header", line)
+			}
+		case lineNo == 2:
+			columns := strings.Split(strings.TrimPrefix(line, "%%%% "), ",")
+			if !strings.HasPrefix(line, "%%%% ") || len(columns) < 3 || columns[0] != "size" || columns[len(columns)-1] != "filename" {
+				return []string{}, []*Item{}, fmt.Errorf("line %d: invalid token. expected %s actual '%s'", lineNo, "columns", line)
+			}
+			algorithms = columns[1 : len(columns)-1]
+		case line != "" && !strings.HasPrefix(line, "#"):
+			item, perr := parseLine(line, len(algorithms))
+			if perr != nil {
+				return []string{}, []*Item{}, fmt.Errorf("line %d: %w", lineNo, perr)
+			}
+			items = append(items, item)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if len(algorithms) == 0 {
+		return []string{}, []*Item{}, errors.New("hashdeep header not found")
+	}
+
+	return algorithms, items, nil
+}
+
+func parseLine(line string, hashCount int) (*Item, error) {
+	fields := strings.SplitN(line, ",", hashCount+2)
+	if len(fields) != hashCount+2 {
+		return nil, fmt.Errorf("invalid token. expected %d %s actual '%s'", hashCount+2, "columns", line)
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("invalid token. expected %s actual '%s'", "size", fields[0])
+	}
+	for _, h := range fields[1 : hashCount+1] {
+		if !hashRegex.MatchString(h) {
+			return nil, fmt.Errorf("invalid token. expected %s actual '%s'", "hash", h)
+		}
+	}
+	if fields[hashCount+1] == "" {
+		return nil, fmt.Errorf("invalid token. expected %s actual '%s'", "filename", "")
+	}
+	return &Item{
+		Size:   size,
+		Hashes: fields[1 : hashCount+1],
+		Path:   fields[hashCount+1],
+	}, nil
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package hashdeep
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tforceaio/tf-unifiler-go/extension"
+)
+
+func TestParser(t *testing.T) {
+	var tests = []struct {
+		name       string
+		content    string
+		algorithms []string
+		items      []*Item
+	}{
+		{"header only", "%%%% HASHDEEP-1.0\n%%%% size,md5,filename\n", []string{"md5"}, []*Item{}},
+		{
+			"comments",
+			"%%%% HASHDEEP-1.0\r\n%%%% size,md5,sha256,filename\r\n## Invoked from: /home/user\r\n## $ hashdeep -c md5,sha256 -r d\r\n##\r\n6,b1946ac92492d2347c6235b4d2611184,5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03,/home/user/d/a.txt\r\n",
+			[]string{"md5", "sha256"},
+			[]*Item{{6, []string{"b1946ac92492d2347c6235b4d2611184", "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"}, "/home/user/d/a.txt"}},
+		},
+		{
+			"filename with comma",
+			"%%%% HASHDEEP-1.0\n%%%% size,sha1,filename\n0,da39a3ee5e6b4b0d3255bfef95601890afd80709,d/b, c.txt",
+			[]string{"sha1"},
+			[]*Item{{0, []string{"da39a3ee5e6b4b0d3255bfef95601890afd80709"}, "d/b, c.txt"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithms, items, err := NewParser(strings.NewReader(tt.content)).Parse()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(algorithms, tt.algorithms) {
+				t.Errorf("Wrong algorithms. Expected '%v'. Actual '%v'.", tt.algorithms, algorithms)
+			}
+			if !reflect.DeepEqual(items, tt.items) {
+				t.Errorf("Wrong items. Expected '%v'. Actual '%v'.", tt.items, items)
+			}
+		})
+	}
+}
+
+func TestParserError(t *testing.T) {
+	var tests = []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"empty", "", "line 1: invalid token. expected hashdeep header actual ''"},
+		{"missing columns", "%%%% HASHDEEP-1.0", "hashdeep header not found"},
+		{"invalid columns", "%%%% HASHDEEP-1.0\n%%%% md5,filename\n", "line 2: invalid token. expected columns actual '%%%% md5,filename'"},
+		{"missing hash", "%%%% HASHDEEP-1.0\n%%%% size,md5,sha1,filename\n6,b1946ac92492d2347c6235b4d2611184,a.txt", "line 3: invalid token. expected 4 columns actual '6,b1946ac92492d2347c6235b4d2611184,a.txt'"},
+		{"invalid hash", "%%%% HASHDEEP-1.0\n%%%% size,md5,filename\n6,b1946ac9249?d2347c6235b4d2611184,a.txt", "line 3: invalid token. expected hash actual 'b1946ac9249?d2347c6235b4d2611184'"},
+		{"invalid size", "%%%% HASHDEEP-1.0\n%%%% size,md5,filename\n-1,b1946ac92492d2347c6235b4d2611184,a.txt", "line 3: invalid token. expected size actual '-1'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := NewParser(strings.NewReader(tt.content)).Parse()
+			errs := extension.ErrString(err)
+			if errs != tt.err {
+				t.Errorf("wrong error. Expected %q. Actual %q.", tt.err, errs)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	header := FormatHeader([]string{"md5", "sha256"})
+	if !reflect.DeepEqual(header, []string{"%%%% HASHDEEP-1.0", "%%%% size,md5,sha256,filename"}) {
+		t.Errorf("Wrong header '%v'.", header)
+	}
+	line := Format(&Item{6, []string{"b1946ac92492d2347c6235b4d2611184"}, "d/b, c.txt"})
+	if line != "6,b1946ac92492d2347c6235b4d2611184,d/b, c.txt" {
+		t.Errorf("Wrong line '%s'.", line)
+	}
+}
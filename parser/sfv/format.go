@@ -0,0 +1,40 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package sfv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tforceaio/tf-unifiler-go/parser/checksum"
+)
+
+// Check whether a path can be written to SFV files. SFV has no escaping, so paths containing
+// line breaks or starting with the comment marker cannot be read back.
+func IsSupportedPath(path string) bool {
+	return path != "" && !strings.ContainsAny(path, "\n\r") && !strings.HasPrefix(path, ";")
+}
+
+// Return a SFV line: path CRC32. Path must be checked using IsSupportedPath.
+func Format(item *checksum.ChecksumItem) string {
+	return fmt.Sprintf("%s %s", item.Path, strings.ToUpper(item.Hash))
+}
+
+// Return a SFV comment line.
+func FormatComment(comment string) string {
+	return "; " + comment
+}
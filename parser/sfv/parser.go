@@ -0,0 +1,84 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package sfv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/tforceaio/tf-unifiler-go/parser/checksum"
+)
+
+var crc32Regex = regexp.MustCompile("^[0-9A-Fa-f]{8}$")
+
+type Parser struct {
+	r *bufio.Reader
+}
+
+func NewParser(r io.Reader) *Parser {
+	return &Parser{r: bufio.NewReader(r)}
+}
+
+// Parse SFV content. Each line contains a path followed by its CRC32 separated by whitespaces.
+// Empty lines and comment lines starting with semicolon are ignored.
+func (p *Parser) Parse() ([]*checksum.ChecksumItem, error) {
+	items := []*checksum.ChecksumItem{}
+
+	for lineNo := 1; ; lineNo++ {
+		line, err := p.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return []*checksum.ChecksumItem{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" && !strings.HasPrefix(line, ";") {
+			item, perr := parseLine(line)
+			if perr != nil {
+				return []*checksum.ChecksumItem{}, fmt.Errorf("line %d: %w", lineNo, perr)
+			}
+			items = append(items, item)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return items, nil
+}
+
+func parseLine(line string) (*checksum.ChecksumItem, error) {
+	sepIndex := strings.LastIndexAny(line, " \t")
+	if sepIndex < 0 {
+		return nil, fmt.Errorf("invalid token. expected %s actual '%s'", "whitespace", line)
+	}
+	hash := line[sepIndex+1:]
+	if !crc32Regex.MatchString(hash) {
+		return nil, fmt.Errorf("invalid token. expected %s actual '%s'", "CRC32", hash)
+	}
+	path := strings.TrimRight(line[:sepIndex], " \t")
+	if path == "" {
+		return nil, fmt.Errorf("invalid token. expected %s actual '%s'", "path", line[:sepIndex])
+	}
+	return &checksum.ChecksumItem{
+		Algorithm:  "crc32",
+		Hash:       hash,
+		BinaryMode: true,
+		Path:       path,
+	}, nil
+}
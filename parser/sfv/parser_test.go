@@ -0,0 +1,127 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package sfv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tforceaio/tf-unifiler-go/extension"
+)
+
+func TestParserItemCount(t *testing.T) {
+	var tests = []struct {
+		name    string
+		content string
+		count   int
+	}{
+		{"0 item", "", 0},
+		{"comment only", "; Generated by TF Unifiler\n;\n", 0},
+		{"1 item", "go.mod 1A2B3C4D", 1},
+		{"2 items", "; comment\ngo.mod 1A2B3C4D\ngo.sum 5e6f7a8b\n", 2},
+		{"CRLF", "go.mod 1A2B3C4D\r\n\r\ngo.sum 5E6F7A8B\r\n", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(strings.NewReader(tt.content))
+			items, err := p.Parse()
+			if err != nil {
+				t.Error(err)
+			}
+			if len(items) != tt.count {
+				t.Errorf("Invalid number of items. Expected %d. Actual %d.", tt.count, len(items))
+			}
+		})
+	}
+}
+
+func TestParserSyntax(t *testing.T) {
+	var tests = []struct {
+		name    string
+		content string
+		path    string
+		hash    string
+	}{
+		{"Simple", "go.mod 1A2B3C4D", "go.mod", "1A2B3C4D"},
+		{"Path with space", "file system/file.go 1A2B3C4D", "file system/file.go", "1A2B3C4D"},
+		{"Multiple whitespaces", "go.mod \t 1a2b3c4d\n", "go.mod", "1a2b3c4d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(strings.NewReader(tt.content))
+			items, _ := p.Parse()
+			item := items[0]
+			if item.Path != tt.path {
+				t.Errorf("Wrong path. Expected '%s'. Actual '%s'.", tt.path, item.Path)
+			}
+			if item.Hash != tt.hash {
+				t.Errorf("Wrong hash. Expected '%s'. Actual '%s'.", tt.hash, item.Hash)
+			}
+			if item.Algorithm != "crc32" {
+				t.Errorf("Wrong algorithm. Expected '%s'. Actual '%s'.", "crc32", item.Algorithm)
+			}
+		})
+	}
+}
+
+func TestParserError(t *testing.T) {
+	var tests = []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"hash only", "1A2B3C4D", "line 1: invalid token. expected whitespace actual '1A2B3C4D'"},
+		{"invalid hash", "go.mod 1A2B3C4", "line 1: invalid token. expected CRC32 actual '1A2B3C4'"},
+		{"missing path", "; comment\n 1A2B3C4D", "line 2: invalid token. expected path actual ''"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(strings.NewReader(tt.content))
+			_, err := p.Parse()
+			errs := extension.ErrString(err)
+			if errs != tt.err {
+				t.Errorf("wrong error. Expected %q. Actual %q.", tt.err, errs)
+			}
+		})
+	}
+}
+
+func TestIsSupportedPath(t *testing.T) {
+	var tests = []struct {
+		name   string
+		path   string
+		result bool
+	}{
+		{"plain", "dir/file name.txt", true},
+		{"line feed", "a\nb.txt", false},
+		{"carriage return", "a\rb.txt", false},
+		{"comment marker", ";file.txt", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := IsSupportedPath(tt.path)
+			if result != tt.result {
+				t.Errorf("wrong result for %q. Expected %t. Actual %t.", tt.path, tt.result, result)
+			}
+		})
+	}
+}
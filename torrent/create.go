@@ -0,0 +1,291 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package torrent
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tforceaio/tf-unifiler-go/filesystem"
+)
+
+const (
+	// Largest piece length chosen automatically, 16 MiB.
+	MaxPieceLength = 16 * 1024 * 1024
+	// Number of pieces automatic piece length aims for.
+	targetPieceCount = 1500
+	// Size of buffer used for reading files.
+	readBufferSize = 1024 * 1024
+)
+
+// Struct CreateOptions contains settings for creating metainfo.
+type CreateOptions struct {
+	Comment     string
+	CreatedBy   string
+	PieceLength int64 // must be a power of two and at least 16 KiB, 0 chooses automatically
+	Private     bool
+	Trackers    []string
+	Version     Version
+}
+
+// Create metainfo for a file or directory. Files are ordered by their paths, and
+// aligned to pieces using padding files for hybrid torrents.
+// fn is called after each file is hashed if it is not nil.
+func Create(root string, opts *CreateOptions, fn func(localPath string, f *File)) (*MetaInfo, error) {
+	if opts == nil {
+		opts = &CreateOptions{}
+	}
+	version := opts.Version
+	if version == "" {
+		version = V1
+	}
+	if !version.HasV1() && !version.HasV2() {
+		return nil, fmt.Errorf("unsupported torrent version: '%s'", version)
+	}
+	files, localPaths, err := listFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	total := int64(0)
+	for _, f := range files {
+		total += f.Length
+	}
+	pieceLength := opts.PieceLength
+	if pieceLength == 0 {
+		pieceLength = autoPieceLength(total)
+	}
+	if pieceLength < BlockSize || pieceLength&(pieceLength-1) != 0 {
+		return nil, fmt.Errorf("piece length must be a power of two and at least %d bytes", BlockSize)
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MetaInfo{
+		Announce:     opts.Trackers,
+		Comment:      opts.Comment,
+		CreatedBy:    opts.CreatedBy,
+		CreationDate: time.Now().Unix(),
+		Files:        []*File{},
+		Name:         filepath.Base(absRoot),
+		PieceLayers:  map[string][]byte{},
+		PieceLength:  pieceLength,
+		Private:      opts.Private,
+		Version:      version,
+	}
+	pieces := newPieceHasher(pieceLength)
+	buf := make([]byte, readBufferSize)
+	for i, f := range files {
+		writers := []io.Writer{}
+		if version.HasV1() {
+			writers = append(writers, pieces)
+		}
+		var tree *merkleHasher
+		if version.HasV2() {
+			tree = newMerkleHasher(pieceLength)
+			writers = append(writers, tree)
+		}
+		if err := copyFile(io.MultiWriter(writers...), localPaths[i], f.Length, buf); err != nil {
+			return nil, err
+		}
+		if tree != nil {
+			root, layer := tree.Sum()
+			f.PiecesRoot = root
+			if layer != nil {
+				m.PieceLayers[string(root)] = layer
+			}
+		}
+		m.Files = append(m.Files, f)
+		if fn != nil {
+			fn(localPaths[i], f)
+		}
+
+		// hybrid torrents align every file except the last one to pieces.
+		if version == Hybrid && i < len(files)-1 && f.Length%pieceLength != 0 {
+			padding := pieceLength - f.Length%pieceLength
+			pieces.Write(make([]byte, padding))
+			m.Files = append(m.Files, &File{
+				Path:    []string{".pad", fmt.Sprint(padding)},
+				Length:  padding,
+				Padding: true,
+			})
+		}
+	}
+	if version.HasV1() {
+		m.Pieces = pieces.Sum()
+	}
+	return m, nil
+}
+
+// Return power of two piece length so the number of pieces is close to target.
+func autoPieceLength(total int64) int64 {
+	pieceLength := int64(BlockSize)
+	for pieceLength < MaxPieceLength && total/pieceLength > targetPieceCount {
+		pieceLength *= 2
+	}
+	return pieceLength
+}
+
+// List files of root sorted by their paths, which is the order required by v2 file tree.
+// Return files and their paths on local filesystem.
+func listFiles(root string) ([]*File, []string, error) {
+	fi, err := os.Stat(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !fi.IsDir() {
+		return []*File{{Path: []string{}, Length: fi.Size()}}, []string{root}, nil
+	}
+
+	contents, err := filesystem.List([]string{root}, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := []*File{}
+	localPaths := map[*File]string{}
+	for _, c := range contents {
+		if c.IsDir {
+			continue
+		}
+		fi, err := os.Stat(c.RelativePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		rel, err := filepath.Rel(root, c.RelativePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		f := &File{
+			Path:   strings.Split(filesystem.NormalizePath(rel), "/"),
+			Length: fi.Size(),
+		}
+		files = append(files, f)
+		localPaths[f] = c.RelativePath
+	}
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("directory '%s' does not contain any file", root)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return comparePaths(files[i].Path, files[j].Path) < 0
+	})
+	sortedPaths := make([]string, len(files))
+	for i, f := range files {
+		sortedPaths[i] = localPaths[f]
+	}
+	return files, sortedPaths, nil
+}
+
+// Compare paths component by component, so a/b is placed before a-b/c like nested dictionaries.
+func comparePaths(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := strings.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+// Write content of a file to w, the file must have the expected length.
+func copyFile(w io.Writer, fPath string, length int64, buf []byte) error {
+	fHandle, err := os.Open(fPath)
+	if err != nil {
+		return err
+	}
+	defer fHandle.Close()
+	written, err := io.CopyBuffer(w, io.LimitReader(fHandle, length+1), buf)
+	if err != nil {
+		return err
+	}
+	if written != length {
+		return fmt.Errorf("file '%s' has been changed while hashing", fPath)
+	}
+	return nil
+}
+
+// pieceHasher computes v1 SHA-1 piece hashes of content spanning all files.
+// Skipped regions are not hashed, pieces overlapping them are marked invalid.
+type pieceHasher struct {
+	pieceLength int64
+	h           hash.Hash
+	n           int64
+	pieces      []byte
+	invalid     map[int]bool
+}
+
+func newPieceHasher(pieceLength int64) *pieceHasher {
+	return &pieceHasher{
+		pieceLength: pieceLength,
+		h:           sha1.New(),
+		pieces:      []byte{},
+		invalid:     map[int]bool{},
+	}
+}
+
+func (h *pieceHasher) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		size := h.pieceLength - h.n
+		if size > int64(len(p)) {
+			size = int64(len(p))
+		}
+		h.h.Write(p[:size])
+		h.advance(size)
+		p = p[size:]
+	}
+	return n, nil
+}
+
+// Skip length bytes of content, pieces overlapping them are marked invalid.
+func (h *pieceHasher) Skip(length int64) {
+	for length > 0 {
+		h.invalid[len(h.pieces)/sha1.Size] = true
+		size := h.pieceLength - h.n
+		if size > length {
+			size = length
+		}
+		h.advance(size)
+		length -= size
+	}
+}
+
+func (h *pieceHasher) advance(size int64) {
+	h.n += size
+	if h.n == h.pieceLength {
+		h.pieces = h.h.Sum(h.pieces)
+		h.h.Reset()
+		h.n = 0
+	}
+}
+
+// Return concatenated piece hashes, including the last partial piece.
+func (h *pieceHasher) Sum() []byte {
+	if h.n > 0 {
+		h.pieces = h.h.Sum(h.pieces)
+		h.h.Reset()
+		h.n = 0
+	}
+	return h.pieces
+}
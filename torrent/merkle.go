@@ -0,0 +1,143 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package torrent
+
+import (
+	"crypto/sha256"
+	"hash"
+)
+
+// Size of leaf blocks of BitTorrent v2 merkle trees, 16 KiB.
+const BlockSize = 16 * 1024
+
+// merkleHasher computes the SHA-256 merkle tree of a file as defined by BEP 52.
+// Leaves are hashes of 16 KiB blocks, the last block may be shorter. Leaves beyond the
+// end of file are zero hashes so the number of leaves is a power of two.
+// Only hashes of the piece layer are kept so memory usage does not depend on block count.
+type merkleHasher struct {
+	pieceLength int64
+	block       hash.Hash
+	nblock      int
+	leaves      [][]byte
+	pieces      [][]byte
+	length      int64
+}
+
+func newMerkleHasher(pieceLength int64) *merkleHasher {
+	return &merkleHasher{
+		pieceLength: pieceLength,
+		block:       sha256.New(),
+		leaves:      [][]byte{},
+		pieces:      [][]byte{},
+	}
+}
+
+func (h *merkleHasher) Write(p []byte) (int, error) {
+	n := len(p)
+	h.length += int64(n)
+	for len(p) > 0 {
+		size := BlockSize - h.nblock
+		if size > len(p) {
+			size = len(p)
+		}
+		h.block.Write(p[:size])
+		h.nblock += size
+		p = p[size:]
+		if h.nblock == BlockSize {
+			h.flushBlock()
+		}
+	}
+	return n, nil
+}
+
+// Return the pieces root and the piece layer of the file. Piece layer is only
+// available for files larger than a piece, and both are nil for empty files.
+func (h *merkleHasher) Sum() (root []byte, layer []byte) {
+	if h.nblock > 0 {
+		h.flushBlock()
+	}
+	if h.length == 0 {
+		return nil, nil
+	}
+	if h.length <= h.pieceLength {
+		// file of exactly 1 piece has been flushed to the piece layer.
+		if len(h.pieces) == 1 {
+			return h.pieces[0], nil
+		}
+		return merkleRoot(h.leaves, make([]byte, sha256.Size)), nil
+	}
+	if len(h.leaves) > 0 {
+		h.flushPiece()
+	}
+	blocksPerPiece := int(h.pieceLength / BlockSize)
+	padPiece := merkleRoot(make([][]byte, blocksPerPiece), make([]byte, sha256.Size))
+	layer = make([]byte, 0, len(h.pieces)*sha256.Size)
+	for _, p := range h.pieces {
+		layer = append(layer, p...)
+	}
+	return merkleRoot(h.pieces, padPiece), layer
+}
+
+func (h *merkleHasher) flushBlock() {
+	h.leaves = append(h.leaves, h.block.Sum(nil))
+	h.block.Reset()
+	h.nblock = 0
+	if int64(len(h.leaves))*BlockSize == h.pieceLength {
+		h.flushPiece()
+	}
+}
+
+// Hash leaves of the current piece into a node of the piece layer. The last piece
+// is padded using zero hashes.
+func (h *merkleHasher) flushPiece() {
+	leaves := h.leaves
+	blocksPerPiece := int(h.pieceLength / BlockSize)
+	for len(leaves) < blocksPerPiece {
+		leaves = append(leaves, make([]byte, sha256.Size))
+	}
+	h.pieces = append(h.pieces, merkleRoot(leaves, nil))
+	h.leaves = [][]byte{}
+}
+
+// Return root of a merkle tree, number of nodes is padded to the next power of two using pad.
+// Nil nodes are treated as pad too.
+func merkleRoot(nodes [][]byte, pad []byte) []byte {
+	width := 1
+	for width < len(nodes) {
+		width *= 2
+	}
+	layer := make([][]byte, width)
+	for i := range layer {
+		if i < len(nodes) && nodes[i] != nil {
+			layer[i] = nodes[i]
+		} else {
+			layer[i] = pad
+		}
+	}
+	h := sha256.New()
+	for len(layer) > 1 {
+		next := make([][]byte, len(layer)/2)
+		for i := range next {
+			h.Reset()
+			h.Write(layer[2*i])
+			h.Write(layer[2*i+1])
+			next[i] = h.Sum(nil)
+		}
+		layer = next
+	}
+	return layer[0]
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package torrent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestMerkleHasher(t *testing.T) {
+	tests := []struct {
+		name  string
+		size  int
+		root  string
+		layer string // SHA-256 of piece layer
+	}{
+		{"empty", 0, "", ""},
+		{"partial block", 1, "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d", ""},
+		{"single block", BlockSize, "15345b8bcf83c9acd70121ebacc0aae2b90b2995b90a5143382a4f29fd125083", ""},
+		{"padded leaves", BlockSize + 1, "4f06ab5d81301a6be8740291d8491edb35843daecf491a50c4d22d92eaf9acbc", ""},
+		{"single piece", 2 * BlockSize, "6aeeb2f304b96333c9af0d0a646d227f4413e4dfde8da317eabcb92bf234ba61", ""},
+		{"partial piece", 70000, "355b46d07e22cdf0f5b13f2fe69919347083cdc6bb939c67bcb70f8b91828b3a", "a6a0ce87f6f5c52bce471ca374db22ed4f6bccfa60b09e42c5ea63474b4492af"},
+		{"padded pieces", 9 * BlockSize, "a4fb4b1855d2e76f502121492f930cb993bda6bef1eba4eba679a8140aff8813", "531b5733590f1ab648d8df1d52c27e7c5eeb606489f7dff5a6c9f9aa81272217"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := make([]byte, tt.size)
+			for i := range content {
+				content[i] = byte(i*7 + i/251)
+			}
+			h := newMerkleHasher(2 * BlockSize)
+			// odd write size so blocks span multiple writes.
+			for i := 0; i < len(content); i += 1000 {
+				end := i + 1000
+				if end > len(content) {
+					end = len(content)
+				}
+				h.Write(content[i:end])
+			}
+			root, layer := h.Sum()
+			if actual := hex.EncodeToString(root); actual != tt.root {
+				t.Errorf("wrong pieces root. expected '%s' actual '%s'", tt.root, actual)
+			}
+			actual := ""
+			if layer != nil {
+				sum := sha256.Sum256(layer)
+				actual = hex.EncodeToString(sum[:])
+			}
+			if actual != tt.layer {
+				t.Errorf("wrong piece layer. expected '%s' actual '%s'", tt.layer, actual)
+			}
+		})
+	}
+}
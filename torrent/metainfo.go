@@ -0,0 +1,413 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+// Package torrent creates and verifies BitTorrent metainfo files of version 1 (BEP 3),
+// version 2 (BEP 52) and hybrid torrents containing both.
+package torrent
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/tforceaio/tf-unifiler-go/parser/bencode"
+)
+
+// Version of BitTorrent metainfo.
+type Version string
+
+const (
+	V1     Version = "v1"     // BEP 3, SHA-1 pieces spanning all files
+	V2     Version = "v2"     // BEP 52, per-file SHA-256 merkle trees
+	Hybrid Version = "hybrid" // both v1 and v2 metadata, files are aligned to pieces
+)
+
+// Return true if metainfo of this version contains v1 pieces.
+func (v Version) HasV1() bool {
+	return v == V1 || v == Hybrid
+}
+
+// Return true if metainfo of this version contains v2 file tree.
+func (v Version) HasV2() bool {
+	return v == V2 || v == Hybrid
+}
+
+// Return version from name, or error if it is unsupported.
+func ParseVersion(name string) (Version, error) {
+	switch v := Version(strings.ToLower(name)); v {
+	case V1, V2, Hybrid:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unsupported torrent version: '%s'", name)
+	}
+}
+
+// Struct File is a file described by metainfo.
+type File struct {
+	Path       []string // path components relative to torrent root, empty for single-file torrents
+	Length     int64
+	Padding    bool   // v1 padding file used by hybrid torrents to align files to pieces
+	PiecesRoot []byte // root of v2 merkle tree, nil for empty files and v1 torrents
+}
+
+// Return path of the file relative to torrent root, using slash as separator.
+func (f *File) PathString() string {
+	return path.Join(f.Path...)
+}
+
+// Struct MetaInfo contains content of a .torrent file.
+type MetaInfo struct {
+	Announce     []string // tracker URLs, each of them is a separate tier
+	Comment      string
+	CreatedBy    string
+	CreationDate int64
+	Files        []*File // in v1 order, includes padding files
+	Name         string
+	PieceLayers  map[string][]byte // v2 piece layers, keyed by pieces root
+	PieceLength  int64
+	Pieces       []byte // v1 concatenated SHA-1 hashes
+	Private      bool
+	Version      Version
+}
+
+// Return true if torrent contains a single file without directory.
+func (m *MetaInfo) IsSingleFile() bool {
+	return len(m.Files) == 1 && len(m.Files[0].Path) == 0
+}
+
+// Return total size of files, including padding files.
+func (m *MetaInfo) TotalLength() int64 {
+	total := int64(0)
+	for _, f := range m.Files {
+		total += f.Length
+	}
+	return total
+}
+
+// Return SHA-1 info hash, only available for v1 and hybrid torrents.
+func (m *MetaInfo) InfoHashV1() ([]byte, error) {
+	if !m.Version.HasV1() {
+		return nil, errors.New("v1 info hash is not available")
+	}
+	info, err := bencode.Marshal(m.info())
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum(info)
+	return sum[:], nil
+}
+
+// Return SHA-256 info hash, only available for v2 and hybrid torrents.
+func (m *MetaInfo) InfoHashV2() ([]byte, error) {
+	if !m.Version.HasV2() {
+		return nil, errors.New("v2 info hash is not available")
+	}
+	info, err := bencode.Marshal(m.info())
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(info)
+	return sum[:], nil
+}
+
+// Return bencoded form of metainfo.
+func (m *MetaInfo) Marshal() ([]byte, error) {
+	dict := map[string]interface{}{
+		"info": m.info(),
+	}
+	if len(m.Announce) > 0 {
+		dict["announce"] = m.Announce[0]
+	}
+	if len(m.Announce) > 1 {
+		tiers := []interface{}{}
+		for _, a := range m.Announce {
+			tiers = append(tiers, []string{a})
+		}
+		dict["announce-list"] = tiers
+	}
+	if m.Comment != "" {
+		dict["comment"] = m.Comment
+	}
+	if m.CreatedBy != "" {
+		dict["created by"] = m.CreatedBy
+	}
+	if m.CreationDate > 0 {
+		dict["creation date"] = m.CreationDate
+	}
+	if m.Version.HasV2() {
+		layers := map[string]interface{}{}
+		for root, layer := range m.PieceLayers {
+			layers[root] = layer
+		}
+		dict["piece layers"] = layers
+	}
+	return bencode.Marshal(dict)
+}
+
+// Return info dictionary of metainfo.
+func (m *MetaInfo) info() map[string]interface{} {
+	info := map[string]interface{}{
+		"name":         m.Name,
+		"piece length": m.PieceLength,
+	}
+	if m.Private {
+		info["private"] = 1
+	}
+	if m.Version.HasV1() {
+		info["pieces"] = m.Pieces
+		if m.IsSingleFile() {
+			info["length"] = m.Files[0].Length
+		} else {
+			files := []interface{}{}
+			for _, f := range m.Files {
+				file := map[string]interface{}{
+					"length": f.Length,
+					"path":   f.Path,
+				}
+				if f.Padding {
+					file["attr"] = "p"
+				}
+				files = append(files, file)
+			}
+			info["files"] = files
+		}
+	}
+	if m.Version.HasV2() {
+		info["meta version"] = 2
+		tree := map[string]interface{}{}
+		for _, f := range m.Files {
+			if f.Padding {
+				continue
+			}
+			entry := map[string]interface{}{"length": f.Length}
+			if f.PiecesRoot != nil {
+				entry["pieces root"] = f.PiecesRoot
+			}
+			components := f.Path
+			if m.IsSingleFile() {
+				components = []string{m.Name}
+			}
+			node := tree
+			for _, c := range components {
+				child, ok := node[c].(map[string]interface{})
+				if !ok {
+					child = map[string]interface{}{}
+					node[c] = child
+				}
+				node = child
+			}
+			node[""] = entry
+		}
+		info["file tree"] = tree
+	}
+	return info
+}
+
+// Parse content of a .torrent file.
+func Parse(data []byte) (*MetaInfo, error) {
+	v, err := bencode.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("metainfo is not a dictionary")
+	}
+	info, ok := dict["info"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("metainfo does not contain info dictionary")
+	}
+
+	m := &MetaInfo{PieceLayers: map[string][]byte{}}
+	m.Comment, _ = dict["comment"].(string)
+	m.CreatedBy, _ = dict["created by"].(string)
+	m.CreationDate, _ = dict["creation date"].(int64)
+	if tiers, ok := dict["announce-list"].([]interface{}); ok {
+		for _, tier := range tiers {
+			urls, _ := tier.([]interface{})
+			for _, u := range urls {
+				if s, ok := u.(string); ok {
+					m.Announce = append(m.Announce, s)
+				}
+			}
+		}
+	} else if announce, ok := dict["announce"].(string); ok {
+		m.Announce = []string{announce}
+	}
+	if layers, ok := dict["piece layers"].(map[string]interface{}); ok {
+		for root, layer := range layers {
+			if s, ok := layer.(string); ok {
+				m.PieceLayers[root] = []byte(s)
+			}
+		}
+	}
+
+	if m.Name, ok = info["name"].(string); !ok || !isValidComponent(m.Name) {
+		return nil, errors.New("info dictionary does not contain valid name")
+	}
+	if m.PieceLength, ok = info["piece length"].(int64); !ok || m.PieceLength <= 0 {
+		return nil, errors.New("info dictionary does not contain valid piece length")
+	}
+	private, _ := info["private"].(int64)
+	m.Private = private == 1
+
+	pieces, hasV1 := info["pieces"].(string)
+	metaVersion, _ := info["meta version"].(int64)
+	hasV2 := metaVersion == 2
+	switch {
+	case hasV1 && hasV2:
+		m.Version = Hybrid
+	case hasV2:
+		m.Version = V2
+	case hasV1:
+		m.Version = V1
+	default:
+		return nil, errors.New("info dictionary contains neither pieces nor meta version 2")
+	}
+
+	if hasV1 {
+		if len(pieces)%sha1.Size != 0 {
+			return nil, errors.New("invalid length of pieces")
+		}
+		m.Pieces = []byte(pieces)
+		m.Files, err = parseFilesV1(info)
+	} else {
+		m.Files, err = parseFileTree(info, m.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hasV1 && hasV2 {
+		// piece roots are only available in file tree.
+		v2Files, err := parseFileTree(info, m.Name)
+		if err != nil {
+			return nil, err
+		}
+		roots := map[string][]byte{}
+		for _, f := range v2Files {
+			roots[f.PathString()] = f.PiecesRoot
+		}
+		for _, f := range m.Files {
+			if !f.Padding {
+				f.PiecesRoot = roots[f.PathString()]
+			}
+		}
+	}
+	return m, nil
+}
+
+func parseFilesV1(info map[string]interface{}) ([]*File, error) {
+	if length, ok := info["length"].(int64); ok {
+		return []*File{{Path: []string{}, Length: length}}, nil
+	}
+	list, ok := info["files"].([]interface{})
+	if !ok {
+		return nil, errors.New("info dictionary contains neither length nor files")
+	}
+	files := []*File{}
+	for _, item := range list {
+		dict, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("invalid file entry")
+		}
+		f := &File{}
+		if f.Length, ok = dict["length"].(int64); !ok || f.Length < 0 {
+			return nil, errors.New("invalid file length")
+		}
+		components, _ := dict["path"].([]interface{})
+		for _, c := range components {
+			s, ok := c.(string)
+			if !ok || !isValidComponent(s) {
+				return nil, fmt.Errorf("invalid file path component '%v'", c)
+			}
+			f.Path = append(f.Path, s)
+		}
+		if len(f.Path) == 0 {
+			return nil, errors.New("file path is empty")
+		}
+		attr, _ := dict["attr"].(string)
+		f.Padding = strings.Contains(attr, "p")
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// Parse v2 file tree. Files are returned in order of their paths, which is also
+// the order of files in hybrid torrents.
+func parseFileTree(info map[string]interface{}, name string) ([]*File, error) {
+	tree, ok := info["file tree"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("info dictionary does not contain file tree")
+	}
+	files := []*File{}
+	if err := walkFileTree(tree, []string{}, &files); err != nil {
+		return nil, err
+	}
+	// single file torrent is a file tree having only the torrent name.
+	if len(files) == 1 && len(files[0].Path) == 1 && files[0].Path[0] == name {
+		files[0].Path = []string{}
+	}
+	return files, nil
+}
+
+func walkFileTree(node map[string]interface{}, parent []string, files *[]*File) error {
+	for _, key := range sortedKeys(node) {
+		child, ok := node[key].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid file tree node '%s'", key)
+		}
+		if key == "" {
+			f := &File{Path: append([]string{}, parent...)}
+			if f.Length, ok = child["length"].(int64); !ok || f.Length < 0 {
+				return errors.New("invalid file length")
+			}
+			if root, ok := child["pieces root"].(string); ok {
+				if len(root) != sha256.Size {
+					return errors.New("invalid length of pieces root")
+				}
+				f.PiecesRoot = []byte(root)
+			}
+			*files = append(*files, f)
+			continue
+		}
+		if !isValidComponent(key) {
+			return fmt.Errorf("invalid file path component '%s'", key)
+		}
+		components := append(append([]string{}, parent...), key)
+		if err := walkFileTree(child, components, files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys(dict map[string]interface{}) []string {
+	keys := make([]string, 0, len(dict))
+	for k := range dict {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Path components must not escape the download directory.
+func isValidComponent(c string) bool {
+	return c != "" && c != "." && c != ".." && !strings.ContainsAny(c, "/\\")
+}
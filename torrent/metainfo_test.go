@@ -0,0 +1,152 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package torrent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Create a directory with files of various sizes for testing, then return its path.
+func createTestContent(t *testing.T) string {
+	root := filepath.Join(t.TempDir(), "content")
+	files := map[string]int{
+		"a-b/x": 2 * BlockSize,
+		"b":     100,
+		"empty": 0,
+		"sub/c": 70000,
+		"sub/d": 5*BlockSize + 7,
+		"z":     200000,
+	}
+	for name, size := range files {
+		fPath := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(fPath), 0775)
+		content := make([]byte, size)
+		for i := range content {
+			content[i] = byte(i*7 + i/251 + len(name))
+		}
+		os.WriteFile(fPath, content, 0664)
+	}
+	return root
+}
+
+func TestCreateAndParse(t *testing.T) {
+	root := createTestContent(t)
+	for _, version := range []Version{V1, V2, Hybrid} {
+		t.Run(string(version), func(t *testing.T) {
+			opts := &CreateOptions{
+				Comment:     "test",
+				PieceLength: 2 * BlockSize,
+				Private:     true,
+				Trackers:    []string{"http://a/announce", "udp://b:1/announce"},
+				Version:     version,
+			}
+			m, err := Create(root, opts, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := m.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := Parse(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Version != version {
+				t.Errorf("wrong version. expected '%s' actual '%s'", version, parsed.Version)
+			}
+			if parsed.Name != "content" || parsed.Comment != "test" || !parsed.Private {
+				t.Errorf("wrong metadata. actual name '%s' comment '%s' private %t", parsed.Name, parsed.Comment, parsed.Private)
+			}
+			if !reflect.DeepEqual(parsed.Announce, opts.Trackers) {
+				t.Errorf("wrong trackers. expected %v actual %v", opts.Trackers, parsed.Announce)
+			}
+			paths := []string{}
+			for _, f := range parsed.Files {
+				if !f.Padding {
+					paths = append(paths, f.PathString())
+				}
+			}
+			expected := []string{"a-b/x", "b", "empty", "sub/c", "sub/d", "z"}
+			if !reflect.DeepEqual(paths, expected) {
+				t.Errorf("wrong files. expected %v actual %v", expected, paths)
+			}
+			if version == Hybrid {
+				offset := int64(0)
+				for _, f := range parsed.Files {
+					if !f.Padding && offset%m.PieceLength != 0 {
+						t.Errorf("file '%s' is not aligned to piece", f.PathString())
+					}
+					offset += f.Length
+				}
+			}
+			for i, f := range parsed.Files {
+				if !bytes.Equal(f.PiecesRoot, m.Files[i].PiecesRoot) {
+					t.Errorf("wrong pieces root of '%s'", f.PathString())
+				}
+			}
+			hash1, _ := m.InfoHashV1()
+			hash2, _ := parsed.InfoHashV1()
+			if !bytes.Equal(hash1, hash2) {
+				t.Errorf("info hash changed after parsing")
+			}
+		})
+	}
+}
+
+func TestCreateSingleFile(t *testing.T) {
+	fPath := filepath.Join(createTestContent(t), "z")
+	m, err := Create(fPath, &CreateOptions{Version: Hybrid}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := m.Marshal()
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.IsSingleFile() || parsed.Name != "z" || parsed.TotalLength() != 200000 {
+		t.Errorf("wrong single file torrent. actual name '%s' size %d", parsed.Name, parsed.TotalLength())
+	}
+	if parsed.PieceLength != BlockSize {
+		t.Errorf("wrong automatic piece length. expected %d actual %d", BlockSize, parsed.PieceLength)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not a dictionary", "le"},
+		{"no info", "d8:announce1:ae"},
+		{"no pieces", "d4:infod6:lengthi1e4:name1:a12:piece lengthi16384eee"},
+		{"unsafe name", "d4:infod6:lengthi1e4:name2:..12:piece lengthi16384e6:pieces0:ee"},
+		{"unsafe path", "d4:infod5:filesld6:lengthi1e4:pathl2:..1:aeee4:name1:a12:piece lengthi16384e6:pieces0:ee"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data)); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
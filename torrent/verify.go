@@ -0,0 +1,173 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package torrent
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Statuses of files after verification.
+const (
+	StatusOK      = "OK"
+	StatusFailed  = "FAILED"
+	StatusMissing = "MISSING"
+)
+
+// Struct VerifyResult is the verification result of a single file.
+type VerifyResult struct {
+	File      *File
+	LocalPath string
+	Status    string
+	BadPieces int // number of pieces of the file which do not match metainfo
+}
+
+// Verify files of a torrent downloaded to dir, the content is expected at dir/name.
+// v2 merkle trees are preferred for hybrid torrents since they are verified per file.
+// fn is called for every file except padding files, in the order of metainfo.
+func Verify(m *MetaInfo, dir string, fn func(r *VerifyResult) error) error {
+	if m.Version.HasV2() {
+		return verifyV2(m, dir, fn)
+	}
+	return verifyV1(m, dir, fn)
+}
+
+// Return path of a file on local filesystem.
+func localPath(m *MetaInfo, dir string, f *File) string {
+	if m.IsSingleFile() {
+		return filepath.Join(dir, m.Name)
+	}
+	return filepath.Join(append([]string{dir, m.Name}, f.Path...)...)
+}
+
+// Return status of a local file without hashing, or empty string if it needs to be hashed.
+func statFile(fPath string, length int64) string {
+	fi, err := os.Stat(fPath)
+	if err != nil || fi.IsDir() {
+		return StatusMissing
+	}
+	if fi.Size() != length {
+		return StatusFailed
+	}
+	return ""
+}
+
+// Return number of pieces of content having specified length.
+func pieceCount(length, pieceLength int64) int {
+	return int((length + pieceLength - 1) / pieceLength)
+}
+
+func verifyV1(m *MetaInfo, dir string, fn func(r *VerifyResult) error) error {
+	if len(m.Pieces) != pieceCount(m.TotalLength(), m.PieceLength)*sha1.Size {
+		return errors.New("number of pieces does not match total length of files")
+	}
+	pieces := newPieceHasher(m.PieceLength)
+	buf := make([]byte, readBufferSize)
+	results := []*VerifyResult{}
+	for _, f := range m.Files {
+		if f.Padding {
+			pieces.Write(make([]byte, f.Length))
+			continue
+		}
+		r := &VerifyResult{File: f, LocalPath: localPath(m, dir, f)}
+		r.Status = statFile(r.LocalPath, f.Length)
+		if r.Status == "" {
+			if err := copyFile(pieces, r.LocalPath, f.Length, buf); err != nil {
+				return err
+			}
+		} else {
+			pieces.Skip(f.Length)
+		}
+		results = append(results, r)
+	}
+
+	actual := pieces.Sum()
+	offset := int64(0)
+	i := 0
+	for _, f := range m.Files {
+		if f.Padding {
+			offset += f.Length
+			continue
+		}
+		r := results[i]
+		i++
+		if r.Status == "" {
+			r.Status = StatusOK
+			for p := offset / m.PieceLength; f.Length > 0 && p*m.PieceLength < offset+f.Length; p++ {
+				start := p * sha1.Size
+				if pieces.invalid[int(p)] || !bytes.Equal(actual[start:start+sha1.Size], m.Pieces[start:start+sha1.Size]) {
+					r.Status = StatusFailed
+					r.BadPieces++
+				}
+			}
+		}
+		offset += f.Length
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verifyV2(m *MetaInfo, dir string, fn func(r *VerifyResult) error) error {
+	buf := make([]byte, readBufferSize)
+	for _, f := range m.Files {
+		if f.Padding {
+			continue
+		}
+		r := &VerifyResult{File: f, LocalPath: localPath(m, dir, f)}
+		r.Status = statFile(r.LocalPath, f.Length)
+		if r.Status == "" {
+			tree := newMerkleHasher(m.PieceLength)
+			if err := copyFile(tree, r.LocalPath, f.Length, buf); err != nil {
+				return err
+			}
+			root, layer := tree.Sum()
+			r.Status = StatusOK
+			if !bytes.Equal(root, f.PiecesRoot) {
+				r.Status = StatusFailed
+				r.BadPieces = countBadPieces(layer, m.PieceLayers[string(f.PiecesRoot)])
+			}
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compare piece layers, every piece is bad if expected layer is not available.
+func countBadPieces(actual, expected []byte) int {
+	count := len(actual) / sha256.Size
+	if count == 0 {
+		return 1
+	}
+	if len(expected) != len(actual) {
+		return count
+	}
+	bad := 0
+	for i := 0; i < len(actual); i += sha256.Size {
+		if !bytes.Equal(actual[i:i+sha256.Size], expected[i:i+sha256.Size]) {
+			bad++
+		}
+	}
+	return bad
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2025 T-Force I/O
+// This file is part of TF Unifiler
+//
+// TF Unifiler is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// TF Unifiler is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with TF Unifiler. If not, see <https://www.gnu.org/licenses/>.
+
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		version  Version
+		expected map[string]string
+	}{
+		// in v1, pieces are shared between files so neighbours of damaged file also fail.
+		{V1, map[string]string{"b": StatusMissing, "sub/c": StatusFailed, "sub/d": StatusFailed}},
+		{V2, map[string]string{"b": StatusMissing, "sub/d": StatusFailed}},
+		{Hybrid, map[string]string{"b": StatusMissing, "sub/d": StatusFailed}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.version), func(t *testing.T) {
+			root := createTestContent(t)
+			m, err := Create(root, &CreateOptions{PieceLength: 2 * BlockSize, Version: tt.version}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := filepath.Dir(root)
+			os.Remove(filepath.Join(root, "b"))
+			fHandle, _ := os.OpenFile(filepath.Join(root, "sub", "d"), os.O_WRONLY, 0664)
+			fHandle.WriteAt([]byte("X"), 3*BlockSize)
+			fHandle.Close()
+
+			err = Verify(m, dir, func(r *VerifyResult) error {
+				expected, ok := tt.expected[r.File.PathString()]
+				if !ok {
+					expected = StatusOK
+				}
+				if r.Status != expected {
+					t.Errorf("wrong status of '%s'. expected '%s' actual '%s'", r.File.PathString(), expected, r.Status)
+				}
+				if r.File.PathString() == "sub/d" && r.BadPieces != 1 {
+					t.Errorf("wrong number of bad pieces. expected %d actual %d", 1, r.BadPieces)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}